@@ -0,0 +1,345 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/openconfig/catalog-server/pkg/client"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+)
+
+// listEntries lists entries of *kind* in organization *orgName*, or in all organizations if *orgName* is "".
+func listEntries(ctx context.Context, catalog client.Catalog, kind string, orgName string) ([]client.Entry, error) {
+	if kind == kindModule {
+		return catalog.ListModuleEntries(ctx, orgName)
+	}
+	return catalog.ListFeatureBundleEntries(ctx, orgName)
+}
+
+// searchEntries searches entries of *kind* of *name* and *version* in all organizations, empty *name* or *version* matches any.
+func searchEntries(ctx context.Context, catalog client.Catalog, kind string, name string, version string) ([]client.Entry, error) {
+	if kind == kindModule {
+		return catalog.SearchModuleEntries(ctx, name, version)
+	}
+	return catalog.SearchFeatureBundleEntries(ctx, name, version)
+}
+
+// keyOf returns name and version of module or feature bundle of *kind* in JSON *data*.
+func keyOf(kind string, data string) (string, string, error) {
+	if kind == kindModule {
+		module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+		if err := oc.Unmarshal([]byte(data), module); err != nil {
+			return "", "", fmt.Errorf("invalid module: %v", err)
+		}
+		return module.GetName(), module.GetVersion(), nil
+	}
+	featureBundle := &oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle{}
+	if err := oc.Unmarshal([]byte(data), featureBundle); err != nil {
+		return "", "", fmt.Errorf("invalid feature bundle: %v", err)
+	}
+	return featureBundle.GetName(), featureBundle.GetVersion(), nil
+}
+
+// toItems converts *entries* of *kind* into printed items, matching *name* and *version* if they are not "".
+// Data of entries is included if *withData* is true.
+func toItems(kind string, entries []client.Entry, name string, version string, withData bool) ([]item, error) {
+	items := []item{}
+	for _, entry := range entries {
+		n, v, err := keyOf(kind, entry.Data)
+		if err != nil {
+			return nil, err
+		}
+		if (name != "" && n != name) || (version != "" && v != version) {
+			continue
+		}
+		it := item{OrgName: entry.OrgName, Name: n, Version: v}
+		if withData {
+			if err := json.Unmarshal([]byte(entry.Data), &it.Data); err != nil {
+				return nil, fmt.Errorf("invalid data of %s@%s: %v", n, v, err)
+			}
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// publish validates JSON *data* of *kind* and publishes it in organization *orgName*, it returns key of published entry.
+func publish(ctx context.Context, c *client.Client, kind string, orgName string, data []byte) (string, error) {
+	if kind == kindModule {
+		module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+		if err := oc.Unmarshal(data, module); err != nil {
+			return "", fmt.Errorf("invalid module: %v", err)
+		}
+		return module.GetName() + "@" + module.GetVersion(), c.CreateModule(ctx, orgName, module)
+	}
+	featureBundle := &oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle{}
+	if err := oc.Unmarshal(data, featureBundle); err != nil {
+		return "", fmt.Errorf("invalid feature bundle: %v", err)
+	}
+	return featureBundle.GetName() + "@" + featureBundle.GetVersion(), c.CreateFeatureBundle(ctx, orgName, featureBundle)
+}
+
+func runList(ctx context.Context, catalog client.Catalog, out *printer, args []string) error {
+	kind, args, err := parseKind(args)
+	if err != nil {
+		return err
+	}
+	fs, key := newFlagSet("list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	entries, err := listEntries(ctx, catalog, kind, *key.orgName)
+	if err != nil {
+		return err
+	}
+	items, err := toItems(kind, entries, "", "", false)
+	if err != nil {
+		return err
+	}
+	return out.items(items)
+}
+
+func runSearch(ctx context.Context, catalog client.Catalog, out *printer, args []string) error {
+	kind, args, err := parseKind(args)
+	if err != nil {
+		return err
+	}
+	fs, key := newFlagSet("search")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	entries, err := searchEntries(ctx, catalog, kind, *key.name, *key.version)
+	if err != nil {
+		return err
+	}
+	items, err := toItems(kind, entries, "", "", false)
+	if err != nil {
+		return err
+	}
+	return out.items(items)
+}
+
+func runGet(ctx context.Context, catalog client.Catalog, out *printer, args []string) error {
+	kind, args, err := parseKind(args)
+	if err != nil {
+		return err
+	}
+	fs, key := newFlagSet("get")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := key.requireKey(); err != nil {
+		return err
+	}
+	entries, err := searchEntries(ctx, catalog, kind, *key.name, *key.version)
+	if err != nil {
+		return err
+	}
+	var orgEntries []client.Entry
+	for _, entry := range entries {
+		if entry.OrgName == *key.orgName {
+			orgEntries = append(orgEntries, entry)
+		}
+	}
+	items, err := toItems(kind, orgEntries, "", "", true)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("%s %s@%s of %s %v", kind, *key.name, *key.version, *key.orgName, client.ErrNotFound)
+	}
+	return out.item(items[0])
+}
+
+func runPublish(ctx context.Context, c *client.Client, out *printer, args []string) error {
+	kind, args, err := parseKind(args)
+	if err != nil {
+		return err
+	}
+	fs, key := newFlagSet("publish")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *key.orgName == "" || fs.NArg() == 0 {
+		return fmt.Errorf("-org and files are required")
+	}
+	for _, file := range fs.Args() {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read %s failed: %v", file, err)
+		}
+		published, err := publish(ctx, c, kind, *key.orgName, data)
+		if err != nil {
+			return fmt.Errorf("publish %s failed: %v", file, err)
+		}
+		out.message("published %s %s of %s", kind, published, *key.orgName)
+	}
+	return nil
+}
+
+func runDelete(ctx context.Context, c *client.Client, out *printer, args []string) error {
+	kind, args, err := parseKind(args)
+	if err != nil {
+		return err
+	}
+	fs, key := newFlagSet("delete")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := key.requireKey(); err != nil {
+		return err
+	}
+	if kind == kindModule {
+		err = c.DeleteModule(ctx, *key.orgName, *key.name, *key.version)
+	} else {
+		err = c.DeleteFeatureBundle(ctx, *key.orgName, *key.name, *key.version)
+	}
+	if err != nil {
+		return err
+	}
+	out.message("deleted %s %s@%s of %s", kind, *key.name, *key.version, *key.orgName)
+	return nil
+}
+
+func runDeps(ctx context.Context, catalog client.Catalog, out *printer, args []string) error {
+	fs, key := newFlagSet("deps")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := key.requireKey(); err != nil {
+		return err
+	}
+	deps, unresolved, err := client.ResolveDependencies(ctx, catalog, *key.orgName, *key.name, *key.version)
+	if err != nil {
+		return err
+	}
+	items := []item{}
+	for _, dep := range deps {
+		items = append(items, item{OrgName: dep.OrgName, Name: dep.Module.GetName(), Version: dep.Module.GetVersion()})
+	}
+	if err := out.items(items); err != nil {
+		return err
+	}
+	if len(unresolved) > 0 {
+		return fmt.Errorf("unresolved required modules: %s", strings.Join(unresolved, ", "))
+	}
+	return nil
+}
+
+func runExport(ctx context.Context, c *client.Client, out *printer, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("FILE is required")
+	}
+	snapshot, err := c.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	if args[0] == "-" {
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal snapshot failed: %v", err)
+		}
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+	if err := snapshot.Save(args[0]); err != nil {
+		return err
+	}
+	out.message("exported %d modules and %d feature bundles to %s, digest %s", len(snapshot.Modules), len(snapshot.FeatureBundles), args[0], snapshot.Digest)
+	return nil
+}
+
+func runImport(ctx context.Context, c *client.Client, out *printer, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("FILE is required")
+	}
+	snapshot, err := client.LoadSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	// Modules are imported before feature bundles, which refer to modules.
+	for _, kind := range []string{kindModule, kindFeatureBundle} {
+		entries := snapshot.Modules
+		if kind == kindFeatureBundle {
+			entries = snapshot.FeatureBundles
+		}
+		for _, entry := range entries {
+			published, err := publish(ctx, c, kind, entry.OrgName, []byte(entry.Data))
+			if err != nil {
+				return fmt.Errorf("import %s of %s failed: %v", kind, entry.OrgName, err)
+			}
+			out.message("imported %s %s of %s", kind, published, entry.OrgName)
+		}
+	}
+	return nil
+}
+
+// openCatalog opens catalog *target*, which is address of catalog server if it contains "://", or file of snapshot otherwise.
+// Client of server uses token printed by *tokenCommand*, or read from file *tokenFile*, or in $CATALOG_TOKEN.
+func openCatalog(target string, tokenCommand string, tokenFile string) (client.Catalog, error) {
+	if strings.Contains(target, "://") {
+		return newClient(target, tokenCommand, tokenFile)
+	}
+	return client.LoadSnapshot(target)
+}
+
+func runCompare(ctx context.Context, catalog client.Catalog, out *printer, args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	orgsPtr := fs.String("org", "", "comma separated names of compared organizations, all organizations in either catalog if not set")
+	tokenPtr := fs.String("token", os.Getenv("CATALOG_TOKEN_FILE"), "file path of auth token of SECONDARY")
+	tokenCommandPtr := fs.String("token-command", os.Getenv("CATALOG_TOKEN_COMMAND"), "command printing auth token of SECONDARY")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("SECONDARY is required")
+	}
+	secondary, err := openCatalog(fs.Arg(0), *tokenCommandPtr, *tokenPtr)
+	if err != nil {
+		return err
+	}
+	orgNames := strings.FieldsFunc(*orgsPtr, func(r rune) bool { return r == ',' })
+	if len(orgNames) == 0 {
+		if orgNames, err = client.OrgNames(ctx, catalog, secondary); err != nil {
+			return err
+		}
+	}
+	comparisons := []comparison{}
+	var differing []string
+	for _, orgName := range orgNames {
+		diffs, err := client.CompareOrg(ctx, catalog, secondary, orgName)
+		if err != nil {
+			return fmt.Errorf("compare %s failed: %v", orgName, err)
+		}
+		comparisons = append(comparisons, comparison{OrgName: orgName, Differences: diffs})
+		if len(diffs) > 0 {
+			differing = append(differing, orgName)
+		}
+	}
+	if err := out.comparisons(comparisons); err != nil {
+		return err
+	}
+	if len(differing) > 0 {
+		return fmt.Errorf("catalogs differ in %s", strings.Join(differing, ", "))
+	}
+	return nil
+}
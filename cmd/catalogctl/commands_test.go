@@ -0,0 +1,40 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/catalog-server/pkg/client"
+)
+
+func TestToItems(t *testing.T) {
+	entries := []client.Entry{
+		{OrgName: "openconfig", Data: `{"name":"openconfig-interfaces","version":"1.0.0"}`},
+		{OrgName: "openconfig", Data: `{"name":"openconfig-interfaces","version":"2.0.0"}`},
+	}
+	got, err := toItems(kindModule, entries, "", "2.0.0", true)
+	if err != nil {
+		t.Fatalf("toItems failed: %v", err)
+	}
+	want := []item{{OrgName: "openconfig", Name: "openconfig-interfaces", Version: "2.0.0", Data: map[string]interface{}{"name": "openconfig-interfaces", "version": "2.0.0"}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("toItems mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := toItems(kindModule, []client.Entry{{OrgName: "openconfig", Data: `{"unknown":1}`}}, "", "", false); err == nil {
+		t.Errorf("toItems of invalid module should fail")
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command catalogctl queries and updates catalog server with pkg/client.
+//
+// Usage:
+//
+//	catalogctl [-server URL] [-token FILE | -token-command CMD] [-snapshot FILE] [-o table|json|yaml] COMMAND [FLAGS] [ARGS]
+//
+// Commands:
+//
+//	list KIND [-org ORG]                        list modules or feature bundles
+//	search KIND [-name NAME] [-version VERSION] search modules or feature bundles in all organizations
+//	get KIND -org ORG -name NAME -version VER   get a module or feature bundle
+//	deps -org ORG -name NAME -version VER       list a module and modules it transitively requires
+//	publish KIND -org ORG FILE...               publish modules or feature bundles in JSON files
+//	delete KIND -org ORG -name NAME -version VER
+//	export FILE                                 download snapshot of catalog into FILE, "-" for stdout
+//	import FILE                                 publish all modules and feature bundles in snapshot FILE
+//	compare [-org ORG,...] SECONDARY            compare catalog with SECONDARY org by org, e.g., while migrating server
+//
+// KIND is `module` or `featurebundle`. Server is given by -server or $CATALOG_SERVER.
+// Token is printed by command given by -token-command or $CATALOG_TOKEN_COMMAND, e.g., `gcloud auth print-identity-token`,
+// or read from file given by -token or $CATALOG_TOKEN_FILE, or taken from $CATALOG_TOKEN.
+// Token is obtained again when it is about to expire or rejected by server.
+// With -snapshot, list, search, get, deps and compare are answered from snapshot written by export without server.
+// SECONDARY of compare is address of another catalog server, or file of its snapshot.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/openconfig/catalog-server/pkg/client"
+)
+
+// Kinds of catalog entries given as first argument of commands.
+const (
+	kindModule        = `module`
+	kindFeatureBundle = `featurebundle`
+)
+
+// command is a subcommand of catalogctl, which runs with its arguments *args*.
+// Commands only reading catalog run with *read*, with either client or snapshot given by -snapshot,
+// and other commands run with *update*, which always requires client.
+type command struct {
+	usage  string
+	read   func(ctx context.Context, catalog client.Catalog, out *printer, args []string) error
+	update func(ctx context.Context, c *client.Client, out *printer, args []string) error
+}
+
+var commands = map[string]command{
+	"list":    {usage: "list KIND [-org ORG]", read: runList},
+	"search":  {usage: "search KIND [-name NAME] [-version VERSION]", read: runSearch},
+	"get":     {usage: "get KIND -org ORG -name NAME -version VERSION", read: runGet},
+	"deps":    {usage: "deps -org ORG -name NAME -version VERSION", read: runDeps},
+	"publish": {usage: "publish KIND -org ORG FILE...", update: runPublish},
+	"delete":  {usage: "delete KIND -org ORG -name NAME -version VERSION", update: runDelete},
+	"export":  {usage: "export FILE", update: runExport},
+	"import":  {usage: "import FILE", update: runImport},
+	"compare": {usage: "compare [-org ORG,...] [-token FILE | -token-command CMD] SECONDARY", read: runCompare},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: catalogctl [-server URL] [-token FILE | -token-command CMD] [-snapshot FILE] [-o table|json|yaml] COMMAND [FLAGS] [ARGS]\n\nCommands:\n")
+	for _, name := range []string{"list", "search", "get", "deps", "publish", "delete", "export", "import", "compare"} {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nKIND is %s or %s.\n\nFlags:\n", kindModule, kindFeatureBundle)
+	flag.PrintDefaults()
+}
+
+func main() {
+	serverPtr := flag.String("server", os.Getenv("CATALOG_SERVER"), "address of catalog server, e.g., https://catalog.example.com")
+	tokenPtr := flag.String("token", os.Getenv("CATALOG_TOKEN_FILE"), "file path of auth token, $CATALOG_TOKEN is used if not set")
+	tokenCommandPtr := flag.String("token-command", os.Getenv("CATALOG_TOKEN_COMMAND"), "command printing auth token, e.g., \"gcloud auth print-identity-token\", which overrides -token")
+	snapshotPtr := flag.String("snapshot", "", "file of catalog snapshot written by export, which answers list, search, get, deps and compare without server")
+	timeoutPtr := flag.Duration("timeout", client.DefaultTimeout, "timeout of each attempt of requests to server")
+	retriesPtr := flag.Int("retries", client.DefaultRetryPolicy.MaxRetries, "maximum number of retries of requests failed by network errors, 429 or 5xx")
+	outputPtr := flag.String("o", formatTable, "output format, table, json or yaml")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "catalogctl: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+	out, err := newPrinter(os.Stdout, *outputPtr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "catalogctl: %v\n", err)
+		os.Exit(2)
+	}
+	// Interrupt cancels requests in progress and waiting for retries.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if cmd.read != nil && *snapshotPtr != "" {
+		snapshot, err := client.LoadSnapshot(*snapshotPtr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "catalogctl: %v\n", err)
+			os.Exit(1)
+		}
+		exit(cmd.read(ctx, snapshot, out, flag.Args()[1:]))
+	}
+	if *serverPtr == "" {
+		fmt.Fprintf(os.Stderr, "catalogctl: server is not given by -server or $CATALOG_SERVER\n")
+		os.Exit(2)
+	}
+
+	retry := client.DefaultRetryPolicy
+	retry.MaxRetries = *retriesPtr
+	c, err := newClient(*serverPtr, *tokenCommandPtr, *tokenPtr, client.WithTimeout(*timeoutPtr), client.WithRetryPolicy(retry))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "catalogctl: %v\n", err)
+		os.Exit(1)
+	}
+	if cmd.read != nil {
+		exit(cmd.read(ctx, c, out, flag.Args()[1:]))
+	}
+	exit(cmd.update(ctx, c, out, flag.Args()[1:]))
+}
+
+// exit exits with status of *err* returned by command.
+func exit(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "catalogctl %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// newClient returns client of catalog server *server* configured by *opts*, with token printed by *tokenCommand*,
+// or read from file *tokenFile*, or in $CATALOG_TOKEN if neither is given.
+func newClient(server string, tokenCommand string, tokenFile string, opts ...client.Option) (*client.Client, error) {
+	opts = append(opts, client.WithServerURL(server))
+	if args := strings.Fields(tokenCommand); len(args) > 0 {
+		return client.NewClient("", append(opts, client.WithTokenSource(client.CommandTokenSource(args[0], args[1:]...)))...)
+	}
+	if _, ok := os.LookupEnv("CATALOG_TOKEN"); ok && tokenFile == "" {
+		opts = append(opts, client.WithTokenSource(client.EnvTokenSource("CATALOG_TOKEN")))
+	}
+	return client.NewClient(tokenFile, opts...)
+}
+
+// parseKind parses kind of catalog entries from first argument of *args*, and returns remaining arguments.
+func parseKind(args []string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("KIND is not given, it should be %s or %s", kindModule, kindFeatureBundle)
+	}
+	switch kind := strings.ToLower(args[0]); kind {
+	case kindModule, kindModule + "s":
+		return kindModule, args[1:], nil
+	case kindFeatureBundle, kindFeatureBundle + "s":
+		return kindFeatureBundle, args[1:], nil
+	}
+	return "", nil, fmt.Errorf("unknown KIND %q, it should be %s or %s", args[0], kindModule, kindFeatureBundle)
+}
+
+// keyFlags are flags identifying a module or feature bundle.
+type keyFlags struct {
+	orgName, name, version *string
+}
+
+// newFlagSet returns flag set of command *name* with flags of keys.
+func newFlagSet(name string) (*flag.FlagSet, keyFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	return fs, keyFlags{
+		orgName: fs.String("org", "", "name of organization"),
+		name:    fs.String("name", "", "name of module or feature bundle"),
+		version: fs.String("version", "", "version of module or feature bundle"),
+	}
+}
+
+// requireKey checks that all flags of keys are given.
+func (k keyFlags) requireKey() error {
+	if *k.orgName == "" || *k.name == "" || *k.version == "" {
+		return fmt.Errorf("-org, -name and -version are required")
+	}
+	return nil
+}
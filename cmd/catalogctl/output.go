@@ -0,0 +1,140 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openconfig/catalog-server/pkg/client"
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats of catalogctl.
+const (
+	formatTable = `table`
+	formatJSON  = `json`
+	formatYAML  = `yaml`
+)
+
+// item is a printed module or feature bundle.
+type item struct {
+	OrgName string      `json:"orgName" yaml:"orgName"`
+	Name    string      `json:"name" yaml:"name"`
+	Version string      `json:"version" yaml:"version"`
+	Data    interface{} `json:"data,omitempty" yaml:"data,omitempty"` // Decoded JSON data, only printed by get.
+}
+
+// comparison is a printed result of comparing an organization in two catalogs.
+type comparison struct {
+	OrgName     string              `json:"orgName" yaml:"orgName"`
+	Differences []client.Difference `json:"differences" yaml:"differences"`
+}
+
+// printer prints results of commands in a format.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// newPrinter returns printer writing to *w* in *format*.
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+		return &printer{w: w, format: format}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q, it should be %s, %s or %s", format, formatTable, formatJSON, formatYAML)
+}
+
+// encode prints *v* in JSON or YAML format.
+func (p *printer) encode(v interface{}) error {
+	if p.format == formatYAML {
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("marshal yaml failed: %v", err)
+		}
+		_, err = p.w.Write(data)
+		return err
+	}
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// items prints *items* as rows of table, or as a list in JSON or YAML format.
+func (p *printer) items(items []item) error {
+	if p.format != formatTable {
+		return p.encode(items)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ORG\tNAME\tVERSION")
+	for _, it := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", it.OrgName, it.Name, it.Version)
+	}
+	return tw.Flush()
+}
+
+// item prints a single item, table format prints its data in indented JSON after its key.
+func (p *printer) item(it item) error {
+	if p.format != formatTable {
+		return p.encode(it)
+	}
+	fmt.Fprintf(p.w, "%s %s@%s\n", it.OrgName, it.Name, it.Version)
+	data, err := json.MarshalIndent(it.Data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal data failed: %v", err)
+	}
+	_, err = fmt.Fprintln(p.w, string(data))
+	return err
+}
+
+// comparisons prints *comparisons* of organizations, table format prints a row of each difference
+// followed by indented rows of its differing fields.
+func (p *printer) comparisons(comparisons []comparison) error {
+	if p.format != formatTable {
+		return p.encode(comparisons)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ORG\tKIND\tNAME\tVERSION\tSTATUS")
+	for _, c := range comparisons {
+		for _, d := range c.Differences {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.OrgName, d.Kind, d.Name, d.Version, d.Status)
+			for _, f := range d.Fields {
+				fmt.Fprintf(tw, "\t\t\t\t  %s: %q -> %q\n", f.Path, f.Primary, f.Secondary)
+			}
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	var same []string
+	for _, c := range comparisons {
+		if len(c.Differences) == 0 {
+			same = append(same, c.OrgName)
+		}
+	}
+	if len(same) > 0 {
+		p.message("no differences in %s", strings.Join(same, ", "))
+	}
+	return nil
+}
+
+// message prints a message of progress of commands.
+func (p *printer) message(format string, args ...interface{}) {
+	fmt.Fprintf(p.w, format+"\n", args...)
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/catalog-server/pkg/client"
+)
+
+func TestPrinterItems(t *testing.T) {
+	items := []item{
+		{OrgName: "openconfig", Name: "openconfig-interfaces", Version: "1.0.0"},
+		{OrgName: "ietf", Name: "ietf-interfaces", Version: "2.0.0"},
+	}
+	tests := []struct {
+		desc    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			desc:   "table",
+			format: formatTable,
+			want: "ORG         NAME                   VERSION\n" +
+				"openconfig  openconfig-interfaces  1.0.0\n" +
+				"ietf        ietf-interfaces        2.0.0\n",
+		},
+		{
+			desc:   "json",
+			format: formatJSON,
+			want: `[
+  {
+    "orgName": "openconfig",
+    "name": "openconfig-interfaces",
+    "version": "1.0.0"
+  },
+  {
+    "orgName": "ietf",
+    "name": "ietf-interfaces",
+    "version": "2.0.0"
+  }
+]
+`,
+		},
+		{
+			desc:   "yaml",
+			format: formatYAML,
+			want: `- orgName: openconfig
+  name: openconfig-interfaces
+  version: 1.0.0
+- orgName: ietf
+  name: ietf-interfaces
+  version: 2.0.0
+`,
+		},
+		{
+			desc:    "unknown format",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			p, err := newPrinter(&buf, tc.format)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("newPrinter returned error %v, wantErr: %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := p.items(items); err != nil {
+				t.Fatalf("items failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, buf.String()); diff != "" {
+				t.Errorf("items output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrinterComparisons(t *testing.T) {
+	comparisons := []comparison{
+		{OrgName: "ietf"},
+		{OrgName: "openconfig", Differences: []client.Difference{
+			{Kind: client.KindModule, OrgName: "openconfig", Name: "openconfig-bgp", Version: "1.0.0", Status: client.OnlyInPrimary},
+			{Kind: client.KindModule, OrgName: "openconfig", Name: "openconfig-interfaces", Version: "1.0.0", Status: client.Changed,
+				Fields: []client.FieldDiff{{Path: "/summary", Primary: "interfaces"}}},
+		}},
+	}
+	var buf bytes.Buffer
+	p, err := newPrinter(&buf, formatTable)
+	if err != nil {
+		t.Fatalf("newPrinter failed: %v", err)
+	}
+	if err := p.comparisons(comparisons); err != nil {
+		t.Fatalf("comparisons failed: %v", err)
+	}
+	want := "ORG         KIND    NAME                   VERSION  STATUS\n" +
+		"openconfig  module  openconfig-bgp         1.0.0    onlyInPrimary\n" +
+		"openconfig  module  openconfig-interfaces  1.0.0    changed\n" +
+		"                                                      /summary: \"interfaces\" -> \"\"\n" +
+		"no differences in ietf\n"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("comparisons output mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -15,18 +15,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/openconfig/catalog-server/pkg/client"
+	"github.com/openconfig/catalog-server/pkg/client/gqlclient"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
 )
 
 // const values for demo purposes.
 const (
-	hostURL    = `https://helloworld-jpx33sh7ha-uc.a.run.app/query` // Address of deployed server.
-	graphQuery = `{ModulesByOrgName(OrgName:"1"){Data}}`            // GraphQL query.
-	queryName  = `ModulesByOrgName`                                 // Name of graphQL query.
+	serverURL = `https://helloworld-jpx33sh7ha-uc.a.run.app` // Address of deployed server.
+	orgName   = `1`                                          // Name of organization whose modules are queried.
 )
 
 // Demo program to show how to use client library functions.
@@ -34,25 +36,27 @@ func main() {
 	var tokenPathPtr = flag.String("token", "token", "file path of auth token")
 	flag.Parse()
 
-	// Format whole query URL.
-	queryURL := hostURL + `?query=` + graphQuery
-
 	// Initialize a new Client.
-	c, err := client.NewClient(*tokenPathPtr)
+	c, err := client.NewClient(*tokenPathPtr, client.WithServerURL(serverURL))
 	if err != nil {
 		glog.Fatalf("catalog client: new client failed: %v", err)
 	}
 
-	// Send query to server and receive response.
-	resp, err := c.QueryServer(queryURL)
+	// Send query generated from operations.graphql of package gqlclient to server and receive typed response.
+	org := orgName
+	resp, err := gqlclient.ModulesByOrgName(context.Background(), c, &org)
 	if err != nil {
 		glog.Fatalf("catalog client: query server failed: %v", err)
 	}
 
 	// Parse query results.
-	modules, err := client.ParseModule(resp, queryName)
-	if err != nil {
-		glog.Fatalf("catalog client: parse response into go catalog modules failed: %v", err)
+	var modules []oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module
+	for _, m := range resp.ModulesByOrgName {
+		module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+		if err := oc.Unmarshal([]byte(m.Data), module); err != nil {
+			glog.Fatalf("catalog client: parse response into go catalog modules failed: %v", err)
+		}
+		modules = append(modules, *module)
 	}
 
 	// Print out names of all matched modules just for testing parsing is correct.
@@ -0,0 +1,223 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+// This file will not be regenerated automatically.
+//
+// It contains helper functions used by resolvers in schema.resolvers.go.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/openconfig/catalog-server/graph/model"
+	"github.com/openconfig/catalog-server/pkg/access"
+	"github.com/openconfig/catalog-server/pkg/archive"
+	"github.com/openconfig/catalog-server/pkg/catalogdiff"
+	"github.com/openconfig/catalog-server/pkg/db"
+	"github.com/openconfig/catalog-server/pkg/validate"
+	"github.com/openconfig/catalog-server/pkg/yangsrc"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+	"github.com/openconfig/ygot/ygot"
+)
+
+// Default and maximum numbers of entries returned by AuditLog query.
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// parseTime parses optional time argument *s* in RFC 3339, nil is returned if *s* is not given.
+func parseTime(s *string) (*time.Time, error) {
+	if s == nil || *s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// issuesToGraphQL converts a slice of validation issues into GraphQL ValidationIssue response type.
+// Issues are sorted by path, and it always returns a non-nil slice as the response field is non-nullable.
+func issuesToGraphQL(issues []validate.Issue) []*validate.Issue {
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Path < issues[j].Path
+	})
+	res := []*validate.Issue{}
+	for i := 0; i < len(issues); i++ {
+		res = append(res, &issues[i])
+	}
+	return res
+}
+
+// queryOrgModules queries names and prefixes of all modules of organization *orgName* from database,
+// which are used by lint rules across modules, and returns them as ygot go structs of Module with only these fields.
+func queryOrgModules(orgName string) ([]*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	prefixes, err := db.QueryModulePrefixes(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("queryOrgModules: %v", err)
+	}
+	var modules []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module
+	for _, p := range prefixes {
+		module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{Name: ygot.String(p.Name)}
+		if p.Prefix != "" {
+			module.Prefix = ygot.String(p.Prefix)
+		}
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+// moduleToJSON serializes *module* into RFC7951 JSON string which is stored in database.
+func moduleToJSON(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) (string, error) {
+	json, err := ygot.EmitJSON(module, &ygot.EmitJSONConfig{
+		Format: ygot.RFC7951,
+		Indent: "  ",
+		RFC7951Config: &ygot.RFC7951JSONConfig{
+			AppendModuleName: true,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("moduleToJSON: marshalling into json string failed: %v", err)
+	}
+	return json, nil
+}
+
+// readFilter returns a function reporting whether owner of request *ctx* can read data of an organization,
+// based on visibility of organizations stored in database.
+func readFilter(ctx context.Context) (func(orgName string) bool, error) {
+	privateOrgs, err := db.QueryOrgsByVisibility(db.VisibilityPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("readFilter: %v", err)
+	}
+	return access.ReadFilter(ctx, privateOrgs), nil
+}
+
+// filterModules returns modules in *modules* of organizations that can be read according to *canRead*.
+func filterModules(modules []db.Module, canRead func(orgName string) bool) []db.Module {
+	var res []db.Module
+	for i := 0; i < len(modules); i++ {
+		if canRead(modules[i].OrgName) {
+			res = append(res, modules[i])
+		}
+	}
+	return res
+}
+
+// filterFeatureBundles returns feature bundles in *featureBundles* of organizations that can be read according to *canRead*.
+func filterFeatureBundles(featureBundles []db.FeatureBundle, canRead func(orgName string) bool) []db.FeatureBundle {
+	var res []db.FeatureBundle
+	for i := 0; i < len(featureBundles); i++ {
+		if canRead(featureBundles[i].OrgName) {
+			res = append(res, featureBundles[i])
+		}
+	}
+	return res
+}
+
+// diffEntries converts catalog entries *entries* given in CatalogSet into entries compared by catalogdiff.
+func diffEntries(entries []*model.CatalogEntry) []catalogdiff.Entry {
+	var res []catalogdiff.Entry
+	for _, entry := range entries {
+		res = append(res, catalogdiff.Entry{OrgName: entry.OrgName, Data: entry.Data})
+	}
+	return res
+}
+
+// diffSet returns entries of *set* compared by CatalogDiff. If feature bundle of *set* is given, it is resolved
+// by entries of organizations that can be read according to *canRead*, in addition to entries given in *set*.
+func diffSet(set model.CatalogSet, canRead func(orgName string) bool) (catalogdiff.Set, error) {
+	res := catalogdiff.Set{Modules: diffEntries(set.Modules), FeatureBundles: diffEntries(set.FeatureBundles)}
+	key := set.FeatureBundle
+	if key == nil {
+		return res, nil
+	}
+	manifest, err := archive.ResolveFeatureBundleManifest(key.OrgName, key.Name, key.Version, canRead)
+	if err != nil {
+		return res, fmt.Errorf("diffSet: %v", err)
+	}
+	if manifest == nil {
+		return res, fmt.Errorf("diffSet: feature bundle %s@%s of %s is not found", key.Name, key.Version, key.OrgName)
+	}
+	for _, fb := range manifest.FeatureBundles {
+		dbFeatureBundles, err := db.QueryFeatureBundlesByKey(&fb.Name, &fb.Version)
+		if err != nil {
+			return res, fmt.Errorf("diffSet: %v", err)
+		}
+		for _, dbFeatureBundle := range dbFeatureBundles {
+			if dbFeatureBundle.OrgName == fb.OrgName {
+				res.FeatureBundles = append(res.FeatureBundles, catalogdiff.Entry{OrgName: fb.OrgName, Data: dbFeatureBundle.Data})
+			}
+		}
+	}
+	for _, m := range manifest.Modules {
+		dbModules, err := db.QueryModulesByKey(&m.Name, &m.Version)
+		if err != nil {
+			return res, fmt.Errorf("diffSet: %v", err)
+		}
+		for _, dbModule := range dbModules {
+			if dbModule.OrgName == m.OrgName {
+				res.Modules = append(res.Modules, catalogdiff.Entry{OrgName: m.OrgName, Data: dbModule.Data})
+			}
+		}
+	}
+	return res, nil
+}
+
+// loadSources fills Source of *modules* with YANG sources looked up by md5-hash in their access in one query.
+// Source is null if module does not have md5-hash or its source is not stored.
+func loadSources(modules []*model.Module) error {
+	md5Hashes := map[*model.Module]string{}
+	var hashes []string
+	for _, m := range modules {
+		module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+		if err := oc.Unmarshal([]byte(m.Data), module); err != nil {
+			return fmt.Errorf("loadSources: cannot unmarshal JSON: %v", err)
+		}
+		if hash := strings.ToLower(module.GetAccess().GetMd5Hash()); hash != "" {
+			md5Hashes[m] = hash
+			hashes = append(hashes, hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	sources, err := yangsrc.LookupMD5s(hashes)
+	if err != nil {
+		return fmt.Errorf("loadSources: %v", err)
+	}
+	for m, hash := range md5Hashes {
+		if source, ok := sources[hash]; ok {
+			m.Source = &source.Data
+		}
+	}
+	return nil
+}
+
+// requestsSource returns whether selection of Modules resolved with *ctx* includes field Source.
+func requestsSource(ctx context.Context) bool {
+	for _, f := range graphql.CollectFieldsCtx(ctx, nil) {
+		if f.Name == "Source" {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,60 @@
 
 package model
 
+type APIToken struct {
+	ID          string   `json:"ID"`
+	OrgNames    []string `json:"OrgNames"`
+	Operations  []string `json:"Operations"`
+	Description string   `json:"Description"`
+	CreatedBy   string   `json:"CreatedBy"`
+	CreatedAt   string   `json:"CreatedAt"`
+	ExpiresAt   *string  `json:"ExpiresAt"`
+	Revoked     bool     `json:"Revoked"`
+}
+
+type AccessGrant struct {
+	OrgName   string `json:"OrgName"`
+	Member    string `json:"Member"`
+	Role      string `json:"Role"`
+	GrantedBy string `json:"GrantedBy"`
+	GrantedAt string `json:"GrantedAt"`
+}
+
+type AccessRequest struct {
+	ID          string  `json:"ID"`
+	OrgName     string  `json:"OrgName"`
+	Member      string  `json:"Member"`
+	Role        string  `json:"Role"`
+	Message     string  `json:"Message"`
+	RequestedBy string  `json:"RequestedBy"`
+	RequestedAt string  `json:"RequestedAt"`
+	Status      string  `json:"Status"`
+	ReviewedBy  *string `json:"ReviewedBy"`
+	ReviewedAt  *string `json:"ReviewedAt"`
+}
+
+type AuditEntry struct {
+	Time      string `json:"Time"`
+	Principal string `json:"Principal"`
+	Subject   string `json:"Subject"`
+	OrgName   string `json:"OrgName"`
+	Operation string `json:"Operation"`
+	Target    string `json:"Target"`
+	Outcome   string `json:"Outcome"`
+	Error     string `json:"Error"`
+}
+
+type CatalogEntry struct {
+	OrgName string `json:"OrgName"`
+	Data    string `json:"Data"`
+}
+
+type CatalogSet struct {
+	FeatureBundle  *FeatureBundleKey `json:"FeatureBundle"`
+	Modules        []*CatalogEntry   `json:"Modules"`
+	FeatureBundles []*CatalogEntry   `json:"FeatureBundles"`
+}
+
 type FeatureBundle struct {
 	OrgName string `json:"OrgName"`
 	Name    string `json:"Name"`
@@ -16,12 +70,13 @@ type FeatureBundleKey struct {
 }
 
 type Module struct {
-	OrgName string `json:"OrgName"`
-	Name    string `json:"Name"`
-	Version string `json:"Version"`
-	URL     string `json:"URL"`
-	Summary string `json:"Summary"`
-	Data    string `json:"Data"`
+	OrgName string  `json:"OrgName"`
+	Name    string  `json:"Name"`
+	Version string  `json:"Version"`
+	URL     string  `json:"URL"`
+	Summary string  `json:"Summary"`
+	Data    string  `json:"Data"`
+	Source  *string `json:"Source"`
 }
 
 type ModuleKey struct {
@@ -30,12 +85,26 @@ type ModuleKey struct {
 	Version string `json:"Version"`
 }
 
+type NewAPIToken struct {
+	OrgNames      []string `json:"OrgNames"`
+	Operations    []string `json:"Operations"`
+	Description   *string  `json:"Description"`
+	ExpiresInDays *int     `json:"ExpiresInDays"`
+}
+
+type NewAPITokenResult struct {
+	Token    string    `json:"Token"`
+	APIToken *APIToken `json:"APIToken"`
+}
+
 type NewFeatureBundle struct {
 	OrgName string `json:"OrgName"`
 	Data    string `json:"Data"`
 }
 
 type NewModule struct {
-	OrgName string `json:"OrgName"`
-	Data    string `json:"Data"`
+	OrgName          string   `json:"OrgName"`
+	Data             string   `json:"Data"`
+	Source           *string  `json:"Source"`
+	SubmoduleSources []string `json:"SubmoduleSources"`
 }
@@ -20,21 +20,32 @@ package graph
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/openconfig/catalog-server/graph/generated"
 	"github.com/openconfig/catalog-server/graph/model"
 	"github.com/openconfig/catalog-server/pkg/access"
+	"github.com/openconfig/catalog-server/pkg/audit"
+	"github.com/openconfig/catalog-server/pkg/catalogdiff"
 	"github.com/openconfig/catalog-server/pkg/db"
 	"github.com/openconfig/catalog-server/pkg/dbtograph"
 	"github.com/openconfig/catalog-server/pkg/validate"
+	"github.com/openconfig/catalog-server/pkg/yangsrc"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
 )
 
-func (r *mutationResolver) CreateModule(ctx context.Context, input model.NewModule, token string) (string, error) {
+func (r *moduleResolver) Source(ctx context.Context, obj *model.Module) (*string, error) {
+	// YANG sources of all modules in results are looked up by their md5-hash in one query by loadSources,
+	// it is null if source is not stored.
+	return obj.Source, nil
+}
+
+func (r *mutationResolver) CreateModule(ctx context.Context, input model.NewModule, token *string) (string, error) {
 	failMsg := `Fail`
 	successMsg := `Success`
 
-	// Validate the token and check whether it contains access to certain organization
-	if err := access.CheckAccess(token, input.OrgName); err != nil {
+	// Validate the token in Authorization header or argument and check whether its owner can publish in certain organization
+	if err := access.CheckAccess(ctx, token, input.OrgName, access.OpPublish); err != nil {
 		return failMsg, fmt.Errorf("CreateModule: validate token failed: %v", err)
 	}
 
@@ -44,20 +55,46 @@ func (r *mutationResolver) CreateModule(ctx context.Context, input model.NewModu
 		return failMsg, fmt.Errorf("CreateModule: validate module failed: %v", err)
 	}
 
+	// Check lint rules configured for this organization.
+	orgModules, err := queryOrgModules(input.OrgName)
+	if err != nil {
+		return failMsg, fmt.Errorf("CreateModule: query modules of organization failed: %v", err)
+	}
+	if err := validate.IssuesToError(validate.LintModule(input.OrgName, module, orgModules)); err != nil {
+		return failMsg, fmt.Errorf("CreateModule: lint module failed: %v", err)
+	}
+
+	// If YANG source is given, verify module against it, and store module with md5-hash filled in.
+	data := input.Data
+	if input.Source != nil {
+		if err := validate.IssuesToError(yangsrc.VerifyModule(module, *input.Source, input.SubmoduleSources)); err != nil {
+			return failMsg, fmt.Errorf("CreateModule: verify module against YANG source failed: %v", err)
+		}
+		// Store YANG sources, so that they can be downloaded from catalog server.
+		for _, source := range append([]string{*input.Source}, input.SubmoduleSources...) {
+			if _, err := yangsrc.Store(source); err != nil {
+				return failMsg, fmt.Errorf("CreateModule: store YANG source failed: %v", err)
+			}
+		}
+		if data, err = moduleToJSON(module); err != nil {
+			return failMsg, fmt.Errorf("CreateModule: %v", err)
+		}
+	}
+
 	// Insert module if not exist, or update it.
-	if err := db.InsertModule(input.OrgName, module.GetName(), module.GetVersion(), input.Data); err != nil {
+	if err := db.InsertModule(input.OrgName, module.GetName(), module.GetVersion(), data); err != nil {
 		return failMsg, fmt.Errorf("CreateModule failed: %v", err)
 	}
 
 	return successMsg, nil
 }
 
-func (r *mutationResolver) DeleteModule(ctx context.Context, input model.ModuleKey, token string) (string, error) {
+func (r *mutationResolver) DeleteModule(ctx context.Context, input model.ModuleKey, token *string) (string, error) {
 	failMsg := `Fail`
 	successMsg := `Success`
 
-	// Validate the token and check whether it contains access to certain organization
-	if err := access.CheckAccess(token, input.OrgName); err != nil {
+	// Validate the token in Authorization header or argument and check whether its owner can delete in certain organization
+	if err := access.CheckAccess(ctx, token, input.OrgName, access.OpDelete); err != nil {
 		return failMsg, fmt.Errorf("DeleteModule: validate token failed: %v", err)
 	}
 
@@ -69,12 +106,12 @@ func (r *mutationResolver) DeleteModule(ctx context.Context, input model.ModuleK
 	return successMsg, nil
 }
 
-func (r *mutationResolver) CreateFeatureBundle(ctx context.Context, input model.NewFeatureBundle, token string) (string, error) {
+func (r *mutationResolver) CreateFeatureBundle(ctx context.Context, input model.NewFeatureBundle, token *string) (string, error) {
 	failMsg := `Fail`
 	successMsg := `Success`
 
-	// Validate the token and check whether it contains access to certain organization
-	if err := access.CheckAccess(token, input.OrgName); err != nil {
+	// Validate the token in Authorization header or argument and check whether its owner can publish in certain organization
+	if err := access.CheckAccess(ctx, token, input.OrgName, access.OpPublish); err != nil {
 		return failMsg, fmt.Errorf("CreateFeatureBundle: validate token failed: %v", err)
 	}
 
@@ -84,6 +121,11 @@ func (r *mutationResolver) CreateFeatureBundle(ctx context.Context, input model.
 		return failMsg, fmt.Errorf("CreateFeatureBundle: validate featureBundle failed: %v", err)
 	}
 
+	// Check lint rules configured for this organization.
+	if err := validate.IssuesToError(validate.LintFeatureBundle(input.OrgName, featureBundle)); err != nil {
+		return failMsg, fmt.Errorf("CreateFeatureBundle: lint featureBundle failed: %v", err)
+	}
+
 	// Insert module if not exist, or update it.
 	if err := db.InsertFeatureBundle(input.OrgName, featureBundle.GetName(), featureBundle.GetVersion(), input.Data); err != nil {
 		return failMsg, fmt.Errorf("CreateFeatureBundle failed: %v", err)
@@ -92,12 +134,12 @@ func (r *mutationResolver) CreateFeatureBundle(ctx context.Context, input model.
 	return successMsg, nil
 }
 
-func (r *mutationResolver) DeleteFeatureBundle(ctx context.Context, input model.FeatureBundleKey, token string) (string, error) {
+func (r *mutationResolver) DeleteFeatureBundle(ctx context.Context, input model.FeatureBundleKey, token *string) (string, error) {
 	failMsg := `Fail`
 	successMsg := `Success`
 
-	// Validate the token and check whether it contains access to certain organization
-	if err := access.CheckAccess(token, input.OrgName); err != nil {
+	// Validate the token in Authorization header or argument and check whether its owner can delete in certain organization
+	if err := access.CheckAccess(ctx, token, input.OrgName, access.OpDelete); err != nil {
 		return failMsg, fmt.Errorf("DeleteFeatureBundle: validate token failed: %v", err)
 	}
 
@@ -109,12 +151,303 @@ func (r *mutationResolver) DeleteFeatureBundle(ctx context.Context, input model.
 	return successMsg, nil
 }
 
+func (r *mutationResolver) SetOrgVisibility(ctx context.Context, orgName string, visibility string) (string, error) {
+	failMsg := `Fail`
+	successMsg := `Success`
+
+	// Validate the token in Authorization header and check whether its owner can administer certain organization
+	if err := access.CheckAccess(ctx, nil, orgName, access.OpAdminister); err != nil {
+		return failMsg, fmt.Errorf("SetOrgVisibility: validate token failed: %v", err)
+	}
+
+	if visibility != db.VisibilityPublic && visibility != db.VisibilityPrivate {
+		return failMsg, fmt.Errorf("SetOrgVisibility: visibility should be %s or %s", db.VisibilityPublic, db.VisibilityPrivate)
+	}
+	if err := db.SetOrgVisibility(orgName, visibility); err != nil {
+		return failMsg, fmt.Errorf("SetOrgVisibility failed: %v", err)
+	}
+
+	return successMsg, nil
+}
+
+func (r *mutationResolver) CreateAPIToken(ctx context.Context, input model.NewAPIToken) (*model.NewAPITokenResult, error) {
+	// Owner of token in Authorization header can only mint API tokens within its own roles.
+	scope := &access.Scope{OrgNames: input.OrgNames}
+	audit.SetOrgNames(ctx, input.OrgNames...)
+	for _, op := range input.Operations {
+		if _, err := access.RequiredRole(access.Operation(op)); err != nil {
+			return nil, fmt.Errorf("CreateAPIToken: %v", err)
+		}
+		scope.Operations = append(scope.Operations, access.Operation(op))
+	}
+	if err := access.CheckScope(ctx, scope); err != nil {
+		return nil, fmt.Errorf("CreateAPIToken: validate token failed: %v", err)
+	}
+	principal, err := access.Authenticate(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAPIToken: %v", err)
+	}
+	// Tokens are limited by current roles of creator when they are verified.
+	creatorRoles, err := access.CreatorRoles(principal)
+	if err != nil {
+		return nil, fmt.Errorf("CreateAPIToken: %v", err)
+	}
+
+	token, id, hash, err := access.NewAPIToken()
+	if err != nil {
+		return nil, fmt.Errorf("CreateAPIToken: %v", err)
+	}
+	apiToken := db.APIToken{
+		ID:           id,
+		Hash:         hash,
+		OrgNames:     input.OrgNames,
+		Operations:   input.Operations,
+		CreatedBy:    principal.Subject,
+		CreatorEmail: principal.Email,
+		CreatorRoles: creatorRoles,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if input.Description != nil {
+		apiToken.Description = *input.Description
+	}
+	if input.ExpiresInDays != nil {
+		if *input.ExpiresInDays <= 0 {
+			return nil, fmt.Errorf("CreateAPIToken: ExpiresInDays should be positive")
+		}
+		expiresAt := apiToken.CreatedAt.AddDate(0, 0, *input.ExpiresInDays)
+		apiToken.ExpiresAt = &expiresAt
+	}
+	if err := db.InsertAPIToken(apiToken); err != nil {
+		return nil, fmt.Errorf("CreateAPIToken failed: %v", err)
+	}
+
+	return &model.NewAPITokenResult{
+		Token:    token,
+		APIToken: dbtograph.APITokenToGraphQL([]db.APIToken{apiToken})[0],
+	}, nil
+}
+
+func (r *mutationResolver) RevokeAPIToken(ctx context.Context, id string) (string, error) {
+	failMsg := `Fail`
+	successMsg := `Success`
+
+	apiToken, err := db.QueryAPITokenByID(id)
+	if err != nil {
+		return failMsg, fmt.Errorf("RevokeAPIToken: %v", err)
+	}
+	if apiToken == nil {
+		return failMsg, fmt.Errorf("RevokeAPIToken: API token %s does not exist", id)
+	}
+	audit.SetOrgNames(ctx, apiToken.OrgNames...)
+
+	// API token can be revoked by its creator, or admins of all organizations it is scoped to.
+	principal, err := access.Authenticate(ctx, nil)
+	if err != nil {
+		return failMsg, fmt.Errorf("RevokeAPIToken: user does not provide valid token: %v", err)
+	}
+	if principal.Scope != nil || principal.Subject != apiToken.CreatedBy {
+		for _, orgName := range apiToken.OrgNames {
+			if err := access.CheckAccess(ctx, nil, orgName, access.OpAdminister); err != nil {
+				return failMsg, fmt.Errorf("RevokeAPIToken: validate token failed: %v", err)
+			}
+		}
+	}
+
+	if err := db.RevokeAPIToken(id); err != nil {
+		return failMsg, fmt.Errorf("RevokeAPIToken failed: %v", err)
+	}
+
+	return successMsg, nil
+}
+
+func (r *mutationResolver) GrantAccess(ctx context.Context, orgName string, member string, role string) (string, error) {
+	failMsg := `Fail`
+	successMsg := `Success`
+
+	// Validate the token in Authorization header and check whether its owner can administer certain organization
+	if err := access.CheckAccess(ctx, nil, orgName, access.OpAdminister); err != nil {
+		return failMsg, fmt.Errorf("GrantAccess: validate token failed: %v", err)
+	}
+	principal, err := access.Authenticate(ctx, nil)
+	if err != nil {
+		return failMsg, fmt.Errorf("GrantAccess: %v", err)
+	}
+	if principal.Scope != nil {
+		return failMsg, fmt.Errorf("GrantAccess: access cannot be granted by API tokens")
+	}
+
+	member = access.NormalizeMember(member)
+	if member == "" {
+		return failMsg, fmt.Errorf("GrantAccess: member should not be empty")
+	}
+	if parsed, err := access.ParseRole(role); err != nil || parsed == access.RoleNone {
+		return failMsg, fmt.Errorf("GrantAccess: invalid role %q, use RevokeAccess to remove access", role)
+	}
+	grant := db.AccessGrant{
+		OrgName:   orgName,
+		Member:    member,
+		Role:      role,
+		GrantedBy: principal.Subject,
+		GrantedAt: time.Now().UTC(),
+	}
+	if err := db.InsertAccessGrant(grant); err != nil {
+		return failMsg, fmt.Errorf("GrantAccess failed: %v", err)
+	}
+
+	return successMsg, nil
+}
+
+func (r *mutationResolver) RevokeAccess(ctx context.Context, orgName string, member string) (string, error) {
+	failMsg := `Fail`
+	successMsg := `Success`
+
+	// Validate the token in Authorization header and check whether its owner can administer certain organization
+	if err := access.CheckAccess(ctx, nil, orgName, access.OpAdminister); err != nil {
+		return failMsg, fmt.Errorf("RevokeAccess: validate token failed: %v", err)
+	}
+
+	member = access.NormalizeMember(member)
+	if member == "" {
+		return failMsg, fmt.Errorf("RevokeAccess: member should not be empty")
+	}
+	if err := db.DeleteAccessGrant(orgName, member); err != nil {
+		return failMsg, fmt.Errorf("RevokeAccess failed: %v", err)
+	}
+
+	return successMsg, nil
+}
+
+func (r *mutationResolver) RequestOrgAccess(ctx context.Context, orgName string, role string, message *string) (*model.AccessRequest, error) {
+	principal, err := access.Authenticate(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("RequestOrgAccess: user does not provide valid token: %v", err)
+	}
+	if principal.Scope != nil {
+		return nil, fmt.Errorf("RequestOrgAccess: access cannot be requested by API tokens")
+	}
+	if orgName == "" {
+		return nil, fmt.Errorf("RequestOrgAccess: name of organization should not be empty")
+	}
+	requested, err := access.ParseRole(role)
+	if err != nil || requested == access.RoleNone {
+		return nil, fmt.Errorf("RequestOrgAccess: invalid role %q", role)
+	}
+	roles, err := access.Roles(principal)
+	if err != nil {
+		return nil, fmt.Errorf("RequestOrgAccess: %v", err)
+	}
+	if current := access.RoleIn(roles, orgName); current >= requested {
+		return nil, fmt.Errorf("RequestOrgAccess: user is already %s of organization %s", current, orgName)
+	}
+
+	member := access.Member(principal)
+	pending, err := db.QueryAccessRequestsByOrgName(orgName, db.AccessRequestPending)
+	if err != nil {
+		return nil, fmt.Errorf("RequestOrgAccess: %v", err)
+	}
+	for _, request := range pending {
+		if request.Member == member {
+			return nil, fmt.Errorf("RequestOrgAccess: request %s of organization %s is pending", request.ID, orgName)
+		}
+	}
+
+	id, err := access.NewAccessRequestID()
+	if err != nil {
+		return nil, fmt.Errorf("RequestOrgAccess: %v", err)
+	}
+	request := db.AccessRequest{
+		ID:          id,
+		OrgName:     orgName,
+		Member:      member,
+		Role:        role,
+		RequestedBy: principal.Subject,
+		RequestedAt: time.Now().UTC(),
+		Status:      db.AccessRequestPending,
+	}
+	if message != nil {
+		request.Message = *message
+	}
+	if err := db.InsertAccessRequest(request); err != nil {
+		return nil, fmt.Errorf("RequestOrgAccess failed: %v", err)
+	}
+
+	return dbtograph.AccessRequestToGraphQL([]db.AccessRequest{request})[0], nil
+}
+
+func (r *mutationResolver) ApproveOrgAccess(ctx context.Context, id string, approve bool) (string, error) {
+	failMsg := `Fail`
+	successMsg := `Success`
+
+	request, err := db.QueryAccessRequestByID(id)
+	if err != nil {
+		return failMsg, fmt.Errorf("ApproveOrgAccess: %v", err)
+	}
+	if request == nil {
+		return failMsg, fmt.Errorf("ApproveOrgAccess: access request %s does not exist", id)
+	}
+	audit.SetOrgNames(ctx, request.OrgName)
+	if request.Status != db.AccessRequestPending {
+		return failMsg, fmt.Errorf("ApproveOrgAccess: access request %s is already %s", id, request.Status)
+	}
+	role, err := access.ParseRole(request.Role)
+	if err != nil {
+		return failMsg, fmt.Errorf("ApproveOrgAccess: %v", err)
+	}
+
+	// Validate the token in Authorization header and check whether its owner can review this request.
+	if err := access.CheckReview(ctx, request.OrgName, role); err != nil {
+		return failMsg, fmt.Errorf("ApproveOrgAccess: validate token failed: %v", err)
+	}
+	principal, err := access.Authenticate(ctx, nil)
+	if err != nil {
+		return failMsg, fmt.Errorf("ApproveOrgAccess: %v", err)
+	}
+
+	now := time.Now().UTC()
+	status := db.AccessRequestRejected
+	if approve {
+		status = db.AccessRequestApproved
+	}
+	// Mark request as reviewed first, so that concurrent reviews cannot both take effect.
+	if err := db.ReviewAccessRequest(id, status, principal.Subject, now); err != nil {
+		return failMsg, fmt.Errorf("ApproveOrgAccess failed: %v", err)
+	}
+	if approve {
+		grant := db.AccessGrant{
+			OrgName:   request.OrgName,
+			Member:    request.Member,
+			Role:      request.Role,
+			GrantedBy: principal.Subject,
+			GrantedAt: now,
+		}
+		if err := db.InsertAccessGrant(grant); err != nil {
+			return failMsg, fmt.Errorf("ApproveOrgAccess failed: %v", err)
+		}
+	}
+
+	return successMsg, nil
+}
+
 func (r *queryResolver) ModulesByOrgName(ctx context.Context, orgName *string) ([]*model.Module, error) {
 	dbModules, err := db.QueryModulesByOrgName(orgName)
 	if err != nil {
 		return nil, err
 	}
-	return dbtograph.ModuleToGraphQL(dbModules)
+	// Data of private organizations are only returned to users with read access.
+	canRead, err := readFilter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ModulesByOrgName: %v", err)
+	}
+	modules, err := dbtograph.ModuleToGraphQL(filterModules(dbModules, canRead))
+	if err != nil {
+		return nil, err
+	}
+	if requestsSource(ctx) {
+		if err := loadSources(modules); err != nil {
+			return nil, fmt.Errorf("ModulesByOrgName: %v", err)
+		}
+	}
+	return modules, nil
 }
 
 func (r *queryResolver) ModulesByKey(ctx context.Context, name *string, version *string) ([]*model.Module, error) {
@@ -122,7 +455,21 @@ func (r *queryResolver) ModulesByKey(ctx context.Context, name *string, version
 	if err != nil {
 		return nil, err
 	}
-	return dbtograph.ModuleToGraphQL(dbModules)
+	// Data of private organizations are only returned to users with read access.
+	canRead, err := readFilter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ModulesByKey: %v", err)
+	}
+	modules, err := dbtograph.ModuleToGraphQL(filterModules(dbModules, canRead))
+	if err != nil {
+		return nil, err
+	}
+	if requestsSource(ctx) {
+		if err := loadSources(modules); err != nil {
+			return nil, fmt.Errorf("ModulesByKey: %v", err)
+		}
+	}
+	return modules, nil
 }
 
 func (r *queryResolver) FeatureBundlesByOrgName(ctx context.Context, orgName *string) ([]*model.FeatureBundle, error) {
@@ -130,7 +477,12 @@ func (r *queryResolver) FeatureBundlesByOrgName(ctx context.Context, orgName *st
 	if err != nil {
 		return nil, err
 	}
-	return dbtograph.FeatureBundleToGraphQL(dbFeatureBundles)
+	// Data of private organizations are only returned to users with read access.
+	canRead, err := readFilter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("FeatureBundlesByOrgName: %v", err)
+	}
+	return dbtograph.FeatureBundleToGraphQL(filterFeatureBundles(dbFeatureBundles, canRead))
 }
 
 func (r *queryResolver) FeatureBundlesByKey(ctx context.Context, name *string, version *string) ([]*model.FeatureBundle, error) {
@@ -138,14 +490,165 @@ func (r *queryResolver) FeatureBundlesByKey(ctx context.Context, name *string, v
 	if err != nil {
 		return nil, err
 	}
-	return dbtograph.FeatureBundleToGraphQL(dbFeatureBundles)
+	// Data of private organizations are only returned to users with read access.
+	canRead, err := readFilter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("FeatureBundlesByKey: %v", err)
+	}
+	return dbtograph.FeatureBundleToGraphQL(filterFeatureBundles(dbFeatureBundles, canRead))
+}
+
+func (r *queryResolver) ValidateModule(ctx context.Context, data string, source *string, submoduleSources []string) ([]*validate.Issue, error) {
+	// Dry run of validation, module is not inserted into database.
+	module, issues := validate.CheckModule(data)
+	if module != nil && source != nil {
+		issues = append(issues, yangsrc.VerifyModule(module, *source, submoduleSources)...)
+	}
+	return issuesToGraphQL(issues), nil
+}
+
+func (r *queryResolver) ValidateFeatureBundle(ctx context.Context, data string) ([]*validate.Issue, error) {
+	// Dry run of validation, featureBundle is not inserted into database.
+	_, issues := validate.CheckFeatureBundle(data)
+	return issuesToGraphQL(issues), nil
 }
 
+func (r *queryResolver) LintModule(ctx context.Context, orgName string, data string) ([]*validate.Issue, error) {
+	module, issues := validate.CheckModule(data)
+	if module != nil {
+		canRead, err := readFilter(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("LintModule: %v", err)
+		}
+		// Existing modules of private organizations are not exposed by lint issues to users without read access.
+		var orgModules []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module
+		if canRead(orgName) {
+			if orgModules, err = queryOrgModules(orgName); err != nil {
+				return nil, fmt.Errorf("LintModule: query modules of organization failed: %v", err)
+			}
+		}
+		issues = append(issues, validate.LintModule(orgName, module, orgModules)...)
+	}
+	return issuesToGraphQL(issues), nil
+}
+
+func (r *queryResolver) LintFeatureBundle(ctx context.Context, orgName string, data string) ([]*validate.Issue, error) {
+	featureBundle, issues := validate.CheckFeatureBundle(data)
+	if featureBundle != nil {
+		issues = append(issues, validate.LintFeatureBundle(orgName, featureBundle)...)
+	}
+	return issuesToGraphQL(issues), nil
+}
+
+func (r *queryResolver) ListAPITokens(ctx context.Context, orgName string) ([]*model.APIToken, error) {
+	// API tokens of organization can only be listed by its admins.
+	if err := access.CheckAccess(ctx, nil, orgName, access.OpAdminister); err != nil {
+		return nil, fmt.Errorf("ListAPITokens: validate token failed: %v", err)
+	}
+	dbAPITokens, err := db.QueryAPITokensByOrgName(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("ListAPITokens: %v", err)
+	}
+	return dbtograph.APITokenToGraphQL(dbAPITokens), nil
+}
+
+func (r *queryResolver) ListAccess(ctx context.Context, orgName string) ([]*model.AccessGrant, error) {
+	// Access grants of organization can only be listed by its admins.
+	if err := access.CheckAccess(ctx, nil, orgName, access.OpAdminister); err != nil {
+		return nil, fmt.Errorf("ListAccess: validate token failed: %v", err)
+	}
+	dbAccessGrants, err := db.QueryAccessGrantsByOrgName(orgName)
+	if err != nil {
+		return nil, fmt.Errorf("ListAccess: %v", err)
+	}
+	return dbtograph.AccessGrantToGraphQL(dbAccessGrants), nil
+}
+
+func (r *queryResolver) PendingAccessRequests(ctx context.Context, orgName string) ([]*model.AccessRequest, error) {
+	// Pending access requests of organization can only be listed by its reviewers.
+	if err := access.CheckAccess(ctx, nil, orgName, access.OpReview); err != nil {
+		return nil, fmt.Errorf("PendingAccessRequests: validate token failed: %v", err)
+	}
+	dbAccessRequests, err := db.QueryAccessRequestsByOrgName(orgName, db.AccessRequestPending)
+	if err != nil {
+		return nil, fmt.Errorf("PendingAccessRequests: %v", err)
+	}
+	return dbtograph.AccessRequestToGraphQL(dbAccessRequests), nil
+}
+
+func (r *queryResolver) AuditLog(ctx context.Context, principal *string, orgName *string, since *string, until *string, limit *int) ([]*model.AuditEntry, error) {
+	filter := db.AuditFilter{Limit: defaultAuditLimit}
+	if principal != nil {
+		filter.Principal = access.NormalizeMember(*principal)
+	}
+	if orgName != nil {
+		filter.OrgName = *orgName
+	}
+
+	// Audit log of organization can only be searched by its admins, and audit log of all organizations by admins of all organizations.
+	adminOrg := filter.OrgName
+	if adminOrg == "" {
+		adminOrg = access.AllOrgs
+	}
+	if err := access.CheckAccess(ctx, nil, adminOrg, access.OpAdminister); err != nil {
+		return nil, fmt.Errorf("AuditLog: validate token failed: %v", err)
+	}
+
+	var err error
+	if filter.Since, err = parseTime(since); err != nil {
+		return nil, fmt.Errorf("AuditLog: invalid Since: %v", err)
+	}
+	if filter.Until, err = parseTime(until); err != nil {
+		return nil, fmt.Errorf("AuditLog: invalid Until: %v", err)
+	}
+	if limit != nil {
+		if *limit <= 0 || *limit > maxAuditLimit {
+			return nil, fmt.Errorf("AuditLog: Limit should be in [1, %d]", maxAuditLimit)
+		}
+		filter.Limit = *limit
+	}
+
+	dbAuditEntries, err := db.QueryAuditLog(filter)
+	if err != nil {
+		return nil, fmt.Errorf("AuditLog: %v", err)
+	}
+	return dbtograph.AuditEntryToGraphQL(dbAuditEntries), nil
+}
+
+func (r *queryResolver) CatalogDiff(ctx context.Context, from model.CatalogSet, to model.CatalogSet, matchByName bool) ([]*catalogdiff.Change, error) {
+	// Release feature bundles are resolved by data of organizations that requester can read.
+	canRead, err := readFilter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("CatalogDiff: %v", err)
+	}
+	fromSet, err := diffSet(from, canRead)
+	if err != nil {
+		return nil, fmt.Errorf("CatalogDiff: from: %v", err)
+	}
+	toSet, err := diffSet(to, canRead)
+	if err != nil {
+		return nil, fmt.Errorf("CatalogDiff: to: %v", err)
+	}
+	changes, err := catalogdiff.Diff(fromSet, toSet, matchByName)
+	if err != nil {
+		return nil, fmt.Errorf("CatalogDiff: %v", err)
+	}
+	res := []*catalogdiff.Change{}
+	for i := range changes {
+		res = append(res, &changes[i])
+	}
+	return res, nil
+}
+
+// Module returns generated.ModuleResolver implementation.
+func (r *Resolver) Module() generated.ModuleResolver { return &moduleResolver{r} }
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
 // Query returns generated.QueryResolver implementation.
 func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
 
+type moduleResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
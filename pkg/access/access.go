@@ -15,6 +15,8 @@
 /*
 Package access contains function to validate token and parse access from a valid token
   for write operations (i.e., create and update).
+  Tokens are verified by Authenticator of the provider selected by $AUTH_PROVIDER,
+  which can be firebase, OpenID Connect or static API keys.
 */
 package access
 
@@ -22,13 +24,12 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"strings"
-
-	firebase "firebase.google.com/go/v4"
+	"sort"
+	"sync"
 )
 
 // const variables related to token validation.
-// *delimiter* is delimiter for claim string of a list of names of organizations that the token owner has access to.
+// *delimiter* is delimiter for claim string of a list of organizations and roles of the token owner in them, see ParseRoles.
 // *accessField* is field name of claim that contains the list of names of organizations that one has access to.
 // Note that organization's names should not contain delimiter.
 const (
@@ -36,6 +37,42 @@ const (
 	baseAccessField = `allow`
 )
 
+// authenticator verifies tokens of write operations, it is set by Init.
+var (
+	authenticator   Authenticator
+	authenticatorMu sync.Mutex
+)
+
+// Init initializes Authenticator of provider configured by environment variables (see NewAuthenticator),
+// which caches verified tokens. It should be called once when catalog server starts,
+// otherwise it is called when the first token is verified.
+func Init(ctx context.Context) error {
+	a, err := NewAuthenticator(ctx)
+	if err != nil {
+		return fmt.Errorf("Init: %v", err)
+	}
+	authenticatorMu.Lock()
+	defer authenticatorMu.Unlock()
+	authenticator = NewCachingAuthenticator(a, defaultCacheSize)
+	return nil
+}
+
+// getAuthenticator returns the initialized Authenticator, and initializes it if Init is not called.
+func getAuthenticator(ctx context.Context) (Authenticator, error) {
+	authenticatorMu.Lock()
+	a := authenticator
+	authenticatorMu.Unlock()
+	if a != nil {
+		return a, nil
+	}
+	if err := Init(ctx); err != nil {
+		return nil, err
+	}
+	authenticatorMu.Lock()
+	defer authenticatorMu.Unlock()
+	return authenticator, nil
+}
+
 func GetAccessField() (string, error) {
 	// name of target database
 	dbname, ok := os.LookupEnv("DB_NAME")
@@ -46,70 +83,121 @@ func GetAccessField() (string, error) {
 }
 
 // ParseAccess takes input of a token string.
-// It first validates whether the token is valid using firebase,
-// then parses from the token's claims a list organization names to which that the token owner has write access.
+// It first validates whether the token is valid using Authenticator initialized by Init,
+// then parses from the token's claims and grants in database a list organization names in which that the token owner has any role.
 // If token is invalid, an error is returned.
 func ParseAccess(token string) ([]string, error) {
-	// Set up firebase configuration to use correct token validation method.
-	ctx := context.Background()
-	projectID, ok := os.LookupEnv("PROJECT_ID")
-	if !ok {
-		return nil, fmt.Errorf("$PROJECT_ID not set")
-	}
-	config := &firebase.Config{ProjectID: projectID}
-	app, err := firebase.NewApp(ctx, config)
+	// Use authenticator to validate token, verified tokens are cached.
+	principal, err := Verify(context.Background(), token)
 	if err != nil {
-		return nil, fmt.Errorf("ParseAccess: error initializing app: %v\n", err)
+		return nil, fmt.Errorf("ParseAccess: %v", err)
 	}
-	client, err := app.Auth(ctx)
+	roles, err := Roles(principal)
 	if err != nil {
-		return nil, fmt.Errorf("ParseAccess: generate firebase authentication admin failed")
+		return nil, fmt.Errorf("ParseAccess: %v", err)
+	}
+	var allowOrgs []string
+	for orgName := range roles {
+		allowOrgs = append(allowOrgs, orgName)
 	}
+	sort.Strings(allowOrgs)
+	return allowOrgs, nil
+}
 
-	// Use firebase to validate token
-	verifiedToken, err := client.VerifyIDToken(ctx, token)
+// Roles returns roles of *principal* keyed by organization's names, which merges roles
+// parsed from its claims (see ParseClaim) and roles granted in database (see GrantedRoles).
+// If an organization appears in both, the higher role is kept.
+func Roles(principal *Principal) (map[string]Role, error) {
+	roles, err := ClaimRoles(principal)
+	if err != nil {
+		return nil, err
+	}
+	granted, err := GrantedRoles(principal)
 	if err != nil {
-		return nil, fmt.Errorf("ParseAccess: error verifying ID token: %v\n", err)
+		return nil, err
 	}
+	return mergeRoles(roles, granted), nil
+}
 
+// ClaimRoles returns roles of *principal* parsed from its claims keyed by organization's names.
+func ClaimRoles(principal *Principal) (map[string]Role, error) {
 	accessField, err := GetAccessField()
 	if err != nil {
-		return nil, fmt.Errorf("ParseAccess: get access field failed: %v", err)
+		return nil, fmt.Errorf("get access field failed: %v", err)
 	}
 
-	// Retrieve *accessField* from claims, if the field does not exist, return an error.
-	allowClaims, ok := verifiedToken.Claims[accessField]
-	if !ok {
-		return nil, fmt.Errorf("ParseAccess: verified token does not contain allow claims: %s", accessField)
+	roles := map[string]Role{}
+	// Retrieve *accessField* from claims, principals without the field may still have roles granted in database.
+	if allowClaims, ok := principal.Claims[accessField]; ok {
+		if roles, err = ParseClaim(allowClaims); err != nil {
+			return nil, fmt.Errorf("invalid allow claims %s: %v", accessField, err)
+		}
 	}
+	return roles, nil
+}
 
-	// Split string into a slice of names of organizations.
-	allowOrgs := strings.Split(allowClaims.(string), delimiter)
-	return allowOrgs, nil
+// mergeRoles adds *other* into *roles* and returns it, the higher role is kept in organizations appearing in both.
+func mergeRoles(roles map[string]Role, other map[string]Role) map[string]Role {
+	for orgName, role := range other {
+		if role > roles[orgName] {
+			roles[orgName] = role
+		}
+	}
+	return roles
 }
 
-// This function takes input of a string of token and a string of organization's name.
-// It checks whether the given token in valid and whether it contains access for write operation to *orgName*.
-// If not, an error is returned.
-func CheckAccess(token string, orgName string) error {
+// Authenticate returns owner of the request of *ctx*.
+// The bearer token in Authorization header verified by Middleware is preferred.
+// *token* given as argument of GraphQL mutation is deprecated, and it is only used
+// when the request does not carry a valid bearer token, e.g., the header is consumed by a proxy.
+func Authenticate(ctx context.Context, token *string) (*Principal, error) {
+	principal, headerErr := PrincipalFromContext(ctx)
+	if principal != nil {
+		return principal, nil
+	}
+	if token != nil && *token != "" {
+		return Verify(ctx, *token)
+	}
+	if headerErr != nil {
+		return nil, headerErr
+	}
+	return nil, fmt.Errorf("no token is given in Authorization header")
+}
+
+// This function takes input of request context *ctx*, deprecated *token* argument, a string of organization's name and an operation.
+// It checks whether the request is authenticated (see Authenticate) and whether its owner has the role required by *op*
+// in *orgName*. If not, an error is returned.
+func CheckAccess(ctx context.Context, token *string, orgName string, op Operation) error {
+	required, err := RequiredRole(op)
+	if err != nil {
+		return fmt.Errorf("CheckAccess: %v", err)
+	}
+
 	// Validate token
-	allowOrgs, err := ParseAccess(token)
+	principal, err := Authenticate(ctx, token)
 	if err != nil {
 		return fmt.Errorf("CheckAccess: user does not provide valid token: %v", err)
 	}
 
-	// Check whether this account has access to such orgnization.
-	hasAccess := false
-	for _, allowOrg := range allowOrgs {
-		if allowOrg == orgName {
-			hasAccess = true
-			break
+	// API tokens are limited to operations in organizations of their scopes.
+	if principal.Scope != nil {
+		if !principal.Scope.Allows(orgName, op) {
+			return fmt.Errorf("CheckAccess: API token is not allowed to %s in organization %s", op, orgName)
 		}
+		return nil
 	}
 
-	// If the token does not contain access to input.OrgName, return an error.
-	if !hasAccess {
-		return fmt.Errorf("CheckAccess: user does not have access to organization %s", orgName)
+	roles, err := Roles(principal)
+	if err != nil {
+		return fmt.Errorf("CheckAccess: %v", err)
+	}
+
+	// If the token does not contain the required role in orgName, return an error.
+	if role := RoleIn(roles, orgName); role < required {
+		if role == RoleNone {
+			return fmt.Errorf("CheckAccess: user does not have access to organization %s", orgName)
+		}
+		return fmt.Errorf("CheckAccess: user is %s of organization %s, but %s requires %s", role, orgName, op, required)
 	}
 
 	return nil
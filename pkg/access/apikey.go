@@ -0,0 +1,81 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// APIKey is one static API key in file of API keys.
+type APIKey struct {
+	Key     string      `json:"key"`     // The API key, which is used as token.
+	Subject string      `json:"subject"` // Name of owner of the key.
+	Allow   interface{} `json:"allow"`   // Organizations that owner has access to in any shape supported by ParseClaim.
+}
+
+// APIKeyAuthenticator verifies static API keys.
+type APIKeyAuthenticator struct {
+	keys map[[sha256.Size]byte]*Principal // Owners of API keys keyed by sha256 hash of keys.
+}
+
+// NewAPIKeyAuthenticator returns an Authenticator verifying API keys read from JSON file *filepath*.
+// The file contains a list of APIKey, e.g., `[{"key": "secret", "subject": "ci", "allow": {"openconfig": "publisher"}}]`.
+// Organizations in "allow" are set as claim named by GetAccessField in Principal of the key.
+func NewAPIKeyAuthenticator(filepath string) (*APIKeyAuthenticator, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("NewAPIKeyAuthenticator: read file failed: %v", err)
+	}
+	var apiKeys []APIKey
+	if err := json.Unmarshal(data, &apiKeys); err != nil {
+		return nil, fmt.Errorf("NewAPIKeyAuthenticator: parse file failed: %v", err)
+	}
+	accessField, err := GetAccessField()
+	if err != nil {
+		return nil, fmt.Errorf("NewAPIKeyAuthenticator: get access field failed: %v", err)
+	}
+
+	a := &APIKeyAuthenticator{keys: map[[sha256.Size]byte]*Principal{}}
+	for _, apiKey := range apiKeys {
+		if apiKey.Key == "" {
+			return nil, fmt.Errorf("NewAPIKeyAuthenticator: API key of %s is empty", apiKey.Subject)
+		}
+		if apiKey.Allow == nil {
+			apiKey.Allow = ""
+		}
+		if _, err := ParseClaim(apiKey.Allow); err != nil {
+			return nil, fmt.Errorf("NewAPIKeyAuthenticator: invalid allow of API key of %s: %v", apiKey.Subject, err)
+		}
+		a.keys[sha256.Sum256([]byte(apiKey.Key))] = &Principal{
+			Subject: apiKey.Subject,
+			Claims:  map[string]interface{}{accessField: apiKey.Allow},
+		}
+	}
+	return a, nil
+}
+
+// Verify checks whether *token* is one of API keys.
+// Keys are compared by their hashes so that lookup time does not depend on how much of a key matches.
+func (a *APIKeyAuthenticator) Verify(ctx context.Context, token string) (*Principal, error) {
+	principal, ok := a.keys[sha256.Sum256([]byte(token))]
+	if !ok {
+		return nil, fmt.Errorf("unknown API key")
+	}
+	return principal, nil
+}
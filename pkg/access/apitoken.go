@@ -0,0 +1,186 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/catalog-server/pkg/db"
+)
+
+// APITokenPrefix is prefix of API tokens minted by catalog server,
+// which distinguishes them from tokens of authentication providers.
+const APITokenPrefix = `catalog_`
+
+// Scope limits a Principal to operations in organizations, e.g., Principal of an API token.
+type Scope struct {
+	OrgNames   []string    // Organizations that principal can access, AllOrgs is not allowed.
+	Operations []Operation // Operations that principal can perform in these organizations.
+	// CreatorRoles are current roles of creator of API token, operations are allowed only if these roles still
+	// allow them, so that demoting a user also limits its tokens. Nil if scope is not limited by them.
+	CreatorRoles map[string]Role
+}
+
+// Allows checks whether *s* allows operation *op* in organization *orgName*.
+func (s *Scope) Allows(orgName string, op Operation) bool {
+	orgAllowed, opAllowed := false, false
+	for _, o := range s.OrgNames {
+		orgAllowed = orgAllowed || o == orgName
+	}
+	for _, o := range s.Operations {
+		opAllowed = opAllowed || o == op
+	}
+	if s.CreatorRoles != nil {
+		required, err := RequiredRole(op)
+		if err != nil || RoleIn(s.CreatorRoles, orgName) < required {
+			return false
+		}
+	}
+	return orgAllowed && opAllowed
+}
+
+// queryAPITokenByHash queries stored API token by its hash, which is replaced in tests.
+var queryAPITokenByHash = db.QueryAPITokenByHash
+
+// NewAPIToken mints a new API token. It returns the token, which is only shown to its creator once,
+// together with the id and hash of the token to be stored.
+func NewAPIToken() (token string, id string, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("NewAPIToken: generate random token failed: %v", err)
+	}
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", "", fmt.Errorf("NewAPIToken: generate random id failed: %v", err)
+	}
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return token, hex.EncodeToString(idBytes), HashAPIToken(token), nil
+}
+
+// HashAPIToken returns hex encoded sha256 hash of API *token*, which is stored instead of the token.
+func HashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// IsAPIToken checks whether *token* is an API token minted by catalog server.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// verifyAPIToken looks up API *token* in database, and returns a Principal limited to scope of the token
+// and current roles of its creator.
+// API tokens are not cached so that revoked tokens and demoted creators are rejected immediately.
+func verifyAPIToken(token string) (*Principal, error) {
+	stored, err := queryAPITokenByHash(HashAPIToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("look up API token failed: %v", err)
+	}
+	if stored == nil {
+		return nil, fmt.Errorf("unknown API token")
+	}
+	if stored.Revoked {
+		return nil, fmt.Errorf("API token %s is revoked", stored.ID)
+	}
+	creatorRoles, err := storedCreatorRoles(stored)
+	if err != nil {
+		return nil, fmt.Errorf("API token %s: %v", stored.ID, err)
+	}
+	principal := &Principal{
+		Subject: "api-token:" + stored.ID,
+		Claims:  map[string]interface{}{},
+		Scope:   &Scope{OrgNames: stored.OrgNames, CreatorRoles: creatorRoles},
+	}
+	for _, op := range stored.Operations {
+		principal.Scope.Operations = append(principal.Scope.Operations, Operation(op))
+	}
+	if stored.ExpiresAt != nil {
+		if !timeNow().Before(*stored.ExpiresAt) {
+			return nil, fmt.Errorf("API token %s expired at %v", stored.ID, *stored.ExpiresAt)
+		}
+		principal.Expiry = *stored.ExpiresAt
+	}
+	return principal, nil
+}
+
+// CreatorRoles returns roles in claims of *principal* encoded as JSON object, which is stored with API tokens it creates.
+// Claims are only known when the creator authenticates, so roles in claims are those at minting,
+// while roles granted in database are looked up whenever API tokens are verified.
+func CreatorRoles(principal *Principal) (string, error) {
+	roles, err := ClaimRoles(principal)
+	if err != nil {
+		return "", fmt.Errorf("CreatorRoles: %v", err)
+	}
+	b, err := json.Marshal(FormatClaim(roles))
+	if err != nil {
+		return "", fmt.Errorf("CreatorRoles: marshal roles failed: %v", err)
+	}
+	return string(b), nil
+}
+
+// storedCreatorRoles returns current roles of creator of *stored* API token, which merges roles in claims
+// stored with the token and roles granted in database to creator's subject or email.
+func storedCreatorRoles(stored *db.APIToken) (map[string]Role, error) {
+	roles := map[string]Role{}
+	if stored.CreatorRoles != "" {
+		var claim interface{}
+		if err := json.Unmarshal([]byte(stored.CreatorRoles), &claim); err != nil {
+			return nil, fmt.Errorf("invalid roles of creator: %v", err)
+		}
+		var err error
+		if roles, err = ParseClaim(claim); err != nil {
+			return nil, fmt.Errorf("invalid roles of creator: %v", err)
+		}
+	}
+	granted, err := GrantedRoles(&Principal{Subject: stored.CreatedBy, Email: stored.CreatorEmail})
+	if err != nil {
+		return nil, err
+	}
+	return mergeRoles(roles, granted), nil
+}
+
+// CheckScope checks whether owner of request *ctx* can mint an API token of *scope*.
+// The owner must be authenticated by an authentication provider instead of another API token,
+// and have the roles required by all operations of *scope* in all its organizations.
+func CheckScope(ctx context.Context, scope *Scope) error {
+	if len(scope.OrgNames) == 0 || len(scope.Operations) == 0 {
+		return fmt.Errorf("CheckScope: scope should contain organizations and operations")
+	}
+	principal, err := Authenticate(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("CheckScope: user does not provide valid token: %v", err)
+	}
+	if principal.Scope != nil {
+		return fmt.Errorf("CheckScope: API tokens cannot be created by API tokens")
+	}
+	for _, orgName := range scope.OrgNames {
+		if orgName == AllOrgs {
+			return fmt.Errorf("CheckScope: API tokens cannot be scoped to all organizations")
+		}
+		for _, op := range scope.Operations {
+			if err := CheckAccess(ctx, nil, orgName, op); err != nil {
+				return fmt.Errorf("CheckScope: %v", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/openconfig/catalog-server/pkg/db"
+)
+
+func TestAPIToken(t *testing.T) {
+	os.Setenv("DB_NAME", "catalog")
+	defer os.Unsetenv("DB_NAME")
+	now := time.Unix(1600000000, 0)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	token, id, hash, err := NewAPIToken()
+	if err != nil {
+		t.Fatalf("NewAPIToken failed: %v", err)
+	}
+	if !IsAPIToken(token) || hash != HashAPIToken(token) {
+		t.Fatalf("NewAPIToken returned invalid token")
+	}
+	expired := now.Add(-time.Hour)
+	stored := map[string]*db.APIToken{
+		hash:                      {ID: id, Hash: hash, OrgNames: []string{"openconfig"}, Operations: []string{"publish"}, CreatorRoles: `{"openconfig": "publisher"}`},
+		HashAPIToken("catalog_x"): {ID: "revoked", OrgNames: []string{"openconfig"}, Operations: []string{"publish"}, Revoked: true},
+		HashAPIToken("catalog_y"): {ID: "expired", OrgNames: []string{"openconfig"}, Operations: []string{"publish"}, ExpiresAt: &expired},
+	}
+	queryAPITokenByHash = func(hash string) (*db.APIToken, error) { return stored[hash], nil }
+	defer func() { queryAPITokenByHash = db.QueryAPITokenByHash }()
+
+	for _, invalid := range []string{"catalog_x", "catalog_y", "catalog_z"} {
+		if _, err := Verify(context.Background(), invalid); err == nil {
+			t.Errorf("Verify should fail for revoked, expired or unknown API token %s", invalid)
+		}
+	}
+
+	principal, err := Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	ctx := WithPrincipal(context.Background(), principal)
+	tests := []struct {
+		desc    string
+		orgName string
+		op      Operation
+		wantErr bool
+	}{
+		{
+			desc:    "operation in scope",
+			orgName: "openconfig",
+			op:      OpPublish,
+		},
+		{
+			desc:    "operation not in scope",
+			orgName: "openconfig",
+			op:      OpDelete,
+			wantErr: true,
+		},
+		{
+			desc:    "organization not in scope",
+			orgName: "ietf",
+			op:      OpPublish,
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := CheckAccess(ctx, nil, tc.orgName, tc.op)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckAccess error mismatch, got: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	if err := CheckScope(ctx, &Scope{OrgNames: []string{"openconfig"}, Operations: []Operation{OpPublish}}); err == nil {
+		t.Errorf("CheckScope should fail for API token")
+	}
+	userCtx := WithPrincipal(context.Background(), &Principal{Claims: map[string]interface{}{"catalog-allow": "openconfig:publisher"}})
+	if err := CheckScope(userCtx, &Scope{OrgNames: []string{"openconfig"}, Operations: []Operation{OpPublish}}); err != nil {
+		t.Errorf("CheckScope failed for scope within roles of user: %v", err)
+	}
+	if err := CheckScope(userCtx, &Scope{OrgNames: []string{"openconfig"}, Operations: []Operation{OpDelete}}); err == nil {
+		t.Errorf("CheckScope should fail for scope beyond roles of user")
+	}
+	user, _ := PrincipalFromContext(userCtx)
+	if roles, err := CreatorRoles(user); err != nil || roles != `{"openconfig":"publisher"}` {
+		t.Errorf("CreatorRoles returned %s, err: %v, want roles in claims of user", roles, err)
+	}
+}
+
+func TestAPITokenDemotedCreator(t *testing.T) {
+	os.Setenv("DB_NAME", "catalog")
+	defer os.Unsetenv("DB_NAME")
+	grants := map[string][]db.AccessGrant{
+		"alice@example.com": {{OrgName: "openconfig", Member: "alice@example.com", Role: "publisher"}},
+	}
+	queryAccessGrantsByMembers = func(members []string) ([]db.AccessGrant, error) {
+		var res []db.AccessGrant
+		for _, member := range members {
+			res = append(res, grants[member]...)
+		}
+		return res, nil
+	}
+	defer func() { queryAccessGrantsByMembers = db.QueryAccessGrantsByMembers }()
+	stored := map[string]*db.APIToken{
+		HashAPIToken("catalog_granted"): {
+			ID: "granted", OrgNames: []string{"openconfig"}, Operations: []string{"publish"},
+			CreatedBy: "uid-alice", CreatorEmail: "alice@example.com", CreatorRoles: `{}`,
+		},
+		HashAPIToken("catalog_claimed"): {
+			ID: "claimed", OrgNames: []string{"openconfig"}, Operations: []string{"publish"},
+			CreatedBy: "uid-bob", CreatorRoles: `{"openconfig": "reader"}`,
+		},
+	}
+	queryAPITokenByHash = func(hash string) (*db.APIToken, error) { return stored[hash], nil }
+	defer func() { queryAPITokenByHash = db.QueryAPITokenByHash }()
+
+	checkPublish := func(token string) error {
+		principal, err := Verify(context.Background(), token)
+		if err != nil {
+			t.Fatalf("Verify failed: %v", err)
+		}
+		return CheckAccess(WithPrincipal(context.Background(), principal), nil, "openconfig", OpPublish)
+	}
+	if err := checkPublish("catalog_granted"); err != nil {
+		t.Errorf("CheckAccess failed for API token whose creator is granted the required role: %v", err)
+	}
+	// Demote creator to reader after the token is minted.
+	grants["alice@example.com"][0].Role = "reader"
+	if err := checkPublish("catalog_granted"); err == nil {
+		t.Errorf("CheckAccess should fail for API token whose creator is demoted")
+	}
+	if err := checkPublish("catalog_claimed"); err == nil {
+		t.Errorf("CheckAccess should fail for API token whose creator does not have the required role in claims")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Names of authentication providers, which are set by $AUTH_PROVIDER.
+const (
+	ProviderFirebase = `firebase` // Firebase ID tokens of project $PROJECT_ID, which is the default provider.
+	ProviderOIDC     = `oidc`     // JWTs issued by a generic OpenID Connect provider and verified with its JWKS.
+	ProviderAPIKey   = `apikey`   // Static API keys read from file $API_KEYS_FILE.
+)
+
+// Principal is the verified owner of a token.
+type Principal struct {
+	Subject string                 // Subject of token, e.g., user id of token owner.
+	Email   string                 // Verified email of token owner, empty if token does not carry one.
+	Claims  map[string]interface{} // Claims of token, containing claim named by GetAccessField.
+	Expiry  time.Time              // Time when token expires, zero if token does not expire.
+	Scope   *Scope                 // Scope of API token, nil if principal is not limited by a scope.
+}
+
+// Authenticator verifies tokens issued by an authentication provider.
+type Authenticator interface {
+	// Verify verifies *token* and returns its owner, an error is returned if *token* is invalid.
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+// NewAuthenticator returns the Authenticator of provider selected by $AUTH_PROVIDER,
+// which is configured by environment variables as follows:
+//   - firebase (default): $PROJECT_ID is the firebase project issuing ID tokens.
+//   - oidc: $OIDC_ISSUER is the issuer of tokens, and $OIDC_AUDIENCE is the expected audience.
+//     $OIDC_JWKS_URL is URL of JWKS to verify tokens, which is discovered from the issuer if not set.
+//   - apikey: $API_KEYS_FILE is path of JSON file of API keys, see NewAPIKeyAuthenticator.
+func NewAuthenticator(ctx context.Context) (Authenticator, error) {
+	provider := os.Getenv("AUTH_PROVIDER")
+	switch provider {
+	case "", ProviderFirebase:
+		projectID, ok := os.LookupEnv("PROJECT_ID")
+		if !ok {
+			return nil, fmt.Errorf("NewAuthenticator: $PROJECT_ID not set")
+		}
+		return NewFirebaseAuthenticator(ctx, projectID)
+	case ProviderOIDC:
+		issuer, ok := os.LookupEnv("OIDC_ISSUER")
+		if !ok {
+			return nil, fmt.Errorf("NewAuthenticator: $OIDC_ISSUER not set")
+		}
+		audience, ok := os.LookupEnv("OIDC_AUDIENCE")
+		if !ok {
+			return nil, fmt.Errorf("NewAuthenticator: $OIDC_AUDIENCE not set")
+		}
+		return NewOIDCAuthenticator(ctx, issuer, audience, os.Getenv("OIDC_JWKS_URL"))
+	case ProviderAPIKey:
+		filepath, ok := os.LookupEnv("API_KEYS_FILE")
+		if !ok {
+			return nil, fmt.Errorf("NewAuthenticator: $API_KEYS_FILE not set")
+		}
+		return NewAPIKeyAuthenticator(filepath)
+	}
+	return nil, fmt.Errorf("NewAuthenticator: unknown authentication provider %s", provider)
+}
+
+// verifiedEmail returns email in *claims* of an ID token if it is verified by the provider.
+func verifiedEmail(claims map[string]interface{}) string {
+	email, _ := claims["email"].(string)
+	if verified, _ := claims["email_verified"].(bool); !verified {
+		return ""
+	}
+	return email
+}
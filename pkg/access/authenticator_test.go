@@ -0,0 +1,211 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testAudience = "catalog"
+
+// signJWT returns a JWT of *claims* signed by *key* with algorithm *alg* and key id *kid*.
+func signJWT(t *testing.T, alg string, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	h := signingAlgorithms[alg].hash.New()
+	h.Write([]byte(signed))
+	var signature []byte
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, signingAlgorithms[alg].hash, h.Sum(nil)); err != nil {
+			t.Fatalf("sign JWT failed: %v", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, h.Sum(nil))
+		if err != nil {
+			t.Fatalf("sign JWT failed: %v", err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// newOIDCServer returns a fake OpenID Connect provider serving discovery document and JWKS of *rsaKey* and *ecKey*.
+func newOIDCServer(rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) *httptest.Server {
+	encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"jwks_uri": server.URL + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string][]jwk{"keys": {
+			{Kid: "rsa", Kty: "RSA", N: encode(rsaKey.N), E: encode(big.NewInt(int64(rsaKey.E)))},
+			{Kid: "ec", Kty: "EC", Crv: "P-256", X: encode(ecKey.X), Y: encode(ecKey.Y)},
+		}})
+	})
+	server = httptest.NewServer(mux)
+	return server
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key failed: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate EC key failed: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key failed: %v", err)
+	}
+	server := newOIDCServer(rsaKey, ecKey)
+	defer server.Close()
+	issuer := server.URL
+
+	now := time.Unix(1600000000, 0)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	claims := func(update map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":           issuer,
+			"aud":           []string{"other", testAudience},
+			"sub":           "user",
+			"exp":           now.Add(time.Hour).Unix(),
+			"catalog-allow": "openconfig",
+		}
+		for k, v := range update {
+			c[k] = v
+		}
+		return c
+	}
+
+	tests := []struct {
+		desc    string
+		token   string
+		wantErr bool
+	}{
+		{
+			desc:  "RS256 token",
+			token: signJWT(t, "RS256", "rsa", rsaKey, claims(nil)),
+		},
+		{
+			desc:  "ES256 token",
+			token: signJWT(t, "ES256", "ec", ecKey, claims(nil)),
+		},
+		{
+			desc:    "signed by other key",
+			token:   signJWT(t, "RS256", "rsa", otherKey, claims(nil)),
+			wantErr: true,
+		},
+		{
+			desc:    "unknown key id",
+			token:   signJWT(t, "RS256", "other", otherKey, claims(nil)),
+			wantErr: true,
+		},
+		{
+			desc:    "algorithm not matching key",
+			token:   signJWT(t, "RS256", "ec", rsaKey, claims(nil)),
+			wantErr: true,
+		},
+		{
+			desc:    "wrong issuer",
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"iss": "https://other.example.com"})),
+			wantErr: true,
+		},
+		{
+			desc:    "wrong audience",
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"aud": "other"})),
+			wantErr: true,
+		},
+		{
+			desc:    "expired token",
+			token:   signJWT(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()})),
+			wantErr: true,
+		},
+		{
+			desc:    "not a JWT",
+			token:   "token",
+			wantErr: true,
+		},
+	}
+
+	// JWKS is discovered from discovery document of issuer.
+	a, err := NewOIDCAuthenticator(context.Background(), issuer, testAudience, "")
+	if err != nil {
+		t.Fatalf("NewOIDCAuthenticator failed: %v", err)
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			principal, err := a.Verify(context.Background(), tc.token)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Verify should fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if principal.Subject != "user" || principal.Claims["catalog-allow"] != "openconfig" || !principal.Expiry.Equal(now.Add(time.Hour)) {
+				t.Errorf("Verify returned wrong principal: %+v", principal)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	os.Setenv("DB_NAME", "catalog")
+	defer os.Unsetenv("DB_NAME")
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, []byte(`[{"key": "secret", "subject": "ci", "allow": "openconfig,ietf"}]`), 0600); err != nil {
+		t.Fatalf("write API keys file failed: %v", err)
+	}
+	a, err := NewAPIKeyAuthenticator(path)
+	if err != nil {
+		t.Fatalf("NewAPIKeyAuthenticator failed: %v", err)
+	}
+
+	principal, err := a.Verify(context.Background(), "secret")
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if principal.Subject != "ci" || principal.Claims["catalog-allow"] != "openconfig,ietf" {
+		t.Errorf("Verify returned wrong principal: %+v", principal)
+	}
+	if _, err := a.Verify(context.Background(), "wrong"); err == nil {
+		t.Errorf("Verify should fail for unknown API key")
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCacheSize is the maximum number of verified tokens kept in cache.
+	defaultCacheSize = 1024
+	// maxCacheTTL is the maximum time a verified token is kept in cache,
+	// so that revoked tokens or keys are not accepted for too long.
+	maxCacheTTL = 5 * time.Minute
+)
+
+// CachingAuthenticator caches principals of tokens verified by another Authenticator.
+// Tokens are keyed by their sha256 hashes so that raw tokens are not kept in memory.
+// Cached entries expire at the earlier of token's expiry and maxCacheTTL after verification,
+// and the least recently used entry is evicted when cache is full.
+type CachingAuthenticator struct {
+	authenticator Authenticator
+	size          int
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	lru     *list.List // Elements are *cacheEntry, the most recently used at front.
+}
+
+// cacheEntry is a verified token in cache.
+type cacheEntry struct {
+	key       [sha256.Size]byte
+	principal *Principal
+	expiry    time.Time
+}
+
+// NewCachingAuthenticator returns an Authenticator caching at most *size* tokens verified by *authenticator*.
+func NewCachingAuthenticator(authenticator Authenticator, size int) *CachingAuthenticator {
+	return &CachingAuthenticator{
+		authenticator: authenticator,
+		size:          size,
+		entries:       map[[sha256.Size]byte]*list.Element{},
+		lru:           list.New(),
+	}
+}
+
+// Verify returns cached principal of *token* if it is not expired,
+// otherwise it verifies *token* and caches its principal. Invalid tokens are not cached.
+func (c *CachingAuthenticator) Verify(ctx context.Context, token string) (*Principal, error) {
+	key := sha256.Sum256([]byte(token))
+	now := timeNow()
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if now.Before(entry.expiry) {
+			c.lru.MoveToFront(elem)
+			c.mu.Unlock()
+			return entry.principal, nil
+		}
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+
+	principal, err := c.authenticator.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	expiry := now.Add(maxCacheTTL)
+	if !principal.Expiry.IsZero() && principal.Expiry.Before(expiry) {
+		expiry = principal.Expiry
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		// The same token is verified concurrently.
+		c.lru.Remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, principal: principal, expiry: expiry})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	return principal, nil
+}
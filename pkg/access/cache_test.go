@@ -0,0 +1,94 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// countingAuthenticator accepts tokens in *expiries* and counts how many times each token is verified.
+type countingAuthenticator struct {
+	expiries map[string]time.Time
+	counts   map[string]int
+}
+
+func (a *countingAuthenticator) Verify(ctx context.Context, token string) (*Principal, error) {
+	a.counts[token]++
+	expiry, ok := a.expiries[token]
+	if !ok {
+		return nil, fmt.Errorf("invalid token")
+	}
+	return &Principal{Subject: token, Expiry: expiry}, nil
+}
+
+func TestCachingAuthenticator(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	fake := &countingAuthenticator{
+		expiries: map[string]time.Time{
+			"long":   {},                   // Token without expiry is cached for maxCacheTTL.
+			"short":  now.Add(time.Minute), // Token expiring before maxCacheTTL is cached until its expiry.
+			"other1": now.Add(time.Hour),
+			"other2": now.Add(time.Hour),
+			"other3": now.Add(time.Hour),
+		},
+		counts: map[string]int{},
+	}
+	c := NewCachingAuthenticator(fake, 3)
+	verify := func(token string) {
+		if _, err := c.Verify(context.Background(), token); err != nil && fake.expiries[token] != (time.Time{}) {
+			t.Fatalf("Verify %s failed: %v", token, err)
+		}
+	}
+
+	verify("long")
+	verify("short")
+	verify("long")
+	verify("short")
+	verify("invalid")
+	verify("invalid")
+	if fake.counts["long"] != 1 || fake.counts["short"] != 1 || fake.counts["invalid"] != 2 {
+		t.Errorf("tokens should be verified once and invalid tokens should not be cached, counts: %v", fake.counts)
+	}
+
+	now = now.Add(2 * time.Minute)
+	verify("long")
+	verify("short")
+	if fake.counts["long"] != 1 || fake.counts["short"] != 2 {
+		t.Errorf("only expired token should be verified again, counts: %v", fake.counts)
+	}
+
+	now = now.Add(maxCacheTTL)
+	verify("long")
+	if fake.counts["long"] != 2 {
+		t.Errorf("token should be verified again after maxCacheTTL, counts: %v", fake.counts)
+	}
+
+	// Cache holds at most 3 tokens, so the least recently used "other1" is evicted when "other3" is added.
+	verify("other1")
+	verify("other2")
+	verify("long")
+	verify("other3")
+	verify("long")
+	verify("other1")
+	if fake.counts["long"] != 2 || fake.counts["other1"] != 2 {
+		t.Errorf("the least recently used token should be evicted, counts: %v", fake.counts)
+	}
+}
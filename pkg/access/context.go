@@ -0,0 +1,96 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// bearerScheme is authentication scheme of Authorization header carrying a bearer token.
+const bearerScheme = `Bearer`
+
+// contextKey is type of keys of values put on request context by this package.
+type contextKey int
+
+// authKey is key of *authResult on request context.
+const authKey contextKey = 0
+
+// authResult is result of verifying bearer token of a request.
+type authResult struct {
+	principal *Principal
+	err       error
+}
+
+// Middleware returns a handler that verifies bearer token in Authorization header of requests once,
+// and puts the verified Principal, or the verification error, on request context before calling *next*.
+// Requests with invalid tokens are not rejected here, since queries do not need tokens,
+// resolvers of write operations get the result by PrincipalFromContext.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r.Header.Get("Authorization"))
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		principal, err := Verify(r.Context(), token)
+		ctx := context.WithValue(r.Context(), authKey, &authResult{principal: principal, err: err})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// bearerToken returns token in Authorization *header* and whether its scheme is Bearer.
+// Authentication scheme is case-insensitive as specified by RFC 7235.
+func bearerToken(header string) (string, bool) {
+	fields := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], bearerScheme) {
+		return "", false
+	}
+	return strings.TrimSpace(fields[1]), true
+}
+
+// WithPrincipal returns a copy of *ctx* carrying verified *principal*.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, authKey, &authResult{principal: principal})
+}
+
+// PrincipalFromContext returns Principal of bearer token verified by Middleware.
+// If request does not carry a bearer token, both returned Principal and error are nil.
+// If bearer token is invalid, the verification error is returned.
+func PrincipalFromContext(ctx context.Context) (*Principal, error) {
+	result, ok := ctx.Value(authKey).(*authResult)
+	if !ok {
+		return nil, nil
+	}
+	if result.err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %v", result.err)
+	}
+	return result.principal, nil
+}
+
+// Verify verifies *token* using Authenticator initialized by Init, and returns its owner.
+// API tokens minted by catalog server are verified against database instead.
+func Verify(ctx context.Context, token string) (*Principal, error) {
+	if IsAPIToken(token) {
+		return verifyAPIToken(token)
+	}
+	a, err := getAuthenticator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return a.Verify(ctx, token)
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareAuthenticate(t *testing.T) {
+	authenticator = &countingAuthenticator{
+		expiries: map[string]time.Time{"header": {}, "argument": {}},
+		counts:   map[string]int{},
+	}
+	defer func() { authenticator = nil }()
+	argument := "argument"
+	invalid := "invalid"
+
+	tests := []struct {
+		desc          string
+		authorization string
+		token         *string
+		wantSubject   string
+		wantErr       bool
+	}{
+		{
+			desc:          "bearer token in header",
+			authorization: "Bearer header",
+			wantSubject:   "header",
+		},
+		{
+			desc:          "bearer scheme is case-insensitive",
+			authorization: "bearer header",
+			wantSubject:   "header",
+		},
+		{
+			desc:          "header is preferred to deprecated argument",
+			authorization: "Bearer header",
+			token:         &argument,
+			wantSubject:   "header",
+		},
+		{
+			desc:          "deprecated argument when header is invalid",
+			authorization: "Bearer invalid",
+			token:         &argument,
+			wantSubject:   "argument",
+		},
+		{
+			desc:        "deprecated argument without header",
+			token:       &argument,
+			wantSubject: "argument",
+		},
+		{
+			desc:          "invalid header",
+			authorization: "Bearer invalid",
+			wantErr:       true,
+		},
+		{
+			desc:          "header without bearer token",
+			authorization: "Basic header",
+			wantErr:       true,
+		},
+		{
+			desc:    "invalid argument",
+			token:   &invalid,
+			wantErr: true,
+		},
+		{
+			desc:    "no token",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			var principal *Principal
+			var err error
+			handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				principal, err = Authenticate(r.Context(), tc.token)
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/query", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Authenticate should fail, got principal: %+v", principal)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate failed: %v", err)
+			}
+			if principal.Subject != tc.wantSubject {
+				t.Errorf("Authenticate returned wrong principal, got: %s, want: %s", principal.Subject, tc.wantSubject)
+			}
+		})
+	}
+
+	principal, err := Authenticate(WithPrincipal(context.Background(), &Principal{Subject: "context"}), nil)
+	if err != nil || principal.Subject != "context" {
+		t.Errorf("Authenticate should return principal on context, got: %+v, err: %v", principal, err)
+	}
+}
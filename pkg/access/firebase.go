@@ -0,0 +1,57 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/auth"
+)
+
+// FirebaseAuthenticator verifies firebase ID tokens.
+type FirebaseAuthenticator struct {
+	client *auth.Client
+}
+
+// NewFirebaseAuthenticator returns an Authenticator verifying ID tokens of firebase project *projectID*.
+func NewFirebaseAuthenticator(ctx context.Context, projectID string) (*FirebaseAuthenticator, error) {
+	config := &firebase.Config{ProjectID: projectID}
+	app, err := firebase.NewApp(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("NewFirebaseAuthenticator: error initializing app: %v", err)
+	}
+	client, err := app.Auth(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("NewFirebaseAuthenticator: generate firebase authentication admin failed: %v", err)
+	}
+	return &FirebaseAuthenticator{client: client}, nil
+}
+
+// Verify verifies firebase ID *token*.
+func (a *FirebaseAuthenticator) Verify(ctx context.Context, token string) (*Principal, error) {
+	verifiedToken, err := a.client.VerifyIDToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("error verifying ID token: %v", err)
+	}
+	return &Principal{
+		Subject: verifiedToken.UID,
+		Email:   verifiedEmail(verifiedToken.Claims),
+		Claims:  verifiedToken.Claims,
+		Expiry:  time.Unix(verifiedToken.Expires, 0),
+	}, nil
+}
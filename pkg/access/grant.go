@@ -0,0 +1,117 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/openconfig/catalog-server/pkg/db"
+)
+
+// queryAccessGrantsByMembers queries access grants stored in database by members, which is replaced in tests.
+var queryAccessGrantsByMembers = db.QueryAccessGrantsByMembers
+
+// NormalizeMember returns *member* of access grants in canonical form.
+// Members are verified emails or subjects of users, emails are case insensitive.
+func NormalizeMember(member string) string {
+	member = strings.TrimSpace(member)
+	if strings.Contains(member, "@") {
+		return strings.ToLower(member)
+	}
+	return member
+}
+
+// Members returns names that access grants of *principal* can be stored under,
+// which are its subject and its verified email.
+func Members(principal *Principal) []string {
+	var members []string
+	if principal.Subject != "" {
+		members = append(members, NormalizeMember(principal.Subject))
+	}
+	if principal.Email != "" {
+		members = append(members, NormalizeMember(principal.Email))
+	}
+	return members
+}
+
+// Member returns name that access grants and requests of *principal* are stored under,
+// which is its verified email if it has one, so that admins can manage access by emails.
+func Member(principal *Principal) string {
+	if principal.Email != "" {
+		return NormalizeMember(principal.Email)
+	}
+	return NormalizeMember(principal.Subject)
+}
+
+// GrantedRoles returns roles of *principal* granted in database keyed by organization's names.
+// API tokens are limited by their scopes, so they are not granted any roles.
+func GrantedRoles(principal *Principal) (map[string]Role, error) {
+	roles := map[string]Role{}
+	members := Members(principal)
+	if principal.Scope != nil || len(members) == 0 {
+		return roles, nil
+	}
+	grants, err := queryAccessGrantsByMembers(members)
+	if err != nil {
+		return nil, fmt.Errorf("GrantedRoles: %v", err)
+	}
+	for _, grant := range grants {
+		role, err := ParseRole(grant.Role)
+		if err != nil {
+			return nil, fmt.Errorf("GrantedRoles: invalid grant of %s in organization %s: %v", grant.Member, grant.OrgName, err)
+		}
+		if role > roles[grant.OrgName] {
+			roles[grant.OrgName] = role
+		}
+	}
+	return roles, nil
+}
+
+// NewAccessRequestID returns a random id of access request.
+func NewAccessRequestID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("NewAccessRequestID: generate random id failed: %v", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// CheckReview checks whether owner of request *ctx* can approve a request of *role* in organization *orgName*.
+// Reviewers are users (instead of API tokens) with role required by OpReview in *orgName*,
+// and they cannot approve roles higher than their own roles.
+func CheckReview(ctx context.Context, orgName string, role Role) error {
+	if err := CheckAccess(ctx, nil, orgName, OpReview); err != nil {
+		return fmt.Errorf("CheckReview: %v", err)
+	}
+	principal, err := Authenticate(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("CheckReview: %v", err)
+	}
+	if principal.Scope != nil {
+		return fmt.Errorf("CheckReview: access requests cannot be reviewed by API tokens")
+	}
+	roles, err := Roles(principal)
+	if err != nil {
+		return fmt.Errorf("CheckReview: %v", err)
+	}
+	if own := RoleIn(roles, orgName); own < role {
+		return fmt.Errorf("CheckReview: %s of organization %s cannot approve role %s", own, orgName, role)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/catalog-server/pkg/db"
+)
+
+func TestNormalizeMember(t *testing.T) {
+	tests := []struct {
+		desc   string
+		member string
+		want   string
+	}{
+		{
+			desc:   "email is case insensitive",
+			member: " Alice@Example.com ",
+			want:   "alice@example.com",
+		},
+		{
+			desc:   "subject is case sensitive",
+			member: "uid-Bob",
+			want:   "uid-Bob",
+		},
+		{
+			desc:   "whitespace only member is empty",
+			member: " \t\n",
+			want:   "",
+		},
+	}
+	for _, tc := range tests {
+		if got := NormalizeMember(tc.member); got != tc.want {
+			t.Errorf("%s: NormalizeMember(%q) = %q, want: %q", tc.desc, tc.member, got, tc.want)
+		}
+	}
+}
+
+func TestRolesWithGrants(t *testing.T) {
+	os.Setenv("DB_NAME", "catalog")
+	defer os.Unsetenv("DB_NAME")
+	grants := []db.AccessGrant{
+		{OrgName: "openconfig", Member: "alice@example.com", Role: "admin"},
+		{OrgName: "ietf", Member: "alice@example.com", Role: "reader"},
+		{OrgName: "vendor", Member: "uid-bob", Role: "publisher"},
+	}
+	queryAccessGrantsByMembers = func(members []string) ([]db.AccessGrant, error) {
+		var res []db.AccessGrant
+		for _, grant := range grants {
+			for _, member := range members {
+				if grant.Member == member {
+					res = append(res, grant)
+				}
+			}
+		}
+		return res, nil
+	}
+	defer func() { queryAccessGrantsByMembers = db.QueryAccessGrantsByMembers }()
+
+	tests := []struct {
+		desc      string
+		principal *Principal
+		want      map[string]Role
+	}{
+		{
+			desc:      "grants of verified email merged with claims",
+			principal: &Principal{Subject: "uid-alice", Email: "Alice@Example.com", Claims: map[string]interface{}{"catalog-allow": "ietf:publisher,openconfig:reader"}},
+			want:      map[string]Role{"openconfig": RoleAdmin, "ietf": RolePublisher},
+		},
+		{
+			desc:      "grants of subject without claims",
+			principal: &Principal{Subject: "uid-bob"},
+			want:      map[string]Role{"vendor": RolePublisher},
+		},
+		{
+			desc:      "API token is not granted roles",
+			principal: &Principal{Subject: "uid-bob", Scope: &Scope{}},
+			want:      map[string]Role{},
+		},
+		{
+			desc:      "principal without grants or claims",
+			principal: &Principal{Subject: "uid-carol"},
+			want:      map[string]Role{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := Roles(tc.principal)
+			if err != nil {
+				t.Fatalf("Roles failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Roles (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckReview(t *testing.T) {
+	os.Setenv("DB_NAME", "catalog")
+	defer os.Unsetenv("DB_NAME")
+	withClaim := func(claim string) context.Context {
+		return WithPrincipal(context.Background(), &Principal{Claims: map[string]interface{}{"catalog-allow": claim}})
+	}
+
+	tests := []struct {
+		desc    string
+		ctx     context.Context
+		role    Role
+		wantErr bool
+	}{
+		{
+			desc: "admin approves publisher",
+			ctx:  withClaim("openconfig:admin"),
+			role: RolePublisher,
+		},
+		{
+			desc:    "maintainer cannot review",
+			ctx:     withClaim("openconfig:maintainer"),
+			role:    RoleReader,
+			wantErr: true,
+		},
+		{
+			desc:    "legacy claim without role cannot review",
+			ctx:     withClaim("openconfig"),
+			role:    RoleReader,
+			wantErr: true,
+		},
+		{
+			desc:    "publisher cannot review",
+			ctx:     withClaim("openconfig:publisher"),
+			role:    RoleReader,
+			wantErr: true,
+		},
+		{
+			desc: "admin of all organizations approves admin",
+			ctx:  withClaim("*:admin"),
+			role: RoleAdmin,
+		},
+		{
+			desc:    "API token cannot review",
+			ctx:     WithPrincipal(context.Background(), &Principal{Scope: &Scope{OrgNames: []string{"openconfig"}, Operations: []Operation{OpReview}}}),
+			role:    RoleReader,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if err := CheckReview(tc.ctx, "openconfig", tc.role); (err != nil) != tc.wantErr {
+				t.Errorf("CheckReview error mismatch, got: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // Register SHA256 used by RS256 and ES256.
+	_ "crypto/sha512" // Register SHA384 and SHA512 used by RS384, RS512 and ES384.
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// discoveryPath is path of OpenID Connect discovery document under issuer's URL.
+	discoveryPath = `/.well-known/openid-configuration`
+	// clockSkew is allowed difference between clocks of issuer and catalog server when checking time of tokens.
+	clockSkew = time.Minute
+	// jwksMinRefresh is minimum interval between refreshes of JWKS when a token is signed by an unknown key.
+	jwksMinRefresh = time.Minute
+)
+
+// timeNow returns the current time, which is replaced in tests.
+var timeNow = time.Now
+
+// signingAlgorithm describes a supported JWS signing algorithm.
+type signingAlgorithm struct {
+	hash crypto.Hash
+	kty  string // Type of JWK verifying signature of this algorithm.
+}
+
+// signingAlgorithms are supported JWS signing algorithms.
+var signingAlgorithms = map[string]signingAlgorithm{
+	"RS256": {hash: crypto.SHA256, kty: "RSA"},
+	"RS384": {hash: crypto.SHA384, kty: "RSA"},
+	"RS512": {hash: crypto.SHA512, kty: "RSA"},
+	"ES256": {hash: crypto.SHA256, kty: "EC"},
+	"ES384": {hash: crypto.SHA384, kty: "EC"},
+}
+
+// OIDCAuthenticator verifies JWTs issued by an OpenID Connect provider.
+type OIDCAuthenticator struct {
+	issuer   string
+	audience string
+	jwksURL  string
+	client   *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey // Public keys in JWKS keyed by key id.
+	lastRefresh time.Time                   // Time when JWKS is refreshed last time.
+}
+
+// jwk is a JSON Web Key of RSA or EC public key.
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewOIDCAuthenticator returns an Authenticator verifying JWTs issued by *issuer* for *audience*.
+// Signatures are verified by public keys in JWKS at *jwksURL*,
+// if *jwksURL* is empty, it is discovered from OpenID Connect discovery document of *issuer*.
+func NewOIDCAuthenticator(ctx context.Context, issuer string, audience string, jwksURL string) (*OIDCAuthenticator, error) {
+	a := &OIDCAuthenticator{
+		issuer:   issuer,
+		audience: audience,
+		jwksURL:  jwksURL,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+	if a.jwksURL == "" {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := a.getJSON(ctx, strings.TrimSuffix(issuer, "/")+discoveryPath, &discovery); err != nil {
+			return nil, fmt.Errorf("NewOIDCAuthenticator: discover JWKS of issuer failed: %v", err)
+		}
+		if discovery.JWKSURI == "" {
+			return nil, fmt.Errorf("NewOIDCAuthenticator: discovery document of issuer does not contain jwks_uri")
+		}
+		a.jwksURL = discovery.JWKSURI
+	}
+	if err := a.refreshKeys(ctx); err != nil {
+		return nil, fmt.Errorf("NewOIDCAuthenticator: %v", err)
+	}
+	return a, nil
+}
+
+// Verify verifies signature, issuer, audience and validity period of JWT *token*.
+func (a *OIDCAuthenticator) Verify(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token is not a JWT")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("decode JWT header failed: %v", err)
+	}
+	alg, ok := signingAlgorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+	key, err := a.key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decode JWT signature failed: %v", err)
+	}
+	if err := verifySignature(alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("decode JWT claims failed: %v", err)
+	}
+	if iss, _ := claims["iss"].(string); iss != a.issuer {
+		return nil, fmt.Errorf("token is issued by %q instead of %q", iss, a.issuer)
+	}
+	if !hasAudience(claims["aud"], a.audience) {
+		return nil, fmt.Errorf("token is not issued for audience %q", a.audience)
+	}
+	now := timeNow()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("token does not have expiry")
+	}
+	expiry := time.Unix(int64(exp), 0)
+	if now.After(expiry.Add(clockSkew)) {
+		return nil, fmt.Errorf("token expired at %v", expiry)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("token is not valid yet")
+	}
+	subject, _ := claims["sub"].(string)
+	return &Principal{Subject: subject, Email: verifiedEmail(claims), Claims: claims, Expiry: expiry}, nil
+}
+
+// key returns public key of id *kid*. JWKS is refreshed if there is no such key,
+// in case that the issuer rotated its keys.
+func (a *OIDCAuthenticator) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	a.mu.Lock()
+	key, ok := a.keys[kid]
+	refresh := !ok && timeNow().Sub(a.lastRefresh) >= jwksMinRefresh
+	a.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if refresh {
+		if err := a.refreshKeys(ctx); err != nil {
+			return nil, err
+		}
+		a.mu.Lock()
+		key, ok = a.keys[kid]
+		a.mu.Unlock()
+		if ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("token is signed by unknown key %q", kid)
+}
+
+// refreshKeys fetches public keys in JWKS. Keys of unsupported types are ignored.
+func (a *OIDCAuthenticator) refreshKeys(ctx context.Context) error {
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := a.getJSON(ctx, a.jwksURL, &jwks); err != nil {
+		return fmt.Errorf("fetch JWKS failed: %v", err)
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, k := range jwks.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.keys = keys
+	a.lastRefresh = timeNow()
+	return nil
+}
+
+// getJSON gets JSON document at *url* and decodes it into *v*.
+func (a *OIDCAuthenticator) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// publicKey converts JWK *k* into RSA or ECDSA public key.
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// verifySignature verifies JWS *signature* of *signed* content using *key* and algorithm *alg*.
+func verifySignature(alg signingAlgorithm, key crypto.PublicKey, signed string, signature []byte) error {
+	h := alg.hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if alg.kty != "RSA" {
+			break
+		}
+		if err := rsa.VerifyPKCS1v15(key, alg.hash, digest, signature); err != nil {
+			return fmt.Errorf("invalid token signature: %v", err)
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if alg.kty != "EC" {
+			break
+		}
+		// ECDSA signature of JWS is concatenation of r and s of the same size.
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid token signature size %d", len(signature))
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("invalid token signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("signing algorithm does not match type of key")
+}
+
+// hasAudience checks whether "aud" claim *aud*, which is a string or a list of strings, contains *audience*.
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeSegment decodes base64url encoded JSON segment *seg* of JWT into *v*.
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// decodeBigInt decodes base64url encoded big-endian integer *s*.
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,213 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is role of a user in an organization. Each role has all permissions of roles lower than it.
+type Role int
+
+const (
+	RoleNone       Role = iota // No access to organization.
+	RoleReader                 // Read organization's private data.
+	RolePublisher              // Create and update modules and feature bundles of organization.
+	RoleMaintainer             // Delete modules and feature bundles of organization.
+	RoleAdmin                  // Administer organization, e.g., manage its settings and members.
+)
+
+// roleNames are names of roles used in claims.
+var roleNames = map[Role]string{
+	RoleNone:       "none",
+	RoleReader:     "reader",
+	RolePublisher:  "publisher",
+	RoleMaintainer: "maintainer",
+	RoleAdmin:      "admin",
+}
+
+const (
+	// roleDelimiter separates organization's name and role in an entry of claim, e.g., `openconfig:publisher`.
+	roleDelimiter = `:`
+	// legacyRole is role of entries of claim without roles, which gave blanket write access to organization.
+	legacyRole = RoleMaintainer
+	// AllOrgs is name of organization in claims granting a role in all organizations, e.g., `*:admin`.
+	AllOrgs = `*`
+)
+
+// Operation is an operation requiring a minimum role in an organization.
+type Operation string
+
+const (
+	OpRead       Operation = `read`       // Read organization's private data.
+	OpPublish    Operation = `publish`    // Create or update modules and feature bundles.
+	OpDelete     Operation = `delete`     // Delete modules and feature bundles.
+	OpReview     Operation = `review`     // Review requests of access to organization.
+	OpAdminister Operation = `administer` // Change settings or members of organization.
+)
+
+// requiredRoles are the minimum roles required by operations.
+// Reviewing access requests grants roles to others, so it requires admin,
+// and legacy claims, which have legacyRole, cannot approve requests.
+var requiredRoles = map[Operation]Role{
+	OpRead:       RoleReader,
+	OpPublish:    RolePublisher,
+	OpDelete:     RoleMaintainer,
+	OpReview:     RoleAdmin,
+	OpAdminister: RoleAdmin,
+}
+
+// String returns name of role *r*.
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("Role(%d)", int(r))
+}
+
+// ParseRole returns Role named *name*.
+func ParseRole(name string) (Role, error) {
+	for role, roleName := range roleNames {
+		if roleName == name {
+			return role, nil
+		}
+	}
+	return RoleNone, fmt.Errorf("unknown role %q", name)
+}
+
+// RequiredRole returns the minimum role required by operation *op*.
+func RequiredRole(op Operation) (Role, error) {
+	role, ok := requiredRoles[op]
+	if !ok {
+		return RoleNone, fmt.Errorf("unknown operation %q", op)
+	}
+	return role, nil
+}
+
+// ParseRoles parses claim string *claim* into roles keyed by organization's names.
+// *claim* is a list of entries separated by delimiter, each entry is `org:role`, or `org` which has legacyRole.
+// Entries are only split if their suffix after the last roleDelimiter is a known role name,
+// so legacy entries of organizations whose names contain roleDelimiter keep their whole names.
+// If an organization appears multiple times, the highest role is kept.
+func ParseRoles(claim string) (map[string]Role, error) {
+	roles := map[string]Role{}
+	for _, entry := range strings.Split(claim, delimiter) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		orgName, role := entry, legacyRole
+		if i := strings.LastIndex(entry, roleDelimiter); i >= 0 {
+			if r, err := ParseRole(entry[i+1:]); err == nil {
+				orgName, role = entry[:i], r
+			}
+		}
+		if orgName == "" {
+			return nil, fmt.Errorf("ParseRoles: invalid entry %q: name of organization should not be empty", entry)
+		}
+		if role > roles[orgName] {
+			roles[orgName] = role
+		}
+	}
+	return roles, nil
+}
+
+// ParseClaim parses claim *claim* of any supported shape into roles keyed by organization's names.
+// Supported shapes of claim, which are decoded from JSON claims of tokens, are:
+//   - string: legacy comma separated list of entries, see ParseRoles.
+//   - array of strings: names of organizations, each of them has legacyRole.
+//   - object: role names keyed by names of organizations, e.g., `{"openconfig": "publisher", "*": "reader"}`.
+//
+// Names of organizations in arrays and objects may contain delimiters.
+// An error describing the invalid part is returned if claim is in any other shape.
+func ParseClaim(claim interface{}) (map[string]Role, error) {
+	roles := map[string]Role{}
+	add := func(orgName string, role Role) error {
+		if strings.TrimSpace(orgName) == "" {
+			return fmt.Errorf("ParseClaim: name of organization should not be empty")
+		}
+		if role > roles[orgName] {
+			roles[orgName] = role
+		}
+		return nil
+	}
+	switch c := claim.(type) {
+	case string:
+		return ParseRoles(c)
+	case []string:
+		for _, orgName := range c {
+			if err := add(orgName, legacyRole); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, entry := range c {
+			orgName, ok := entry.(string)
+			if !ok {
+				return nil, fmt.Errorf("ParseClaim: entry %d of array is %T, want name of organization", i, entry)
+			}
+			if err := add(orgName, legacyRole); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]string:
+		for orgName, roleName := range c {
+			role, err := ParseRole(roleName)
+			if err != nil {
+				return nil, fmt.Errorf("ParseClaim: invalid role of organization %q: %v", orgName, err)
+			}
+			if err := add(orgName, role); err != nil {
+				return nil, err
+			}
+		}
+	case map[string]interface{}:
+		for orgName, value := range c {
+			roleName, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("ParseClaim: role of organization %q is %T, want name of role", orgName, value)
+			}
+			role, err := ParseRole(roleName)
+			if err != nil {
+				return nil, fmt.Errorf("ParseClaim: invalid role of organization %q: %v", orgName, err)
+			}
+			if err := add(orgName, role); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("ParseClaim: claim is %T, want string, array or object", claim)
+	}
+	return roles, nil
+}
+
+// FormatClaim formats *roles* as object claim of role names keyed by names of organizations, see ParseClaim.
+func FormatClaim(roles map[string]Role) map[string]interface{} {
+	claim := map[string]interface{}{}
+	for orgName, role := range roles {
+		claim[orgName] = role.String()
+	}
+	return claim
+}
+
+// RoleIn returns role in organization *orgName* given *roles*,
+// which is the higher one of role in *orgName* and role in AllOrgs.
+func RoleIn(roles map[string]Role, orgName string) Role {
+	role := roles[orgName]
+	if roles[AllOrgs] > role {
+		role = roles[AllOrgs]
+	}
+	return role
+}
@@ -0,0 +1,244 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRoles(t *testing.T) {
+	tests := []struct {
+		desc    string
+		claim   string
+		want    map[string]Role
+		wantErr bool
+	}{
+		{
+			desc:  "legacy claim without roles",
+			claim: "openconfig,ietf",
+			want:  map[string]Role{"openconfig": RoleMaintainer, "ietf": RoleMaintainer},
+		},
+		{
+			desc:  "claim with roles",
+			claim: "openconfig:publisher, ietf:reader,*:admin",
+			want:  map[string]Role{"openconfig": RolePublisher, "ietf": RoleReader, AllOrgs: RoleAdmin},
+		},
+		{
+			desc:  "highest role is kept",
+			claim: "openconfig:admin,openconfig:reader",
+			want:  map[string]Role{"openconfig": RoleAdmin},
+		},
+		{
+			desc:  "empty claim",
+			claim: "",
+			want:  map[string]Role{},
+		},
+		{
+			desc:  "legacy organization whose name contains delimiter of role",
+			claim: "vendor:team,vendor:lab:publisher",
+			want:  map[string]Role{"vendor:team": RoleMaintainer, "vendor:lab": RolePublisher},
+		},
+		{
+			desc:    "entry without organization",
+			claim:   ":admin",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			roles, err := ParseRoles(tc.claim)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseRoles error mismatch, got: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, roles); diff != "" {
+				t.Errorf("ParseRoles mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseClaim(t *testing.T) {
+	tests := []struct {
+		desc    string
+		claim   interface{}
+		want    map[string]Role
+		wantErr bool
+	}{
+		{
+			desc:  "legacy string claim",
+			claim: "openconfig:publisher,ietf",
+			want:  map[string]Role{"openconfig": RolePublisher, "ietf": RoleMaintainer},
+		},
+		{
+			desc:  "array of organizations",
+			claim: []interface{}{"openconfig", "vendor, inc."},
+			want:  map[string]Role{"openconfig": RoleMaintainer, "vendor, inc.": RoleMaintainer},
+		},
+		{
+			desc:  "object of roles",
+			claim: map[string]interface{}{"openconfig": "publisher", "vendor:x": "reader", AllOrgs: "admin"},
+			want:  map[string]Role{"openconfig": RolePublisher, "vendor:x": RoleReader, AllOrgs: RoleAdmin},
+		},
+		{
+			desc:    "array with non-string entry",
+			claim:   []interface{}{"openconfig", 1.0},
+			wantErr: true,
+		},
+		{
+			desc:    "object with non-string role",
+			claim:   map[string]interface{}{"openconfig": []interface{}{"publisher"}},
+			wantErr: true,
+		},
+		{
+			desc:    "object with unknown role",
+			claim:   map[string]interface{}{"openconfig": "owner"},
+			wantErr: true,
+		},
+		{
+			desc:    "empty organization name",
+			claim:   []interface{}{""},
+			wantErr: true,
+		},
+		{
+			desc:    "unsupported shape",
+			claim:   true,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			roles, err := ParseClaim(tc.claim)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseClaim error mismatch, got: %v, wantErr: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, roles); diff != "" {
+				t.Errorf("ParseClaim mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFormatClaim(t *testing.T) {
+	roles := map[string]Role{"openconfig": RolePublisher, AllOrgs: RoleReader}
+	claim := FormatClaim(roles)
+	if diff := cmp.Diff(map[string]interface{}{"openconfig": "publisher", AllOrgs: "reader"}, claim); diff != "" {
+		t.Errorf("FormatClaim mismatch (-want +got):\n%s", diff)
+	}
+	parsed, err := ParseClaim(claim)
+	if err != nil {
+		t.Fatalf("ParseClaim of formatted claim failed: %v", err)
+	}
+	if diff := cmp.Diff(roles, parsed); diff != "" {
+		t.Errorf("ParseClaim of formatted claim mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	os.Setenv("DB_NAME", "catalog")
+	defer os.Unsetenv("DB_NAME")
+	withClaim := func(claim interface{}) context.Context {
+		return WithPrincipal(context.Background(), &Principal{Claims: map[string]interface{}{"catalog-allow": claim}})
+	}
+
+	tests := []struct {
+		desc    string
+		claim   interface{}
+		orgName string
+		op      Operation
+		wantErr bool
+	}{
+		{
+			desc:    "publisher can publish",
+			claim:   "openconfig:publisher",
+			orgName: "openconfig",
+			op:      OpPublish,
+		},
+		{
+			desc:    "publisher cannot delete",
+			claim:   "openconfig:publisher",
+			orgName: "openconfig",
+			op:      OpDelete,
+			wantErr: true,
+		},
+		{
+			desc:    "object claim can publish",
+			claim:   map[string]interface{}{"openconfig": "publisher"},
+			orgName: "openconfig",
+			op:      OpPublish,
+		},
+		{
+			desc:    "array claim cannot administer",
+			claim:   []interface{}{"openconfig"},
+			orgName: "openconfig",
+			op:      OpAdminister,
+			wantErr: true,
+		},
+		{
+			desc:    "malformed claim",
+			claim:   map[string]interface{}{"openconfig": 1.0},
+			orgName: "openconfig",
+			op:      OpRead,
+			wantErr: true,
+		},
+		{
+			desc:    "legacy claim can delete",
+			claim:   "openconfig",
+			orgName: "openconfig",
+			op:      OpDelete,
+		},
+		{
+			desc:    "legacy claim cannot administer",
+			claim:   "openconfig",
+			orgName: "openconfig",
+			op:      OpAdminister,
+			wantErr: true,
+		},
+		{
+			desc:    "role in other organization",
+			claim:   "ietf:admin",
+			orgName: "openconfig",
+			op:      OpRead,
+			wantErr: true,
+		},
+		{
+			desc:    "role in all organizations",
+			claim:   "ietf:reader,*:admin",
+			orgName: "openconfig",
+			op:      OpAdminister,
+		},
+		{
+			desc:    "unknown operation",
+			claim:   "*:admin",
+			orgName: "openconfig",
+			op:      "own",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := CheckAccess(withClaim(tc.claim), nil, tc.orgName, tc.op)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckAccess error mismatch, got: %v, wantErr: %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import "context"
+
+// ReadFilter returns a function reporting whether owner of request *ctx* can read data of an organization.
+// Data of organizations in *privateOrgs* can only be read by principals with RoleReader or higher roles in them,
+// or API tokens scoped to OpRead in them. Data of other organizations can be read by anyone.
+// Anonymous requests and requests with invalid bearer tokens can only read public organizations.
+func ReadFilter(ctx context.Context, privateOrgs []string) func(orgName string) bool {
+	private := map[string]bool{}
+	for _, orgName := range privateOrgs {
+		private[orgName] = true
+	}
+	roles := map[string]Role{}
+	principal, err := PrincipalFromContext(ctx)
+	if err != nil {
+		principal = nil
+	}
+	if principal != nil && principal.Scope == nil {
+		if r, err := Roles(principal); err == nil {
+			roles = r
+		}
+	}
+	return func(orgName string) bool {
+		if !private[orgName] {
+			return true
+		}
+		// API tokens can read private organizations only if OpRead is in their scopes.
+		if principal != nil && principal.Scope != nil {
+			return principal.Scope.Allows(orgName, OpRead)
+		}
+		return RoleIn(roles, orgName) >= RoleReader
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package access
+
+import (
+	"context"
+	"os"
+	"testing"
+)
+
+func TestReadFilter(t *testing.T) {
+	os.Setenv("DB_NAME", "catalog")
+	defer os.Unsetenv("DB_NAME")
+	privateOrgs := []string{"staging", "vendor"}
+
+	tests := []struct {
+		desc     string
+		ctx      context.Context
+		readable map[string]bool
+	}{
+		{
+			desc:     "anonymous request",
+			ctx:      context.Background(),
+			readable: map[string]bool{"openconfig": true, "staging": false, "vendor": false},
+		},
+		{
+			desc:     "reader of private organization",
+			ctx:      WithPrincipal(context.Background(), &Principal{Claims: map[string]interface{}{"catalog-allow": "staging:reader"}}),
+			readable: map[string]bool{"openconfig": true, "staging": true, "vendor": false},
+		},
+		{
+			desc:     "role in all organizations",
+			ctx:      WithPrincipal(context.Background(), &Principal{Claims: map[string]interface{}{"catalog-allow": "*:reader"}}),
+			readable: map[string]bool{"openconfig": true, "staging": true, "vendor": true},
+		},
+		{
+			desc:     "principal without claims",
+			ctx:      WithPrincipal(context.Background(), &Principal{}),
+			readable: map[string]bool{"openconfig": true, "staging": false, "vendor": false},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			canRead := ReadFilter(tc.ctx, privateOrgs)
+			for orgName, want := range tc.readable {
+				if got := canRead(orgName); got != want {
+					t.Errorf("can read %s mismatch, got: %v, want: %v", orgName, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package archive bundles YANG sources of a module or feature bundle together with
+all modules it transitively depends on, so that a complete model set can be downloaded at once.
+  - archive.go resolves the dependency closure of a module or feature bundle.
+  - writer.go writes the resolved closure into tar.gz or zip archives.
+  - handler.go serves archives over HTTP.
+*/
+package archive
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openconfig/catalog-server/pkg/db"
+	"github.com/openconfig/catalog-server/pkg/versions"
+	"github.com/openconfig/catalog-server/pkg/yangsrc"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+)
+
+const (
+	KindModule        = `module`        // Kind of archive whose root is a Module.
+	KindFeatureBundle = `featureBundle` // Kind of archive whose root is a FeatureBundle.
+
+	// ManifestFile is name of generated catalog manifest file in archive.
+	ManifestFile = `manifest.json`
+)
+
+// Functions to query catalog and stored YANG sources, which are replaced in tests.
+var (
+	queryModules        = db.QueryModulesByKey
+	queryFeatureBundles = db.QueryFeatureBundlesByKey
+	lookupSource        = yangsrc.Lookup
+	queryPrivateOrgs    = func() ([]string, error) { return db.QueryOrgsByVisibility(db.VisibilityPrivate) }
+)
+
+// Manifest describes content of an archive, it is stored as ManifestFile in the archive.
+type Manifest struct {
+	Kind           string           `json:"kind"`                      // Kind of root of archive, KindModule or KindFeatureBundle.
+	OrgName        string           `json:"org-name"`                  // Name of organization holding root of archive.
+	Name           string           `json:"name"`                      // Name of root of archive.
+	Version        string           `json:"version"`                   // Version of root of archive.
+	FeatureBundles []ManifestEntry  `json:"feature-bundles,omitempty"` // Feature bundles included by root feature bundle, including itself.
+	Modules        []ManifestModule `json:"modules"`                   // Modules in dependency closure of root, sorted by name.
+	Unresolved     []string         `json:"unresolved,omitempty"`      // Dependencies that cannot be found in catalog.
+	MissingSources []string         `json:"missing-sources,omitempty"` // Modules and submodules whose YANG sources are not stored.
+}
+
+// ManifestEntry identifies a Module or FeatureBundle in catalog.
+type ManifestEntry struct {
+	OrgName string `json:"org-name"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ManifestModule describes a Module in archive.
+type ManifestModule struct {
+	ManifestEntry
+	Revision   string              `json:"revision,omitempty"`
+	File       string              `json:"file,omitempty"` // Name of YANG source file in archive, empty if source is missing.
+	MD5Hash    string              `json:"md5-hash,omitempty"`
+	Submodules []ManifestSubmodule `json:"submodules,omitempty"`
+}
+
+// ManifestSubmodule describes a submodule in archive.
+type ManifestSubmodule struct {
+	Name    string `json:"name"`
+	File    string `json:"file,omitempty"` // Name of YANG source file in archive, empty if source is missing.
+	MD5Hash string `json:"md5-hash,omitempty"`
+}
+
+// File is one YANG source file in archive.
+type File struct {
+	Name string
+	Data string
+}
+
+// Bundle is the resolved dependency closure of a Module or FeatureBundle.
+type Bundle struct {
+	Manifest Manifest
+	Files    []File // YANG source files sorted by name, manifest is not included.
+}
+
+// resolver keeps state while resolving the dependency closure.
+type resolver struct {
+	orgName        string                 // Organization of root, whose modules are preferred.
+	canRead        func(string) bool      // Whether data of an organization can be read by requester.
+	root           *db.Module             // Root module if root of archive is a Module.
+	bundle         *Bundle                // Resolved result.
+	modules        map[string]bool        // Names of modules already resolved or unresolved.
+	featureBundles map[ManifestEntry]bool // Feature bundles already resolved.
+	unresolved     map[string]bool        // Dependencies that cannot be found.
+	missingSources map[string]bool        // Modules and submodules whose sources are missing.
+	pending        []string               // Names of modules waiting to be resolved.
+	skipSources    bool                   // Whether YANG sources are not looked up, e.g., when only manifest is needed.
+}
+
+// ResolveModule resolves module *name* of *version* of organization *orgName* and
+// its transitive closure of required modules and their submodules.
+// If *version* is empty, the latest version of that module is used.
+// A nil Bundle is returned without error if there is no such module.
+// Required modules are looked up by name, preferring modules of *orgName* and then the latest version.
+// Only modules of organizations that *canRead* reports true are included.
+func ResolveModule(orgName string, name string, version string, canRead func(orgName string) bool) (*Bundle, error) {
+	if !canRead(orgName) {
+		return nil, nil
+	}
+	r := newResolver(orgName, canRead)
+	module, err := r.findModule(name, version, true)
+	if err != nil {
+		return nil, fmt.Errorf("ResolveModule: %v", err)
+	}
+	if module == nil {
+		return nil, nil
+	}
+	r.root = module
+	r.bundle.Manifest = Manifest{Kind: KindModule, OrgName: orgName, Name: name, Version: module.Version}
+	r.pending = append(r.pending, name)
+	if err := r.resolveModules(); err != nil {
+		return nil, fmt.Errorf("ResolveModule: %v", err)
+	}
+	return r.finish(), nil
+}
+
+// ResolveFeatureBundle resolves feature bundle *name* of *version* of organization *orgName*,
+// feature bundles it includes, and the transitive closure of modules referred by their paths.
+// If *version* is empty, the latest version of that feature bundle is used.
+// A nil Bundle is returned without error if there is no such feature bundle.
+// Only feature bundles and modules of organizations that *canRead* reports true are included.
+func ResolveFeatureBundle(orgName string, name string, version string, canRead func(orgName string) bool) (*Bundle, error) {
+	bundle, err := newResolver(orgName, canRead).resolveRootFeatureBundle(name, version)
+	if err != nil {
+		return nil, fmt.Errorf("ResolveFeatureBundle: %v", err)
+	}
+	return bundle, nil
+}
+
+// ResolveFeatureBundleManifest resolves feature bundle as ResolveFeatureBundle without YANG sources,
+// e.g., to compare modules of releases. A nil Manifest is returned without error if there is no such feature bundle.
+func ResolveFeatureBundleManifest(orgName string, name string, version string, canRead func(orgName string) bool) (*Manifest, error) {
+	r := newResolver(orgName, canRead)
+	r.skipSources = true
+	bundle, err := r.resolveRootFeatureBundle(name, version)
+	if err != nil || bundle == nil {
+		return nil, err
+	}
+	return &bundle.Manifest, nil
+}
+
+// resolveRootFeatureBundle resolves feature bundle *name* of *version* of organization of root as root of bundle.
+func (r *resolver) resolveRootFeatureBundle(name string, version string) (*Bundle, error) {
+	if !r.canRead(r.orgName) {
+		return nil, nil
+	}
+	entry, err := r.resolveFeatureBundle(r.orgName, name, version)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	manifest := &r.bundle.Manifest
+	manifest.Kind, manifest.OrgName, manifest.Name, manifest.Version = KindFeatureBundle, entry.OrgName, entry.Name, entry.Version
+	if err := r.resolveModules(); err != nil {
+		return nil, err
+	}
+	return r.finish(), nil
+}
+
+// newResolver returns a resolver preferring modules of organization *orgName*,
+// which only resolves data of organizations that *canRead* reports true.
+func newResolver(orgName string, canRead func(string) bool) *resolver {
+	return &resolver{
+		orgName:        orgName,
+		canRead:        canRead,
+		bundle:         &Bundle{},
+		modules:        map[string]bool{},
+		featureBundles: map[ManifestEntry]bool{},
+		unresolved:     map[string]bool{},
+		missingSources: map[string]bool{},
+	}
+}
+
+// resolveFeatureBundle resolves feature bundle *name* of *version* of organization *orgName*
+// and feature bundles it includes, and queues modules referred by their paths.
+// It returns the resolved feature bundle, or nil if it cannot be found.
+func (r *resolver) resolveFeatureBundle(orgName string, name string, version string) (*ManifestEntry, error) {
+	if !r.canRead(orgName) {
+		return nil, nil
+	}
+	dbFeatureBundle, err := findFeatureBundle(name, version, orgName)
+	if err != nil || dbFeatureBundle == nil {
+		return nil, err
+	}
+	entry := ManifestEntry{OrgName: dbFeatureBundle.OrgName, Name: dbFeatureBundle.Name, Version: dbFeatureBundle.Version}
+	if r.featureBundles[entry] {
+		return &entry, nil
+	}
+	r.featureBundles[entry] = true
+	r.bundle.Manifest.FeatureBundles = append(r.bundle.Manifest.FeatureBundles, entry)
+
+	featureBundle := &oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle{}
+	if err := oc.Unmarshal([]byte(dbFeatureBundle.Data), featureBundle); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal feature bundle %s: %v", name, err)
+	}
+	for _, path := range featureBundle.Path {
+		moduleName := pathModule(path)
+		if moduleName == "" {
+			r.unresolved["path "+path] = true
+			continue
+		}
+		r.pending = append(r.pending, moduleName)
+	}
+	if featureBundle.GetFeatureBundles() != nil {
+		for _, ref := range featureBundle.GetFeatureBundles().FeatureBundle {
+			refOrgName := ref.GetPublisher()
+			if refOrgName == "" {
+				refOrgName = entry.OrgName
+			}
+			refEntry, err := r.resolveFeatureBundle(refOrgName, ref.GetName(), ref.GetVersion())
+			if err != nil {
+				return nil, err
+			}
+			if refEntry == nil {
+				r.unresolved[fmt.Sprintf("feature bundle %s/%s@%s", refOrgName, ref.GetName(), ref.GetVersion())] = true
+			}
+		}
+	}
+	return &entry, nil
+}
+
+// resolveModules resolves all pending modules and modules they require,
+// and collects YANG sources of them and their submodules.
+func (r *resolver) resolveModules() error {
+	for len(r.pending) > 0 {
+		name := r.pending[0]
+		r.pending = r.pending[1:]
+		if r.modules[name] {
+			continue
+		}
+		r.modules[name] = true
+
+		// The root module is already found with its version, other modules are looked up by name only.
+		var err error
+		dbModule := r.root
+		if dbModule == nil || dbModule.Name != name {
+			if dbModule, err = r.findModule(name, "", false); err != nil {
+				return err
+			}
+		}
+		if dbModule == nil {
+			r.unresolved["module "+name] = true
+			continue
+		}
+		module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+		if err := oc.Unmarshal([]byte(dbModule.Data), module); err != nil {
+			return fmt.Errorf("cannot unmarshal module %s: %v", name, err)
+		}
+
+		manifestModule := ManifestModule{
+			ManifestEntry: ManifestEntry{OrgName: dbModule.OrgName, Name: dbModule.Name, Version: dbModule.Version},
+			Revision:      module.GetRevision(),
+			MD5Hash:       module.GetAccess().GetMd5Hash(),
+		}
+		if manifestModule.File, err = r.addSource(name, manifestModule.MD5Hash); err != nil {
+			return err
+		}
+		if module.GetSubmodules() != nil {
+			var submoduleNames []string
+			for submoduleName := range module.GetSubmodules().Submodule {
+				submoduleNames = append(submoduleNames, submoduleName)
+			}
+			sort.Strings(submoduleNames)
+			for _, submoduleName := range submoduleNames {
+				submodule := ManifestSubmodule{
+					Name:    submoduleName,
+					MD5Hash: module.GetSubmodules().GetSubmodule(submoduleName).GetAccess().GetMd5Hash(),
+				}
+				if submodule.File, err = r.addSource(submoduleName, submodule.MD5Hash); err != nil {
+					return err
+				}
+				manifestModule.Submodules = append(manifestModule.Submodules, submodule)
+			}
+		}
+		r.bundle.Manifest.Modules = append(r.bundle.Manifest.Modules, manifestModule)
+		r.pending = append(r.pending, module.GetDependencies().GetRequiredModule()...)
+	}
+	return nil
+}
+
+// addSource adds stored YANG source of module or submodule *name* with md5 hash *md5Hash* into bundle.
+// It returns name of the added file, or "" if the source is not stored or sources are skipped.
+// Error is returned if *name* cannot be a file name in archive, since names are read from catalog
+// and archives are extracted by users.
+func (r *resolver) addSource(name string, md5Hash string) (string, error) {
+	if r.skipSources {
+		return "", nil
+	}
+	if err := checkFileName(name); err != nil {
+		return "", err
+	}
+	if md5Hash == "" {
+		r.missingSources[name] = true
+		return "", nil
+	}
+	source, err := lookupSource(md5Hash)
+	if err != nil {
+		return "", err
+	}
+	if source == nil {
+		r.missingSources[name] = true
+		return "", nil
+	}
+	file := name + ".yang"
+	r.bundle.Files = append(r.bundle.Files, File{Name: file, Data: source.Data})
+	return file, nil
+}
+
+// checkFileName checks that YANG source of module or submodule *name* can be written as a file
+// at top level of archive, i.e., *name* is not empty and does not contain path separators, `..` or NUL.
+func checkFileName(name string) error {
+	if name == "" || strings.ContainsAny(name, "/\\\x00") || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid name %q of module or submodule, which cannot be a file name in archive", name)
+	}
+	return nil
+}
+
+// finish sorts resolved result and returns it.
+func (r *resolver) finish() *Bundle {
+	manifest := &r.bundle.Manifest
+	sort.Slice(manifest.Modules, func(i, j int) bool {
+		return manifest.Modules[i].Name < manifest.Modules[j].Name
+	})
+	sort.Slice(r.bundle.Files, func(i, j int) bool {
+		return r.bundle.Files[i].Name < r.bundle.Files[j].Name
+	})
+	manifest.Unresolved = sortedKeys(r.unresolved)
+	manifest.MissingSources = sortedKeys(r.missingSources)
+	return r.bundle
+}
+
+// findModule queries module *name* of *version* from catalog.
+// If *version* is empty, the latest version is returned.
+// Modules of organization of root are preferred, and if *orgOnly* is true, only they are returned.
+// Modules of organizations that cannot be read are skipped.
+// A nil pointer is returned without error if there is no such module.
+func (r *resolver) findModule(name string, version string, orgOnly bool) (*db.Module, error) {
+	orgName := r.orgName
+	var versionPtr *string
+	if version != "" {
+		versionPtr = &version
+	}
+	modules, err := queryModules(&name, versionPtr)
+	if err != nil {
+		return nil, err
+	}
+	var best *db.Module
+	for i := 0; i < len(modules); i++ {
+		m := &modules[i]
+		if (orgOnly && m.OrgName != orgName) || !r.canRead(m.OrgName) {
+			continue
+		}
+		switch {
+		case best == nil:
+			best = m
+		case (m.OrgName == orgName) != (best.OrgName == orgName):
+			if m.OrgName == orgName {
+				best = m
+			}
+		case versions.Compare(m.Version, best.Version) > 0:
+			best = m
+		}
+	}
+	return best, nil
+}
+
+// findFeatureBundle queries feature bundle *name* of *version* of organization *orgName* from catalog.
+// If *version* is empty, the latest version is returned.
+// A nil pointer is returned without error if there is no such feature bundle.
+func findFeatureBundle(name string, version string, orgName string) (*db.FeatureBundle, error) {
+	var versionPtr *string
+	if version != "" {
+		versionPtr = &version
+	}
+	featureBundles, err := queryFeatureBundles(&name, versionPtr)
+	if err != nil {
+		return nil, err
+	}
+	var best *db.FeatureBundle
+	for i := 0; i < len(featureBundles); i++ {
+		fb := &featureBundles[i]
+		if fb.OrgName != orgName {
+			continue
+		}
+		if best == nil || versions.Compare(fb.Version, best.Version) > 0 {
+			best = fb
+		}
+	}
+	return best, nil
+}
+
+// pathModule returns name of module defining the first element of module-qualified *path*,
+// e.g., `openconfig-interfaces` for `/openconfig-interfaces:interfaces/interface`.
+// It returns "" if the first element is not qualified with module name.
+func pathModule(path string) string {
+	elem := strings.TrimPrefix(path, "/")
+	if i := strings.Index(elem, "/"); i >= 0 {
+		elem = elem[:i]
+	}
+	i := strings.Index(elem, ":")
+	if i <= 0 {
+		return ""
+	}
+	return elem[:i]
+}
+
+// sortedKeys returns sorted keys of *set*.
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
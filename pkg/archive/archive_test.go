@@ -0,0 +1,391 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/catalog-server/pkg/db"
+	"github.com/openconfig/catalog-server/pkg/yangsrc"
+)
+
+// Modules and feature bundles in fake catalog used by tests.
+var (
+	testModules = []db.Module{
+		{OrgName: "org", Name: "base", Version: "1.0.0", Data: `{
+			"openconfig-module-catalog:name": "base", "openconfig-module-catalog:version": "1.0.0",
+			"openconfig-module-catalog:access": {"md5-hash": "md5-base-1"},
+			"openconfig-module-catalog:dependencies": {"required-module": ["types", "ext"]},
+			"openconfig-module-catalog:submodules": {"submodule": [{"name": "base-sub", "access": {"md5-hash": "md5-base-sub"}}]}
+		}`},
+		{OrgName: "org", Name: "base", Version: "1.10.0", Data: `{
+			"openconfig-module-catalog:name": "base", "openconfig-module-catalog:version": "1.10.0",
+			"openconfig-module-catalog:access": {"md5-hash": "md5-base-10"}
+		}`},
+		{OrgName: "org", Name: "types", Version: "0.1.0", Data: `{
+			"openconfig-module-catalog:name": "types", "openconfig-module-catalog:version": "0.1.0",
+			"openconfig-module-catalog:access": {"md5-hash": "md5-types-org"},
+			"openconfig-module-catalog:dependencies": {"required-module": ["base"]}
+		}`},
+		{OrgName: "other", Name: "types", Version: "9.0.0", Data: `{
+			"openconfig-module-catalog:name": "types", "openconfig-module-catalog:version": "9.0.0",
+			"openconfig-module-catalog:access": {"md5-hash": "md5-types-other"}
+		}`},
+		{OrgName: "private", Name: "secret", Version: "1.0.0", Data: `{
+			"openconfig-module-catalog:name": "secret", "openconfig-module-catalog:version": "1.0.0"
+		}`},
+		{OrgName: "other", Name: "ext", Version: "1.0.0", Data: `{
+			"openconfig-module-catalog:name": "ext", "openconfig-module-catalog:version": "1.0.0",
+			"openconfig-module-catalog:dependencies": {"required-module": ["missing"]}
+		}`},
+		{OrgName: "evil", Name: "../../etc/x", Version: "1.0.0", Data: `{
+			"openconfig-module-catalog:name": "../../etc/x", "openconfig-module-catalog:version": "1.0.0",
+			"openconfig-module-catalog:access": {"md5-hash": "md5-evil"}
+		}`},
+		{OrgName: "evil", Name: "evil-sub", Version: "1.0.0", Data: `{
+			"openconfig-module-catalog:name": "evil-sub", "openconfig-module-catalog:version": "1.0.0",
+			"openconfig-module-catalog:submodules": {"submodule": [{"name": "..\\x", "access": {"md5-hash": "md5-evil"}}]}
+		}`},
+	}
+	testFeatureBundles = []db.FeatureBundle{
+		{OrgName: "org", Name: "fb", Version: "1.0.0", Data: `{
+			"openconfig-module-catalog:name": "fb", "openconfig-module-catalog:version": "1.0.0",
+			"openconfig-module-catalog:path": ["/types:node/leaf", "/no-prefix"],
+			"openconfig-module-catalog:feature-bundles": {"feature-bundle": [
+				{"name": "nested", "version": "1.0.0", "publisher": "other"},
+				{"name": "absent", "version": "1.0.0"}
+			]}
+		}`},
+		{OrgName: "other", Name: "nested", Version: "1.0.0", Data: `{
+			"openconfig-module-catalog:name": "nested", "openconfig-module-catalog:version": "1.0.0",
+			"openconfig-module-catalog:path": ["/ext:top"]
+		}`},
+	}
+	testSources = map[string]string{
+		"md5-base-1":    "module base {}",
+		"md5-base-10":   "module base { revision 2021-01-01; }",
+		"md5-base-sub":  "submodule base-sub {}",
+		"md5-types-org": "module types {}",
+		"md5-evil":      "module evil {}",
+	}
+)
+
+// fakeCatalog replaces functions querying catalog with ones reading test data.
+func fakeCatalog(t *testing.T) {
+	queryModules = func(name *string, version *string) ([]db.Module, error) {
+		var res []db.Module
+		for _, m := range testModules {
+			if (name == nil || m.Name == *name) && (version == nil || m.Version == *version) {
+				res = append(res, m)
+			}
+		}
+		return res, nil
+	}
+	queryFeatureBundles = func(name *string, version *string) ([]db.FeatureBundle, error) {
+		var res []db.FeatureBundle
+		for _, fb := range testFeatureBundles {
+			if (name == nil || fb.Name == *name) && (version == nil || fb.Version == *version) {
+				res = append(res, fb)
+			}
+		}
+		return res, nil
+	}
+	privateOrgs := queryPrivateOrgs
+	queryPrivateOrgs = func() ([]string, error) { return []string{"private"}, nil }
+	lookupSource = func(hash string) (*db.YangSource, error) {
+		data, ok := testSources[hash]
+		if !ok {
+			return nil, nil
+		}
+		return &db.YangSource{MD5: hash, Data: data}, nil
+	}
+	t.Cleanup(func() {
+		queryModules = db.QueryModulesByKey
+		queryFeatureBundles = db.QueryFeatureBundlesByKey
+		lookupSource = yangsrc.Lookup
+		queryPrivateOrgs = privateOrgs
+	})
+}
+
+func TestResolveModule(t *testing.T) {
+	fakeCatalog(t)
+	tests := []struct {
+		desc    string
+		orgName string
+		name    string
+		version string
+		want    *Bundle
+	}{
+		{
+			desc:    "transitive closure of required modules",
+			orgName: "org",
+			name:    "base",
+			version: "1.0.0",
+			want: &Bundle{
+				Manifest: Manifest{
+					Kind:    KindModule,
+					OrgName: "org",
+					Name:    "base",
+					Version: "1.0.0",
+					Modules: []ManifestModule{
+						{
+							ManifestEntry: ManifestEntry{OrgName: "org", Name: "base", Version: "1.0.0"},
+							File:          "base.yang",
+							MD5Hash:       "md5-base-1",
+							Submodules:    []ManifestSubmodule{{Name: "base-sub", File: "base-sub.yang", MD5Hash: "md5-base-sub"}},
+						},
+						{ManifestEntry: ManifestEntry{OrgName: "other", Name: "ext", Version: "1.0.0"}},
+						{ManifestEntry: ManifestEntry{OrgName: "org", Name: "types", Version: "0.1.0"}, File: "types.yang", MD5Hash: "md5-types-org"},
+					},
+					Unresolved:     []string{"module missing"},
+					MissingSources: []string{"ext"},
+				},
+				Files: []File{
+					{Name: "base-sub.yang", Data: "submodule base-sub {}"},
+					{Name: "base.yang", Data: "module base {}"},
+					{Name: "types.yang", Data: "module types {}"},
+				},
+			},
+		},
+		{
+			desc:    "latest version without version",
+			orgName: "org",
+			name:    "base",
+			want: &Bundle{
+				Manifest: Manifest{
+					Kind:    KindModule,
+					OrgName: "org",
+					Name:    "base",
+					Version: "1.10.0",
+					Modules: []ManifestModule{
+						{ManifestEntry: ManifestEntry{OrgName: "org", Name: "base", Version: "1.10.0"}, File: "base.yang", MD5Hash: "md5-base-10"},
+					},
+				},
+				Files: []File{{Name: "base.yang", Data: "module base { revision 2021-01-01; }"}},
+			},
+		},
+		{
+			desc:    "module of other organization",
+			orgName: "org",
+			name:    "ext",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := ResolveModule(tc.orgName, tc.name, tc.version, func(string) bool { return true })
+			if err != nil {
+				t.Fatalf("ResolveModule failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ResolveModule mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveModuleInvalidFileName(t *testing.T) {
+	fakeCatalog(t)
+	for _, name := range []string{"../../etc/x", "evil-sub"} {
+		if bundle, err := ResolveModule("evil", name, "1.0.0", func(string) bool { return true }); err == nil {
+			t.Errorf("ResolveModule of %s should fail for name of module or submodule with path traversal, got files: %v", name, bundle.Files)
+		}
+	}
+}
+
+func TestResolveFeatureBundle(t *testing.T) {
+	fakeCatalog(t)
+	got, err := ResolveFeatureBundle("org", "fb", "", func(string) bool { return true })
+	if err != nil {
+		t.Fatalf("ResolveFeatureBundle failed: %v", err)
+	}
+	want := Manifest{
+		Kind:    KindFeatureBundle,
+		OrgName: "org",
+		Name:    "fb",
+		Version: "1.0.0",
+		FeatureBundles: []ManifestEntry{
+			{OrgName: "org", Name: "fb", Version: "1.0.0"},
+			{OrgName: "other", Name: "nested", Version: "1.0.0"},
+		},
+		Modules: []ManifestModule{
+			{
+				ManifestEntry: ManifestEntry{OrgName: "org", Name: "base", Version: "1.10.0"},
+				File:          "base.yang",
+				MD5Hash:       "md5-base-10",
+			},
+			{ManifestEntry: ManifestEntry{OrgName: "other", Name: "ext", Version: "1.0.0"}},
+			{ManifestEntry: ManifestEntry{OrgName: "org", Name: "types", Version: "0.1.0"}, File: "types.yang", MD5Hash: "md5-types-org"},
+		},
+		Unresolved:     []string{"feature bundle org/absent@1.0.0", "module missing", "path /no-prefix"},
+		MissingSources: []string{"ext"},
+	}
+	if diff := cmp.Diff(want, got.Manifest); diff != "" {
+		t.Errorf("ResolveFeatureBundle manifest mismatch (-want +got):\n%s", diff)
+	}
+
+	// Manifest without sources has the same entries, but no files.
+	manifest, err := ResolveFeatureBundleManifest("org", "fb", "", func(string) bool { return true })
+	if err != nil {
+		t.Fatalf("ResolveFeatureBundleManifest failed: %v", err)
+	}
+	want.Modules[0].File, want.Modules[2].File = "", ""
+	want.MissingSources = nil
+	if diff := cmp.Diff(&want, manifest); diff != "" {
+		t.Errorf("ResolveFeatureBundleManifest mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandleDownload(t *testing.T) {
+	fakeCatalog(t)
+	wantFiles := []string{"base-sub.yang", "base.yang", ManifestFile, "types.yang"}
+	tests := []struct {
+		desc       string
+		url        string
+		wantStatus int
+		wantFiles  []string
+		read       func(data []byte) ([]string, error)
+	}{
+		{
+			desc:       "tar.gz archive",
+			url:        DownloadPath + "?orgName=org&name=base&version=1.0.0",
+			wantStatus: http.StatusOK,
+			wantFiles:  wantFiles,
+			read:       readTarGz,
+		},
+		{
+			desc:       "zip archive",
+			url:        DownloadPath + "?orgName=org&name=base&version=1.0.0&format=zip",
+			wantStatus: http.StatusOK,
+			wantFiles:  wantFiles,
+			read:       readZip,
+		},
+		{
+			desc:       "missing name",
+			url:        DownloadPath + "?orgName=org",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "unknown format",
+			url:        DownloadPath + "?orgName=org&name=base&format=rar",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			desc:       "module of private organization",
+			url:        DownloadPath + "?orgName=private&name=secret",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "unknown feature bundle",
+			url:        DownloadPath + "?orgName=org&name=base&kind=featureBundle",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			HandleDownload(rec, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status mismatch, got: %d, want: %d, body: %s", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.read == nil {
+				return
+			}
+			files, err := tc.read(rec.Body.Bytes())
+			if err != nil {
+				t.Fatalf("cannot read archive: %v", err)
+			}
+			sort.Strings(files)
+			if diff := cmp.Diff(tc.wantFiles, files); diff != "" {
+				t.Errorf("files in archive mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// readTarGz returns names of files in gzip compressed tar archive *data*.
+func readTarGz(data []byte) ([]string, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(gr)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, header.Name)
+	}
+}
+
+// readZip returns names of files in zip archive *data*.
+func readZip(data []byte) ([]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	return names, nil
+}
+
+func TestWriteReproducible(t *testing.T) {
+	bundle := &Bundle{
+		Manifest: Manifest{Kind: KindModule, OrgName: "org", Name: "base", Version: "1.0.0"},
+		Files:    []File{{Name: "base.yang", Data: "module base {}"}},
+	}
+	tests := []struct {
+		desc  string
+		write func(w io.Writer, bundle *Bundle) error
+	}{
+		{desc: "tar.gz archive", write: WriteTarGz},
+		{desc: "zip archive", write: WriteZip},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			var first, second bytes.Buffer
+			if err := tc.write(&first, bundle); err != nil {
+				t.Fatalf("write archive failed: %v", err)
+			}
+			// Both tar and zip archives store modification time in seconds.
+			time.Sleep(1100 * time.Millisecond)
+			if err := tc.write(&second, bundle); err != nil {
+				t.Fatalf("write archive failed: %v", err)
+			}
+			if !bytes.Equal(first.Bytes(), second.Bytes()) {
+				t.Errorf("archives of the same bundle written at different times differ")
+			}
+		})
+	}
+}
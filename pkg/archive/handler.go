@@ -0,0 +1,91 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/openconfig/catalog-server/pkg/access"
+)
+
+// DownloadPath is path of HTTP endpoint to download archives.
+const DownloadPath = `/archive`
+
+// HandleDownload is handler function of HTTP endpoint at DownloadPath, which should be wrapped by access.Middleware
+// so that data of private organizations can be downloaded by users with read access.
+// Root of archive is given by URL query parameters:
+//   - kind: KindModule (default) or KindFeatureBundle.
+//   - orgName, name: key of root, both are required.
+//   - version: version of root, the latest version is used if it is not given.
+//   - format: FormatTarGz (default) or FormatZip.
+//
+// e.g., `/archive?orgName=openconfig&name=openconfig-interfaces&version=2.4.3&format=zip`.
+func HandleDownload(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	kind, orgName, name, version, format := query.Get("kind"), query.Get("orgName"), query.Get("name"), query.Get("version"), query.Get("format")
+	if orgName == "" || name == "" {
+		http.Error(w, "orgName and name are required", http.StatusBadRequest)
+		return
+	}
+	if format == "" {
+		format = FormatTarGz
+	}
+	if format != FormatTarGz && format != FormatZip {
+		http.Error(w, fmt.Sprintf("format should be %s or %s", FormatTarGz, FormatZip), http.StatusBadRequest)
+		return
+	}
+
+	// Data of private organizations are only included for users with read access.
+	privateOrgs, err := queryPrivateOrgs()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	canRead := access.ReadFilter(r.Context(), privateOrgs)
+
+	var bundle *Bundle
+	switch kind {
+	case "", KindModule:
+		bundle, err = ResolveModule(orgName, name, version, canRead)
+	case KindFeatureBundle:
+		bundle, err = ResolveFeatureBundle(orgName, name, version, canRead)
+	default:
+		http.Error(w, fmt.Sprintf("kind should be %s or %s", KindModule, KindFeatureBundle), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if bundle == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", bundle.Manifest.Name, bundle.Manifest.Version, format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	// Archive is streamed into response, so errors after the header is written are reported by aborting the connection.
+	if format == FormatZip {
+		w.Header().Set("Content-Type", "application/zip")
+		err = WriteZip(w, bundle)
+	} else {
+		w.Header().Set("Content-Type", "application/gzip")
+		err = WriteTarGz(w, bundle)
+	}
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	FormatTarGz = `tar.gz` // Format of gzip compressed tar archive.
+	FormatZip   = `zip`    // Format of zip archive.
+)
+
+// ModTime is modification time of all files in archives, which is fixed so that
+// archives of the same bundle are identical byte by byte. It is the earliest time zip format can represent.
+var ModTime = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// files returns all files to be written into archive of *bundle*,
+// which are ManifestFile followed by YANG sources.
+func (bundle *Bundle) files() ([]File, error) {
+	manifest, err := json.MarshalIndent(bundle.Manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal manifest failed: %v", err)
+	}
+	return append([]File{{Name: ManifestFile, Data: string(manifest)}}, bundle.Files...), nil
+}
+
+// WriteTarGz writes *bundle* into *w* as a gzip compressed tar archive.
+func WriteTarGz(w io.Writer, bundle *Bundle) error {
+	files, err := bundle.files()
+	if err != nil {
+		return fmt.Errorf("WriteTarGz: %v", err)
+	}
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    file.Name,
+			Mode:    0644,
+			Size:    int64(len(file.Data)),
+			ModTime: ModTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("WriteTarGz: write header of %s failed: %v", file.Name, err)
+		}
+		if _, err := io.WriteString(tw, file.Data); err != nil {
+			return fmt.Errorf("WriteTarGz: write %s failed: %v", file.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("WriteTarGz: close tar writer failed: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("WriteTarGz: close gzip writer failed: %v", err)
+	}
+	return nil
+}
+
+// WriteZip writes *bundle* into *w* as a zip archive.
+func WriteZip(w io.Writer, bundle *Bundle) error {
+	files, err := bundle.files()
+	if err != nil {
+		return fmt.Errorf("WriteZip: %v", err)
+	}
+	zw := zip.NewWriter(w)
+	for _, file := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     file.Name,
+			Method:   zip.Deflate,
+			Modified: ModTime,
+		})
+		if err != nil {
+			return fmt.Errorf("WriteZip: create %s failed: %v", file.Name, err)
+		}
+		if _, err := io.WriteString(fw, file.Data); err != nil {
+			return fmt.Errorf("WriteZip: write %s failed: %v", file.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("WriteZip: close zip writer failed: %v", err)
+	}
+	return nil
+}
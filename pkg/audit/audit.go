@@ -0,0 +1,170 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit records authenticated operations of catalog server in audit log stored in database.
+// Every mutation is recorded with its principal, organization, target, outcome and error reason
+// by Middleware, which is installed as field middleware of GraphQL server.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/golang/glog"
+	"github.com/openconfig/catalog-server/pkg/access"
+	"github.com/openconfig/catalog-server/pkg/db"
+)
+
+// auditedObject is GraphQL object whose fields are audited.
+const auditedObject = `Mutation`
+
+// targetArgs are names of arguments, or fields of input arguments, identifying target of operation.
+var targetArgs = []string{"Name", "Version", "ID", "Member", "Role", "Visibility", "OrgNames", "Operations"}
+
+// insertAuditEntry stores entries of audit log, which is replaced in tests.
+var insertAuditEntry = db.InsertAuditEntry
+
+// timeNow returns current time, which is replaced in tests.
+var timeNow = time.Now
+
+// contextKey is type of keys of values put on context of mutations by this package.
+type contextKey int
+
+// orgNamesKey is key of *resolvedOrgNames on context of mutations.
+const orgNamesKey contextKey = 0
+
+// resolvedOrgNames are organizations of a mutation set by its resolver, see SetOrgNames.
+type resolvedOrgNames struct {
+	orgNames []string
+}
+
+// Middleware is GraphQL field middleware recording every mutation in audit log after it is resolved.
+// Failure of recording is logged, and does not change result of the mutation.
+func Middleware(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Object != auditedObject {
+		return next(ctx)
+	}
+	ctx = context.WithValue(ctx, orgNamesKey, &resolvedOrgNames{})
+	res, err := next(ctx)
+	if recordErr := Record(ctx, fc.Field.Name, fc.Args, err); recordErr != nil {
+		glog.Errorf("audit: %v", recordErr)
+	}
+	return res, err
+}
+
+// SetOrgNames records that mutation of *ctx* operates in organizations *orgNames*, which are resolved by its resolver,
+// e.g., from the access request or API token identified by its arguments.
+// It is a no-op if mutation is not audited by Middleware.
+func SetOrgNames(ctx context.Context, orgNames ...string) {
+	if resolved, ok := ctx.Value(orgNamesKey).(*resolvedOrgNames); ok {
+		resolved.orgNames = orgNames
+	}
+}
+
+// Record records in audit log operation *operation* of request *ctx* with arguments *args*, which resulted in *opErr*.
+// Principal is the owner of bearer token of request, or of deprecated Token argument.
+// Organizations set by SetOrgNames are preferred to those in arguments,
+// and one entry is recorded for each of them, so that admins of every organization see the operation.
+func Record(ctx context.Context, operation string, args map[string]interface{}, opErr error) error {
+	entry := db.AuditEntry{
+		Time:      timeNow().UTC(),
+		Operation: operation,
+		Target:    target(args),
+		Outcome:   db.AuditSuccess,
+	}
+	if opErr != nil {
+		entry.Outcome = db.AuditFailure
+		entry.Error = opErr.Error()
+	}
+	token, _ := args["Token"].(*string)
+	if principal, err := access.Authenticate(ctx, token); err == nil {
+		entry.Principal = access.Member(principal)
+		entry.Subject = principal.Subject
+	}
+	orgNames := []string{orgName(args)}
+	if resolved, ok := ctx.Value(orgNamesKey).(*resolvedOrgNames); ok && len(resolved.orgNames) > 0 {
+		orgNames = resolved.orgNames
+	}
+	for _, name := range orgNames {
+		entry.OrgName = name
+		if err := insertAuditEntry(entry); err != nil {
+			return fmt.Errorf("Record: %v", err)
+		}
+	}
+	return nil
+}
+
+// orgName returns organization in argument OrgName, or in field OrgName of argument Input.
+func orgName(args map[string]interface{}) string {
+	if name, ok := args["OrgName"].(string); ok {
+		return name
+	}
+	if v, ok := field(args["Input"], "OrgName"); ok {
+		if name, ok := v.(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// target formats arguments in targetArgs, or fields of argument Input in targetArgs, e.g., `Name=foo, Version=1.0.0`.
+// Other arguments are not recorded since they can be large, e.g., data of modules, or secret, e.g., tokens.
+func target(args map[string]interface{}) string {
+	var parts []string
+	for _, name := range targetArgs {
+		v, ok := args[name]
+		if !ok {
+			v, ok = field(args["Input"], name)
+		}
+		if !ok || v == nil {
+			continue
+		}
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				continue
+			}
+			rv = rv.Elem()
+		}
+		parts = append(parts, fmt.Sprintf("%s=%v", name, rv.Interface()))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// field returns field *name* of struct, or pointer to struct, *input*.
+func field(input interface{}, name string) (interface{}, bool) {
+	if input == nil {
+		return nil, false
+	}
+	v := reflect.ValueOf(input)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	f := v.FieldByName(name)
+	if !f.IsValid() {
+		return nil, false
+	}
+	return f.Interface(), true
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/catalog-server/pkg/access"
+	"github.com/openconfig/catalog-server/pkg/db"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// moduleKey is an input argument similar to model.ModuleKey.
+type moduleKey struct {
+	OrgName string
+	Name    string
+	Version string
+}
+
+// newAPIToken is an input argument similar to model.NewAPIToken.
+type newAPIToken struct {
+	OrgNames   []string
+	Operations []string
+}
+
+func TestMiddleware(t *testing.T) {
+	now := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	var entries []db.AuditEntry
+	insertAuditEntry = func(entry db.AuditEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}
+	defer func() { insertAuditEntry = db.InsertAuditEntry }()
+
+	user := access.WithPrincipal(context.Background(), &access.Principal{Subject: "uid", Email: "alice@example.com"})
+	tests := []struct {
+		desc      string
+		ctx       context.Context
+		object    string
+		operation string
+		args      map[string]interface{}
+		orgNames  []string // Organizations set by resolver.
+		err       error
+		want      []db.AuditEntry
+	}{
+		{
+			desc:      "successful mutation with input",
+			ctx:       user,
+			object:    "Mutation",
+			operation: "DeleteModule",
+			args:      map[string]interface{}{"Input": moduleKey{OrgName: "openconfig", Name: "openconfig-interfaces", Version: "1.0.0"}},
+			want: []db.AuditEntry{{
+				Time: now, Principal: "alice@example.com", Subject: "uid", OrgName: "openconfig", Operation: "DeleteModule",
+				Target: "Name=openconfig-interfaces, Version=1.0.0", Outcome: db.AuditSuccess,
+			}},
+		},
+		{
+			desc:      "failed mutation of anonymous request",
+			ctx:       context.Background(),
+			object:    "Mutation",
+			operation: "GrantAccess",
+			args:      map[string]interface{}{"OrgName": "openconfig", "Member": "bob@example.com", "Role": "admin"},
+			err:       errors.New("no token"),
+			want: []db.AuditEntry{{
+				Time: now, OrgName: "openconfig", Operation: "GrantAccess",
+				Target: "Member=bob@example.com, Role=admin", Outcome: db.AuditFailure, Error: "no token",
+			}},
+		},
+		{
+			desc:      "organization of access request resolved by ApproveOrgAccess",
+			ctx:       user,
+			object:    "Mutation",
+			operation: "ApproveOrgAccess",
+			args:      map[string]interface{}{"ID": "request-1", "Approve": true},
+			orgNames:  []string{"openconfig"},
+			want: []db.AuditEntry{{
+				Time: now, Principal: "alice@example.com", Subject: "uid", OrgName: "openconfig", Operation: "ApproveOrgAccess",
+				Target: "ID=request-1", Outcome: db.AuditSuccess,
+			}},
+		},
+		{
+			desc:      "organizations of API token resolved by RevokeAPIToken",
+			ctx:       user,
+			object:    "Mutation",
+			operation: "RevokeAPIToken",
+			args:      map[string]interface{}{"ID": "token-1"},
+			orgNames:  []string{"openconfig", "ietf"},
+			err:       errors.New("not admin"),
+			want: []db.AuditEntry{
+				{
+					Time: now, Principal: "alice@example.com", Subject: "uid", OrgName: "openconfig", Operation: "RevokeAPIToken",
+					Target: "ID=token-1", Outcome: db.AuditFailure, Error: "not admin",
+				},
+				{
+					Time: now, Principal: "alice@example.com", Subject: "uid", OrgName: "ietf", Operation: "RevokeAPIToken",
+					Target: "ID=token-1", Outcome: db.AuditFailure, Error: "not admin",
+				},
+			},
+		},
+		{
+			desc:      "organizations in input list of CreateAPIToken",
+			ctx:       user,
+			object:    "Mutation",
+			operation: "CreateAPIToken",
+			args:      map[string]interface{}{"Input": newAPIToken{OrgNames: []string{"openconfig", "ietf"}, Operations: []string{"publish"}}},
+			orgNames:  []string{"openconfig", "ietf"},
+			want: []db.AuditEntry{
+				{
+					Time: now, Principal: "alice@example.com", Subject: "uid", OrgName: "openconfig", Operation: "CreateAPIToken",
+					Target: "OrgNames=[openconfig ietf], Operations=[publish]", Outcome: db.AuditSuccess,
+				},
+				{
+					Time: now, Principal: "alice@example.com", Subject: "uid", OrgName: "ietf", Operation: "CreateAPIToken",
+					Target: "OrgNames=[openconfig ietf], Operations=[publish]", Outcome: db.AuditSuccess,
+				},
+			},
+		},
+		{
+			desc:      "query is not audited",
+			ctx:       user,
+			object:    "Query",
+			operation: "ListAccess",
+			args:      map[string]interface{}{"OrgName": "openconfig"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			entries = nil
+			ctx := graphql.WithFieldContext(tc.ctx, &graphql.FieldContext{
+				Object: tc.object,
+				Field:  graphql.CollectedField{Field: &ast.Field{Name: tc.operation}},
+				Args:   tc.args,
+			})
+			res, err := Middleware(ctx, func(ctx context.Context) (interface{}, error) {
+				SetOrgNames(ctx, tc.orgNames...)
+				return "Success", tc.err
+			})
+			if res != "Success" || err != tc.err {
+				t.Errorf("Middleware changed result of resolver, got: %v, %v", res, err)
+			}
+			if diff := cmp.Diff(tc.want, entries); diff != "" {
+				t.Errorf("audit entries mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package catalogdiff compares two sets of catalog entries, e.g., two snapshots of catalog or modules of two releases,
+// and reports added, removed and changed modules and feature bundles with their differing fields.
+// Entries are unmarshalled into ygot go structs, and fields are compared leaf by leaf.
+package catalogdiff
+
+import (
+	"fmt"
+	"sort"
+
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+	"github.com/openconfig/gnmi/value"
+	"github.com/openconfig/ygot/ygot"
+)
+
+// Kinds of compared entries.
+const (
+	KindModule        = `module`
+	KindFeatureBundle = `featureBundle`
+)
+
+// Status of changes.
+const (
+	Added   = `added`   // Entry only exists in the set compared to.
+	Removed = `removed` // Entry only exists in the set compared from.
+	Changed = `changed` // Entry exists in both sets with different fields.
+)
+
+// Entry is a module or feature bundle of an organization in JSON.
+type Entry struct {
+	OrgName string `json:"orgName"`
+	Data    string `json:"data"`
+}
+
+// Set is a set of modules and feature bundles compared by Diff.
+type Set struct {
+	Modules        []Entry `json:"modules"`
+	FeatureBundles []Entry `json:"featureBundles"`
+}
+
+// FieldDiff is a leaf of module or feature bundle whose values differ between two sets.
+type FieldDiff struct {
+	Path string `json:"path" yaml:"path"`                     // Path of leaf in module or feature bundle, e.g., `/summary`.
+	From string `json:"from,omitempty" yaml:"from,omitempty"` // Value in the set compared from, "" if it is not set.
+	To   string `json:"to,omitempty" yaml:"to,omitempty"`     // Value in the set compared to, "" if it is not set.
+}
+
+// Change is a module or feature bundle which is added, removed or changed between two sets.
+type Change struct {
+	Kind        string      `json:"kind" yaml:"kind"` // KindModule or KindFeatureBundle.
+	OrgName     string      `json:"orgName" yaml:"orgName"`
+	Name        string      `json:"name" yaml:"name"`
+	FromVersion string      `json:"fromVersion,omitempty" yaml:"fromVersion,omitempty"` // Version in the set compared from, "" if it is added.
+	ToVersion   string      `json:"toVersion,omitempty" yaml:"toVersion,omitempty"`     // Version in the set compared to, "" if it is removed.
+	Status      string      `json:"status" yaml:"status"`                               // Added, Removed or Changed.
+	Fields      []FieldDiff `json:"fields,omitempty" yaml:"fields,omitempty"`           // Differing leaves if Status is Changed.
+}
+
+// version returns version of changed entry in the set compared from, or in the set compared to if it is added.
+func (c Change) version() string {
+	if c.FromVersion != "" {
+		return c.FromVersion
+	}
+	return c.ToVersion
+}
+
+// parsedEntry is an entry unmarshalled into ygot go struct.
+type parsedEntry struct {
+	orgName, name, version string
+	s                      ygot.GoStruct
+}
+
+// nameKey returns organization and name of *e*, which identify it when entries are matched by name.
+func (e parsedEntry) nameKey() string {
+	return e.orgName + "/" + e.name
+}
+
+// parseEntries unmarshals *entries* of *kind* into ygot go structs.
+func parseEntries(kind string, entries []Entry) ([]parsedEntry, error) {
+	var res []parsedEntry
+	for _, entry := range entries {
+		var e parsedEntry
+		if kind == KindModule {
+			module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+			if err := oc.Unmarshal([]byte(entry.Data), module); err != nil {
+				return nil, fmt.Errorf("cannot unmarshal module of %s: %v", entry.OrgName, err)
+			}
+			e = parsedEntry{orgName: entry.OrgName, name: module.GetName(), version: module.GetVersion(), s: module}
+		} else {
+			featureBundle := &oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle{}
+			if err := oc.Unmarshal([]byte(entry.Data), featureBundle); err != nil {
+				return nil, fmt.Errorf("cannot unmarshal feature bundle of %s: %v", entry.OrgName, err)
+			}
+			e = parsedEntry{orgName: entry.OrgName, name: featureBundle.GetName(), version: featureBundle.GetVersion(), s: featureBundle}
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
+
+// multipleVersions returns organizations and names having multiple versions in any of *sets*.
+func multipleVersions(sets ...[]parsedEntry) map[string]bool {
+	res := map[string]bool{}
+	for _, entries := range sets {
+		seen := map[string]bool{}
+		for _, e := range entries {
+			res[e.nameKey()] = res[e.nameKey()] || seen[e.nameKey()]
+			seen[e.nameKey()] = true
+		}
+	}
+	return res
+}
+
+// keyEntries returns *entries* keyed by organization and name if *byName* is true and they are not in *multiVersion*,
+// or by organization, name and version otherwise.
+func keyEntries(entries []parsedEntry, byName bool, multiVersion map[string]bool) map[string]parsedEntry {
+	res := map[string]parsedEntry{}
+	for _, e := range entries {
+		key := e.nameKey()
+		if !byName || multiVersion[key] {
+			key += "@" + e.version
+		}
+		res[key] = e
+	}
+	return res
+}
+
+// DiffEntries compares entries of *kind* from *from* to *to*. Entries are matched by organization and name if *byName* is true,
+// e.g., to compare releases containing a version of each module, so that version changes are reported as changed fields,
+// or by organization, name and version otherwise. Names having multiple versions in either set, e.g., modules
+// required in two versions by a dependency closure, cannot be matched by name, so their versions are matched
+// by version and all of them are reported. Changes are sorted by organization, name and version.
+func DiffEntries(kind string, from []Entry, to []Entry, byName bool) ([]Change, error) {
+	fromParsed, err := parseEntries(kind, from)
+	if err != nil {
+		return nil, fmt.Errorf("DiffEntries: %v", err)
+	}
+	toParsed, err := parseEntries(kind, to)
+	if err != nil {
+		return nil, fmt.Errorf("DiffEntries: %v", err)
+	}
+	multiVersion := multipleVersions(fromParsed, toParsed)
+	fromEntries := keyEntries(fromParsed, byName, multiVersion)
+	toEntries := keyEntries(toParsed, byName, multiVersion)
+	changes := []Change{}
+	for key, f := range fromEntries {
+		c := Change{Kind: kind, OrgName: f.orgName, Name: f.name, FromVersion: f.version}
+		t, ok := toEntries[key]
+		if !ok {
+			c.Status = Removed
+			changes = append(changes, c)
+			continue
+		}
+		c.ToVersion = t.version
+		if c.Fields, err = Fields(f.s, t.s); err != nil {
+			return nil, fmt.Errorf("DiffEntries: compare %s failed: %v", key, err)
+		}
+		if len(c.Fields) > 0 {
+			c.Status = Changed
+			changes = append(changes, c)
+		}
+	}
+	for key, t := range toEntries {
+		if _, ok := fromEntries[key]; !ok {
+			changes = append(changes, Change{Kind: kind, OrgName: t.orgName, Name: t.name, ToVersion: t.version, Status: Added})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.OrgName != b.OrgName {
+			return a.OrgName < b.OrgName
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.version() < b.version()
+	})
+	return changes, nil
+}
+
+// Diff compares modules and then feature bundles from set *from* to set *to*, entries are matched as DiffEntries.
+func Diff(from Set, to Set, byName bool) ([]Change, error) {
+	modules, err := DiffEntries(KindModule, from.Modules, to.Modules, byName)
+	if err != nil {
+		return nil, fmt.Errorf("Diff: %v", err)
+	}
+	featureBundles, err := DiffEntries(KindFeatureBundle, from.FeatureBundles, to.FeatureBundles, byName)
+	if err != nil {
+		return nil, fmt.Errorf("Diff: %v", err)
+	}
+	return append(modules, featureBundles...), nil
+}
+
+// Fields returns leaves whose values differ from ygot go struct *from* to *to*, sorted by path.
+func Fields(from ygot.GoStruct, to ygot.GoStruct) ([]FieldDiff, error) {
+	fields := map[string]*FieldDiff{}
+	// Updates of diff from one struct to the other contain values of the other struct of all differing leaves.
+	for _, dir := range []struct {
+		original, modified ygot.GoStruct
+		set                func(f *FieldDiff, v string)
+	}{
+		{original: from, modified: to, set: func(f *FieldDiff, v string) { f.To = v }},
+		{original: to, modified: from, set: func(f *FieldDiff, v string) { f.From = v }},
+	} {
+		n, err := ygot.Diff(dir.original, dir.modified)
+		if err != nil {
+			return nil, err
+		}
+		for _, update := range n.GetUpdate() {
+			path, err := ygot.PathToString(update.GetPath())
+			if err != nil {
+				return nil, err
+			}
+			v, err := value.ToScalar(update.GetVal())
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of %s: %v", path, err)
+			}
+			if fields[path] == nil {
+				fields[path] = &FieldDiff{Path: path}
+			}
+			dir.set(fields[path], fmt.Sprint(v))
+		}
+	}
+	res := []FieldDiff{}
+	for _, f := range fields {
+		res = append(res, *f)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res, nil
+}
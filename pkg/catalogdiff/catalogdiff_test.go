@@ -0,0 +1,119 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package catalogdiff
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+	"github.com/openconfig/ygot/ygot"
+)
+
+// moduleEntry returns entry of module *name* of *version* in organization *orgName* with *summary*.
+func moduleEntry(t *testing.T, orgName string, name string, version string, summary string) Entry {
+	module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{Name: ygot.String(name), Version: ygot.String(version)}
+	if summary != "" {
+		module.Summary = ygot.String(summary)
+	}
+	data, err := ygot.EmitJSON(module, &ygot.EmitJSONConfig{Format: ygot.RFC7951, RFC7951Config: &ygot.RFC7951JSONConfig{AppendModuleName: true}})
+	if err != nil {
+		t.Fatalf("marshal module failed: %v", err)
+	}
+	return Entry{OrgName: orgName, Data: data}
+}
+
+func TestDiff(t *testing.T) {
+	from := Set{Modules: []Entry{
+		moduleEntry(t, "openconfig", "openconfig-interfaces", "2.4.3", "interfaces"),
+		moduleEntry(t, "openconfig", "openconfig-bgp", "6.0.0", "bgp"),
+		moduleEntry(t, "openconfig", "openconfig-lldp", "0.2.1", "lldp"),
+	}}
+	to := Set{Modules: []Entry{
+		moduleEntry(t, "openconfig", "openconfig-interfaces", "3.0.0", "interfaces"),
+		moduleEntry(t, "openconfig", "openconfig-bgp", "6.0.0", "BGP"),
+		moduleEntry(t, "openconfig", "openconfig-aft", "1.0.0", "aft"),
+	}}
+	tests := []struct {
+		desc   string
+		from   Set
+		to     Set
+		byName bool
+		want   []Change
+	}{
+		{
+			desc: "same sets",
+			from: from,
+			to:   from,
+			want: []Change{},
+		},
+		{
+			desc: "match by version",
+			from: from,
+			to:   to,
+			want: []Change{
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-aft", ToVersion: "1.0.0", Status: Added},
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-bgp", FromVersion: "6.0.0", ToVersion: "6.0.0", Status: Changed,
+					Fields: []FieldDiff{{Path: "/summary", From: "bgp", To: "BGP"}}},
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-interfaces", FromVersion: "2.4.3", Status: Removed},
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-interfaces", ToVersion: "3.0.0", Status: Added},
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-lldp", FromVersion: "0.2.1", Status: Removed},
+			},
+		},
+		{
+			desc:   "match by name",
+			from:   from,
+			to:     to,
+			byName: true,
+			want: []Change{
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-aft", ToVersion: "1.0.0", Status: Added},
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-bgp", FromVersion: "6.0.0", ToVersion: "6.0.0", Status: Changed,
+					Fields: []FieldDiff{{Path: "/summary", From: "bgp", To: "BGP"}}},
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-interfaces", FromVersion: "2.4.3", ToVersion: "3.0.0", Status: Changed,
+					Fields: []FieldDiff{{Path: "/version", From: "2.4.3", To: "3.0.0"}}},
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-lldp", FromVersion: "0.2.1", Status: Removed},
+			},
+		},
+		{
+			desc: "match by name with multiple versions of a module",
+			from: Set{Modules: []Entry{
+				moduleEntry(t, "openconfig", "openconfig-interfaces", "2.4.3", "interfaces"),
+				moduleEntry(t, "openconfig", "openconfig-bgp", "6.0.0", "bgp"),
+			}},
+			to: Set{Modules: []Entry{
+				moduleEntry(t, "openconfig", "openconfig-interfaces", "2.4.3", "interfaces"),
+				moduleEntry(t, "openconfig", "openconfig-interfaces", "3.0.0", "interfaces"),
+				moduleEntry(t, "openconfig", "openconfig-bgp", "6.1.0", "bgp"),
+			}},
+			byName: true,
+			want: []Change{
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-bgp", FromVersion: "6.0.0", ToVersion: "6.1.0", Status: Changed,
+					Fields: []FieldDiff{{Path: "/version", From: "6.0.0", To: "6.1.0"}}},
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-interfaces", ToVersion: "3.0.0", Status: Added},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := Diff(tc.from, tc.to, tc.byName)
+			if err != nil {
+				t.Fatalf("Diff failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Diff mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+// This file contains typed methods of Client, which send GraphQL operations generated into package gqlclient
+// to catalog server and decode responses into ygot go structs.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/openconfig/catalog-server/pkg/client/gqlclient"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+	"github.com/openconfig/ygot/ygot"
+)
+
+// ErrNotFound is returned when the requested module or feature bundle does not exist.
+var ErrNotFound = errors.New("not found in catalog")
+
+// Entry is a module or feature bundle of an organization in raw JSON data, e.g., for exporting catalog.
+type Entry struct {
+	OrgName string `json:"orgName"` // Name of organization of module or feature bundle.
+	Data    string `json:"data"`    // JSON data of module or feature bundle.
+}
+
+// toEntries converts *entries* of query results into Entry.
+func toEntries(entries []dataEntry) []Entry {
+	res := []Entry{}
+	for _, entry := range entries {
+		res = append(res, Entry{OrgName: entry.OrgName, Data: *entry.Data})
+	}
+	return res
+}
+
+// optional returns nil for empty *s*, so that optional arguments of queries are not given.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// newDataEntry returns entry of *data* of organization *orgName*.
+func newDataEntry(orgName string, data string) dataEntry {
+	return dataEntry{OrgName: orgName, Data: &data}
+}
+
+// modulesByOrgName returns entries of modules of organization *orgName*, or of all organizations if *orgName* is "".
+func (c *Client) modulesByOrgName(ctx context.Context, orgName string) ([]dataEntry, error) {
+	resp, err := gqlclient.ModulesByOrgName(ctx, c, optional(orgName))
+	if err != nil {
+		return nil, err
+	}
+	entries := []dataEntry{}
+	for _, m := range resp.ModulesByOrgName {
+		entries = append(entries, newDataEntry(m.OrgName, m.Data))
+	}
+	return entries, nil
+}
+
+// modulesByKey returns entries of modules of *name* and *version* in all organizations, empty *name* or *version* matches any.
+func (c *Client) modulesByKey(ctx context.Context, name string, version string) ([]dataEntry, error) {
+	resp, err := gqlclient.ModulesByKey(ctx, c, optional(name), optional(version))
+	if err != nil {
+		return nil, err
+	}
+	entries := []dataEntry{}
+	for _, m := range resp.ModulesByKey {
+		entries = append(entries, newDataEntry(m.OrgName, m.Data))
+	}
+	return entries, nil
+}
+
+// featureBundlesByOrgName returns entries of feature bundles of organization *orgName*, or of all organizations if *orgName* is "".
+func (c *Client) featureBundlesByOrgName(ctx context.Context, orgName string) ([]dataEntry, error) {
+	resp, err := gqlclient.FeatureBundlesByOrgName(ctx, c, optional(orgName))
+	if err != nil {
+		return nil, err
+	}
+	entries := []dataEntry{}
+	for _, fb := range resp.FeatureBundlesByOrgName {
+		entries = append(entries, newDataEntry(fb.OrgName, fb.Data))
+	}
+	return entries, nil
+}
+
+// featureBundlesByKey returns entries of feature bundles of *name* and *version* in all organizations, empty *name* or *version* matches any.
+func (c *Client) featureBundlesByKey(ctx context.Context, name string, version string) ([]dataEntry, error) {
+	resp, err := gqlclient.FeatureBundlesByKey(ctx, c, optional(name), optional(version))
+	if err != nil {
+		return nil, err
+	}
+	entries := []dataEntry{}
+	for _, fb := range resp.FeatureBundlesByKey {
+		entries = append(entries, newDataEntry(fb.OrgName, fb.Data))
+	}
+	return entries, nil
+}
+
+// toJSON marshals ygot go struct *s* into RFC7951 JSON expected by catalog server.
+func toJSON(s ygot.ValidatedGoStruct) (string, error) {
+	return ygot.EmitJSON(s, &ygot.EmitJSONConfig{
+		Format: ygot.RFC7951,
+		RFC7951Config: &ygot.RFC7951JSONConfig{
+			AppendModuleName: true,
+		},
+	})
+}
+
+// ListModules returns modules of organization *orgName*, or modules of all organizations if *orgName* is "".
+func (c *Client) ListModules(ctx context.Context, orgName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	entries, err := c.modulesByOrgName(ctx, orgName)
+	if err != nil {
+		return nil, fmt.Errorf("ListModules: %w", err)
+	}
+	return unmarshalModules(entries)
+}
+
+// ListModuleEntries returns modules of organization *orgName*, or of all organizations if *orgName* is "", in raw JSON data.
+func (c *Client) ListModuleEntries(ctx context.Context, orgName string) ([]Entry, error) {
+	entries, err := c.modulesByOrgName(ctx, orgName)
+	if err != nil {
+		return nil, fmt.Errorf("ListModuleEntries: %w", err)
+	}
+	return toEntries(entries), nil
+}
+
+// SearchModules returns modules of name *name* and version *version* in all organizations, empty *name* or *version* matches any.
+func (c *Client) SearchModules(ctx context.Context, name string, version string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	entries, err := c.modulesByKey(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("SearchModules: %w", err)
+	}
+	return unmarshalModules(entries)
+}
+
+// SearchModuleEntries returns modules of name *name* and version *version* in all organizations in raw JSON data,
+// empty *name* or *version* matches any.
+func (c *Client) SearchModuleEntries(ctx context.Context, name string, version string) ([]Entry, error) {
+	entries, err := c.modulesByKey(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("SearchModuleEntries: %w", err)
+	}
+	return toEntries(entries), nil
+}
+
+// GetModule returns module of *name* and *version* in organization *orgName*, ErrNotFound is returned if it does not exist.
+func (c *Client) GetModule(ctx context.Context, orgName string, name string, version string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	entries, err := c.modulesByKey(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("GetModule: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.OrgName != orgName {
+			continue
+		}
+		modules, err := unmarshalModules([]dataEntry{entry})
+		if err != nil {
+			return nil, fmt.Errorf("GetModule: %w", err)
+		}
+		return &modules[0], nil
+	}
+	return nil, fmt.Errorf("GetModule: module %s@%s of %s %w", name, version, orgName, ErrNotFound)
+}
+
+// CreateModule creates *module* in organization *orgName*, or updates it if it exists.
+func (c *Client) CreateModule(ctx context.Context, orgName string, module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) error {
+	data, err := toJSON(module)
+	if err != nil {
+		return fmt.Errorf("CreateModule: marshal module failed: %v", err)
+	}
+	if _, err := gqlclient.CreateModule(ctx, c, gqlclient.NewModule{OrgName: orgName, Data: data}); err != nil {
+		return fmt.Errorf("CreateModule: %w", err)
+	}
+	return nil
+}
+
+// DeleteModule deletes module of *name* and *version* in organization *orgName*.
+func (c *Client) DeleteModule(ctx context.Context, orgName string, name string, version string) error {
+	if _, err := gqlclient.DeleteModule(ctx, c, gqlclient.ModuleKey{OrgName: orgName, Name: name, Version: version}); err != nil {
+		return fmt.Errorf("DeleteModule: %w", err)
+	}
+	return nil
+}
+
+// ListFeatureBundles returns feature bundles of organization *orgName*, or of all organizations if *orgName* is "".
+func (c *Client) ListFeatureBundles(ctx context.Context, orgName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	entries, err := c.featureBundlesByOrgName(ctx, orgName)
+	if err != nil {
+		return nil, fmt.Errorf("ListFeatureBundles: %w", err)
+	}
+	return unmarshalFeatureBundles(entries)
+}
+
+// ListFeatureBundleEntries returns feature bundles of organization *orgName*, or of all organizations if *orgName* is "", in raw JSON data.
+func (c *Client) ListFeatureBundleEntries(ctx context.Context, orgName string) ([]Entry, error) {
+	entries, err := c.featureBundlesByOrgName(ctx, orgName)
+	if err != nil {
+		return nil, fmt.Errorf("ListFeatureBundleEntries: %w", err)
+	}
+	return toEntries(entries), nil
+}
+
+// SearchFeatureBundles returns feature bundles of name *name* and version *version* in all organizations, empty *name* or *version* matches any.
+func (c *Client) SearchFeatureBundles(ctx context.Context, name string, version string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	entries, err := c.featureBundlesByKey(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("SearchFeatureBundles: %w", err)
+	}
+	return unmarshalFeatureBundles(entries)
+}
+
+// SearchFeatureBundleEntries returns feature bundles of name *name* and version *version* in all organizations in raw JSON data,
+// empty *name* or *version* matches any.
+func (c *Client) SearchFeatureBundleEntries(ctx context.Context, name string, version string) ([]Entry, error) {
+	entries, err := c.featureBundlesByKey(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("SearchFeatureBundleEntries: %w", err)
+	}
+	return toEntries(entries), nil
+}
+
+// GetFeatureBundle returns feature bundle of *name* and *version* in organization *orgName*, ErrNotFound is returned if it does not exist.
+func (c *Client) GetFeatureBundle(ctx context.Context, orgName string, name string, version string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	entries, err := c.featureBundlesByKey(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("GetFeatureBundle: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.OrgName != orgName {
+			continue
+		}
+		featureBundles, err := unmarshalFeatureBundles([]dataEntry{entry})
+		if err != nil {
+			return nil, fmt.Errorf("GetFeatureBundle: %w", err)
+		}
+		return &featureBundles[0], nil
+	}
+	return nil, fmt.Errorf("GetFeatureBundle: feature bundle %s@%s of %s %w", name, version, orgName, ErrNotFound)
+}
+
+// CreateFeatureBundle creates *featureBundle* in organization *orgName*, or updates it if it exists.
+func (c *Client) CreateFeatureBundle(ctx context.Context, orgName string, featureBundle *oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle) error {
+	data, err := toJSON(featureBundle)
+	if err != nil {
+		return fmt.Errorf("CreateFeatureBundle: marshal feature bundle failed: %v", err)
+	}
+	if _, err := gqlclient.CreateFeatureBundle(ctx, c, gqlclient.NewFeatureBundle{OrgName: orgName, Data: data}); err != nil {
+		return fmt.Errorf("CreateFeatureBundle: %w", err)
+	}
+	return nil
+}
+
+// DeleteFeatureBundle deletes feature bundle of *name* and *version* in organization *orgName*.
+func (c *Client) DeleteFeatureBundle(ctx context.Context, orgName string, name string, version string) error {
+	if _, err := gqlclient.DeleteFeatureBundle(ctx, c, gqlclient.FeatureBundleKey{OrgName: orgName, Name: name, Version: version}); err != nil {
+		return fmt.Errorf("DeleteFeatureBundle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/openconfig/catalog-server/pkg/catalogdiff"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+	"github.com/openconfig/ygot/ygot"
+)
+
+// fakeCatalog is a fake GraphQL server of catalog containing modules and feature bundles of organizations.
+type fakeCatalog struct {
+	modules        []dataEntry
+	featureBundles []dataEntry
+	token          string               // Token required by mutations.
+	created        []dataEntry          // Data created by mutations.
+	requests       []graphQLRequest     // All requests received.
+	changes        []catalogdiff.Change // Changes returned by CatalogDiff.
+}
+
+// newModuleEntry returns entry of module *name* of *version* in organization *orgName*.
+func newModuleEntry(t *testing.T, orgName string, name string, version string) dataEntry {
+	data, err := toJSON(&oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{Name: ygot.String(name), Version: ygot.String(version)})
+	if err != nil {
+		t.Fatalf("marshal module failed: %v", err)
+	}
+	return dataEntry{OrgName: orgName, Data: &data}
+}
+
+// newFeatureBundleEntry returns entry of feature bundle *name* of *version* in organization *orgName*.
+func newFeatureBundleEntry(t *testing.T, orgName string, name string, version string) dataEntry {
+	data, err := toJSON(&oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle{Name: ygot.String(name), Version: ygot.String(version)})
+	if err != nil {
+		t.Fatalf("marshal feature bundle failed: %v", err)
+	}
+	return dataEntry{OrgName: orgName, Data: &data}
+}
+
+// filter returns entries of organization in variable OrgName, or all entries if it is not given.
+func filter(entries []dataEntry, vars map[string]interface{}) []dataEntry {
+	res := []dataEntry{}
+	for _, entry := range entries {
+		if orgName, ok := vars["OrgName"].(string); !ok || entry.OrgName == orgName {
+			res = append(res, entry)
+		}
+	}
+	return res
+}
+
+func (f *fakeCatalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+	if r.Method != "POST" || json.NewDecoder(r.Body).Decode(&req) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.requests = append(f.requests, req)
+	respond := func(queryName string, result interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{queryName: result}})
+	}
+	fail := func(msg string) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": []GraphQLError{{Message: msg}}, "data": nil})
+	}
+	switch {
+	case strings.Contains(req.Query, "ModulesByOrgName"):
+		respond("ModulesByOrgName", filter(f.modules, req.Variables))
+	case strings.Contains(req.Query, "ModulesByKey"):
+		respond("ModulesByKey", f.modules)
+	case strings.Contains(req.Query, "FeatureBundlesByOrgName"):
+		respond("FeatureBundlesByOrgName", filter(f.featureBundles, req.Variables))
+	case strings.Contains(req.Query, "CatalogDiff"):
+		respond("CatalogDiff", f.changes)
+	case strings.Contains(req.Query, "mutation"):
+		if r.Header.Get("Authorization") != "Bearer "+f.token {
+			fail("validate token failed")
+			return
+		}
+		input := req.Variables["Input"].(map[string]interface{})
+		if data, ok := input["Data"].(string); ok {
+			f.created = append(f.created, dataEntry{OrgName: input["OrgName"].(string), Data: &data})
+		}
+		respond("Mutation", "Success")
+	default:
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fail("unknown query")
+	}
+}
+
+func TestClientMethods(t *testing.T) {
+	ctx := context.Background()
+	catalog := &fakeCatalog{
+		modules: []dataEntry{
+			newModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0"),
+			newModuleEntry(t, "ietf", "ietf-interfaces", "2.0.0"),
+		},
+		featureBundles: []dataEntry{newFeatureBundleEntry(t, "openconfig", "routing", "1.0.0")},
+		token:          "secret",
+	}
+	ts := httptest.NewServer(catalog)
+	defer ts.Close()
+	c, err := NewClient("", WithServerURL(ts.URL+"/"))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	modules, err := c.ListModules(ctx, "ietf")
+	if err != nil {
+		t.Fatalf("ListModules failed: %v", err)
+	}
+	if len(modules) != 1 || modules[0].GetName() != "ietf-interfaces" {
+		t.Errorf("ListModules mismatch, got: %v", modules)
+	}
+	if _, ok := catalog.requests[0].Variables["OrgName"]; !ok {
+		t.Errorf("ListModules should send OrgName as variable, got: %v", catalog.requests[0])
+	}
+
+	entries, err := c.ListModuleEntries(ctx, "openconfig")
+	if err != nil {
+		t.Fatalf("ListModuleEntries failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].OrgName != "openconfig" || entries[0].Data != *catalog.modules[0].Data {
+		t.Errorf("ListModuleEntries mismatch, got: %v", entries)
+	}
+
+	module, err := c.GetModule(ctx, "openconfig", "openconfig-interfaces", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetModule failed: %v", err)
+	}
+	if module.GetVersion() != "1.0.0" {
+		t.Errorf("GetModule mismatch, got: %v", module)
+	}
+	if _, err := c.GetModule(ctx, "vendor", "openconfig-interfaces", "1.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetModule of other organization should not be found, got: %v", err)
+	}
+
+	featureBundles, err := c.ListFeatureBundles(ctx, "")
+	if err != nil {
+		t.Fatalf("ListFeatureBundles failed: %v", err)
+	}
+	if len(featureBundles) != 1 || featureBundles[0].GetName() != "routing" {
+		t.Errorf("ListFeatureBundles mismatch, got: %v", featureBundles)
+	}
+
+	// Mutations without token fail with errors returned by server.
+	var respErr *ResponseError
+	if err := c.DeleteModule(ctx, "openconfig", "openconfig-interfaces", "1.0.0"); !errors.As(err, &respErr) || respErr.Errors[0].Message != "validate token failed" {
+		t.Errorf("DeleteModule without token should return ResponseError, got: %v", err)
+	}
+
+	WithToken(catalog.token)(c)
+	if err := c.CreateModule(ctx, "vendor", &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{Name: ygot.String("vendor-x"), Version: ygot.String("0.1.0")}); err != nil {
+		t.Fatalf("CreateModule failed: %v", err)
+	}
+	if len(catalog.created) != 1 || catalog.created[0].OrgName != "vendor" {
+		t.Fatalf("CreateModule should send module of vendor, got: %v", catalog.created)
+	}
+	created, err := unmarshalModules(catalog.created)
+	if err != nil || created[0].GetName() != "vendor-x" {
+		t.Errorf("CreateModule sent invalid data, got: %v, err: %v", created, err)
+	}
+	if err := c.DeleteFeatureBundle(ctx, "openconfig", "routing", "1.0.0"); err != nil {
+		t.Errorf("DeleteFeatureBundle failed: %v", err)
+	}
+
+	if err := c.Do(ctx, `{ Unknown }`, nil, nil); !errors.As(err, &respErr) {
+		t.Errorf("invalid query should return ResponseError, got: %v", err)
+	}
+	if err := (&Client{}).Do(ctx, `{ ModulesByOrgName { Data } }`, nil, nil); err == nil {
+		t.Errorf("Do without server URL should fail")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheFileExt is extension of files of cached results.
+const cacheFileExt = `.json`
+
+// timeNow returns current time, which is replaced in tests.
+var timeNow = time.Now
+
+// cacheEntry is a cached response of a query stored in a file.
+type cacheEntry struct {
+	ETag     string        `json:"etag"`     // ETag of response, used to revalidate the entry.
+	StoredAt time.Time     `json:"storedAt"` // Time when response is received or revalidated.
+	Lifetime time.Duration `json:"lifetime"` // Duration after StoredAt in which entry is used without revalidation.
+	Body     []byte        `json:"body"`     // Body of response.
+}
+
+// fresh checks whether *e* can be used without revalidation.
+func (e *cacheEntry) fresh() bool {
+	return timeNow().Before(e.StoredAt.Add(e.Lifetime))
+}
+
+// diskCache caches results of queries in files of a directory.
+type diskCache struct {
+	dir string        // Directory of cached results.
+	ttl time.Duration // Lifetime of results if server does not specify one.
+}
+
+// WithCache enables on-disk cache of query results in directory *dir*, which is created if it does not exist.
+// Cached results are used without contacting server for *ttl*, or for max-age given by Cache-Control of response.
+// After that, they are revalidated with ETag of response, and used again if server responds 304 Not Modified.
+// Responses with Cache-Control no-store are not cached, and responses with no-cache are always revalidated.
+func WithCache(dir string, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cache = &diskCache{dir: dir, ttl: ttl}
+	}
+}
+
+// InvalidateCache removes all cached results, it does nothing if cache is not enabled.
+func (c *Client) InvalidateCache() error {
+	if c.cache == nil {
+		return nil
+	}
+	if err := c.cache.clear(); err != nil {
+		return fmt.Errorf("InvalidateCache: %v", err)
+	}
+	return nil
+}
+
+// key returns key of cached results of GraphQL request *body* sent with *token*.
+// Results of different users are cached separately as they may read different organizations,
+// but results of a user are kept when token is refreshed, as key depends on identity of token rather than its value.
+func (d *diskCache) key(token string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(tokenIdentity(token)))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// get returns cached entry of *key*, nil is returned if there is no such entry or it is corrupted.
+func (d *diskCache) get(key string) *cacheEntry {
+	data, err := ioutil.ReadFile(filepath.Join(d.dir, key+cacheFileExt))
+	if err != nil {
+		return nil
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil
+	}
+	return entry
+}
+
+// put stores *entry* of *key*, the file is replaced atomically.
+func (d *diskCache) put(key string, entry *cacheEntry) error {
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return fmt.Errorf("create cache directory failed: %v", err)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal cache entry failed: %v", err)
+	}
+	if err := writeFileAtomic(filepath.Join(d.dir, key+cacheFileExt), data); err != nil {
+		return fmt.Errorf("write cache file failed: %v", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes *data* into file *path* through a temporary file in the same directory,
+// so that concurrent readers never see partial content.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// clear removes all cached entries.
+func (d *diskCache) clear() error {
+	files, err := filepath.Glob(filepath.Join(d.dir, "*"+cacheFileExt))
+	if err != nil {
+		return fmt.Errorf("list cache files failed: %v", err)
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove cache file failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// lifetime returns how long response of *header* can be used without revalidation according to its Cache-Control,
+// *ttl* is used if Cache-Control does not specify one. false is returned if response should not be stored.
+func lifetime(header http.Header, ttl time.Duration) (time.Duration, bool) {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store":
+			return 0, false
+		case directive == "no-cache":
+			ttl = 0
+		case strings.HasPrefix(directive, "max-age="):
+			if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil && seconds >= 0 {
+				ttl = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return ttl, true
+}
+
+// postCached sends GraphQL request *body* like post, but answers it from cache if a fresh result is cached,
+// or revalidates the cached result with its ETag. Only successful responses are cached.
+func (c *Client) postCached(ctx context.Context, body []byte) (int, []byte, error) {
+	token, err := c.authToken(ctx, "")
+	if err != nil {
+		return 0, nil, err
+	}
+	key := c.cache.key(token, body)
+	entry := c.cache.get(key)
+	if entry != nil && entry.fresh() {
+		return http.StatusOK, entry.Body, nil
+	}
+	etag := ""
+	if entry != nil {
+		etag = entry.ETag
+	}
+	resp, err := c.post(ctx, body, etag, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	status, respBody, header := resp.status, resp.body, resp.header
+	// Token may be refreshed during the request, result is stored for user of the token finally sent.
+	key = c.cache.key(resp.token, body)
+	switch {
+	case status == http.StatusNotModified && entry != nil:
+		entry.StoredAt = timeNow()
+		entry.Lifetime, _ = lifetime(header, c.cache.ttl)
+		respBody = entry.Body
+		status = http.StatusOK
+	case status == http.StatusOK:
+		if _, err := decodeResponse(respBody); err != nil {
+			return status, respBody, nil
+		}
+		lt, store := lifetime(header, c.cache.ttl)
+		if !store {
+			return status, respBody, nil
+		}
+		entry = &cacheEntry{ETag: header.Get("ETag"), StoredAt: timeNow(), Lifetime: lt, Body: respBody}
+	default:
+		return status, respBody, nil
+	}
+	// Failure of caching does not fail the query, the result is fetched again next time.
+	c.cache.put(key, entry)
+	return status, respBody, nil
+}
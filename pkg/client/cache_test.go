@@ -0,0 +1,186 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openconfig/catalog-server/pkg/etag"
+)
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	hits, notModified := 0, 0
+	version := "1.0.0"
+	cacheControl := ""
+	handler := etag.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		fmt.Fprintf(w, `{"data": {"Mutation": "Success", "ModulesByOrgName": [{"Data": "{\"openconfig-module-catalog:name\": \"m\", \"openconfig-module-catalog:version\": \"%s\"}"}]}}`, version)
+	}))
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		if rec.Code == http.StatusNotModified {
+			notModified++
+		}
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	}))
+	defer ts.Close()
+
+	c, err := NewClient("", WithServerURL(ts.URL), WithCache(t.TempDir(), time.Minute))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	// list queries catalog and checks version of the module and numbers of requests served by server.
+	list := func(desc string, wantVersion string, wantHits int, wantNotModified int) {
+		modules, err := c.ListModules(ctx, "openconfig")
+		if err != nil {
+			t.Fatalf("%s: ListModules failed: %v", desc, err)
+		}
+		if len(modules) != 1 || modules[0].GetVersion() != wantVersion {
+			t.Errorf("%s: ListModules mismatch, got: %v, want version: %s", desc, modules, wantVersion)
+		}
+		if hits != wantHits || notModified != wantNotModified {
+			t.Errorf("%s: requests mismatch, got hits: %d, not modified: %d, want: %d, %d", desc, hits, notModified, wantHits, wantNotModified)
+		}
+	}
+
+	list("first query", "1.0.0", 1, 0)
+	list("fresh result is cached", "1.0.0", 1, 0)
+	now = now.Add(2 * time.Minute)
+	list("stale result is revalidated", "1.0.0", 2, 1)
+	list("revalidated result is fresh", "1.0.0", 2, 1)
+	version = "1.1.0"
+	now = now.Add(2 * time.Minute)
+	list("changed result is fetched", "1.1.0", 3, 1)
+
+	WithToken("other")(c)
+	list("results are cached by token", "1.1.0", 4, 1)
+
+	if err := c.DeleteModule(ctx, "openconfig", "m", "1.0.0"); err != nil {
+		t.Fatalf("DeleteModule failed: %v", err)
+	}
+	list("mutation invalidates cache", "1.1.0", 6, 1)
+	if err := c.InvalidateCache(); err != nil {
+		t.Fatalf("InvalidateCache failed: %v", err)
+	}
+	list("cache is invalidated", "1.1.0", 7, 1)
+
+	cacheControl = "no-store"
+	if err := c.InvalidateCache(); err != nil {
+		t.Fatalf("InvalidateCache failed: %v", err)
+	}
+	list("no-store is not cached", "1.1.0", 8, 1)
+	list("no-store is not cached again", "1.1.0", 9, 1)
+	cacheControl = "private, max-age=600"
+	list("max-age overrides ttl", "1.1.0", 10, 1)
+	now = now.Add(5 * time.Minute)
+	list("result is fresh within max-age", "1.1.0", 10, 1)
+}
+
+// newSubjectJWT returns an unsigned JWT of *subject* expiring at *expiry*.
+func newSubjectJWT(subject string, expiry time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"iss":"issuer","sub":%q,"exp":%d}`, subject, expiry.Unix())))
+	return "eyJhbGciOiJub25lIn0." + payload + ".sig"
+}
+
+func TestCacheRefreshedToken(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	hits := 0
+	accepted := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accepted != "" && r.Header.Get("Authorization") != "Bearer "+accepted {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		hits++
+		w.Write([]byte(`{"data": {"ModulesByOrgName": [{"Data": "{\"openconfig-module-catalog:name\": \"m\"}"}]}}`))
+	}))
+	defer ts.Close()
+
+	tests := []struct {
+		desc     string
+		tokens   []string      // Tokens returned by token source in order.
+		accepted string        // Token accepted by server, any token is accepted if it is "".
+		advance  time.Duration // Time passed between the two queries.
+		wantHits int
+	}{
+		{
+			desc:     "token of the same subject refreshed before expiry",
+			tokens:   []string{newSubjectJWT("alice", now.Add(time.Hour)), newSubjectJWT("alice", now.Add(2*time.Hour))},
+			advance:  58 * time.Minute,
+			wantHits: 1,
+		},
+		{
+			desc:     "token of another subject",
+			tokens:   []string{newSubjectJWT("alice", now.Add(time.Hour)), newSubjectJWT("bob", now.Add(2*time.Hour))},
+			advance:  58 * time.Minute,
+			wantHits: 2,
+		},
+		{
+			desc:     "result is stored under token refreshed after status 401",
+			tokens:   []string{"token-1", "token-2"},
+			accepted: "token-2",
+			wantHits: 1,
+		},
+	}
+	for _, tc := range tests {
+		hits, accepted = 0, tc.accepted
+		start := now
+		calls := 0
+		source := TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+			token := tc.tokens[calls]
+			if calls < len(tc.tokens)-1 {
+				calls++
+			}
+			return newToken(token), nil
+		})
+		c, err := NewClient("", WithServerURL(ts.URL), WithTokenSource(source), WithCache(t.TempDir(), 24*time.Hour))
+		if err != nil {
+			t.Fatalf("%s: NewClient failed: %v", tc.desc, err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := c.ListModules(ctx, "openconfig"); err != nil {
+				t.Fatalf("%s: ListModules failed: %v", tc.desc, err)
+			}
+			now = now.Add(tc.advance)
+		}
+		if hits != tc.wantHits {
+			t.Errorf("%s: requests served by server mismatch, got: %d, want: %d", tc.desc, hits, tc.wantHits)
+		}
+		now = start
+	}
+}
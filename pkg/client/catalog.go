@@ -0,0 +1,120 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+// This file contains Catalog interface answered by both Client and Snapshot,
+// and resolution of module dependencies on top of it.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/openconfig/catalog-server/pkg/versions"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+)
+
+// Catalog answers read-only queries of modules and feature bundles,
+// either by querying catalog server (Client) or from a downloaded Snapshot.
+type Catalog interface {
+	ListModules(ctx context.Context, orgName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error)
+	ListModuleEntries(ctx context.Context, orgName string) ([]Entry, error)
+	SearchModules(ctx context.Context, name string, version string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error)
+	SearchModuleEntries(ctx context.Context, name string, version string) ([]Entry, error)
+	GetModule(ctx context.Context, orgName string, name string, version string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error)
+	ListFeatureBundles(ctx context.Context, orgName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error)
+	ListFeatureBundleEntries(ctx context.Context, orgName string) ([]Entry, error)
+	SearchFeatureBundles(ctx context.Context, name string, version string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error)
+	SearchFeatureBundleEntries(ctx context.Context, name string, version string) ([]Entry, error)
+	GetFeatureBundle(ctx context.Context, orgName string, name string, version string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error)
+}
+
+var (
+	_ Catalog = (*Client)(nil)
+	_ Catalog = (*Snapshot)(nil)
+)
+
+// Dependency is a module in dependency closure resolved by ResolveDependencies.
+type Dependency struct {
+	OrgName string
+	Module  *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module
+}
+
+// ResolveDependencies returns module *name* of *version* of organization *orgName* in *catalog*
+// and the transitive closure of its required modules, sorted by name.
+// Required modules are looked up by name, preferring modules of *orgName* and then the latest version,
+// in the same way as archives downloaded from catalog server.
+// Names of required modules that cannot be found in *catalog* are returned as unresolved.
+func ResolveDependencies(ctx context.Context, catalog Catalog, orgName string, name string, version string) ([]Dependency, []string, error) {
+	root, err := catalog.GetModule(ctx, orgName, name, version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ResolveDependencies: %w", err)
+	}
+	deps := []Dependency{{OrgName: orgName, Module: root}}
+	seen := map[string]bool{name: true}
+	var unresolved []string
+	pending := append([]string{}, root.GetDependencies().GetRequiredModule()...)
+	for len(pending) > 0 {
+		required := pending[0]
+		pending = pending[1:]
+		if seen[required] {
+			continue
+		}
+		seen[required] = true
+		entries, err := catalog.SearchModuleEntries(ctx, required, "")
+		if err != nil {
+			return nil, nil, fmt.Errorf("ResolveDependencies: %w", err)
+		}
+		dep, err := bestModule(entries, orgName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ResolveDependencies: %w", err)
+		}
+		if dep == nil {
+			unresolved = append(unresolved, required)
+			continue
+		}
+		deps = append(deps, *dep)
+		pending = append(pending, dep.Module.GetDependencies().GetRequiredModule()...)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i].Module.GetName() < deps[j].Module.GetName()
+	})
+	sort.Strings(unresolved)
+	return deps, unresolved, nil
+}
+
+// bestModule returns module in *entries* of organization *orgName* if there is any, and then of the latest version.
+// A nil pointer is returned if *entries* is empty.
+func bestModule(entries []Entry, orgName string) (*Dependency, error) {
+	var best *Dependency
+	for _, entry := range entries {
+		modules, err := unmarshalModules([]dataEntry{{OrgName: entry.OrgName, Data: &entry.Data}})
+		if err != nil {
+			return nil, err
+		}
+		m := &Dependency{OrgName: entry.OrgName, Module: &modules[0]}
+		switch {
+		case best == nil:
+			best = m
+		case (m.OrgName == orgName) != (best.OrgName == orgName):
+			if m.OrgName == orgName {
+				best = m
+			}
+		case versions.Compare(m.Module.GetVersion(), best.Module.GetVersion()) > 0:
+			best = m
+		}
+	}
+	return best, nil
+}
@@ -17,41 +17,246 @@ Package client contains functions of client side library.
 It provides helper functions to write a client program to:
  * Format query to catalog server.
  * Receive and parse responses into go structs.
+ * Query and update catalog server by typed methods of Client, see api.go.
+ * Download snapshot of catalog and answer the same queries from it locally, see snapshot.go.
 */
 package client
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
-	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
 )
 
 const (
-	dataField     = "data" // All responses from server are put inside a field called `data` of json string.
-	JSONFieldName = `Data` // Name of field in response containing json data.
+	dataField     = "data"   // All responses from server are put inside a field called `data` of json string.
+	JSONFieldName = `Data`   // Name of field in response containing json data.
+	queryPath     = `/query` // Path of GraphQL endpoint of catalog server.
 )
 
-// Client is struct of client containing token string.
+// Client is struct of client containing source of tokens.
 // User should always use `NewClient` to initialize a Client struct.
 type Client struct {
-	token string // token string, this should be initialized.
+	tokens    *cachedTokenSource // Source of tokens, nil if no token is given.
+	serverURL string             // Address of catalog server, e.g., `https://catalog.example.com`, set by WithServerURL.
+	cache     *diskCache         // On-disk cache of query results, set by WithCache.
+
+	httpClient *http.Client  // HTTP client sending requests, set by WithHTTPClient.
+	timeout    time.Duration // Timeout of each attempt of requests, set by WithTimeout.
+	retry      RetryPolicy   // Retries of failed requests, set by WithRetryPolicy.
+}
+
+// Option configures a Client created by NewClient.
+type Option func(*Client)
+
+// WithServerURL sets address of catalog server, e.g., `https://catalog.example.com`, which is required by typed methods of Client.
+func WithServerURL(serverURL string) Option {
+	return func(c *Client) {
+		c.serverURL = strings.TrimSuffix(serverURL, "/")
+	}
+}
+
+// WithToken sets authentication *token* sent as bearer token, which overrides token read from file by NewClient.
+func WithToken(token string) Option {
+	return WithTokenSource(StaticTokenSource(token))
 }
 
-// NewClient returns a new client pointer with filepath of `authentication token`.
-// If filepath is "", then it means no filepath is given, set Client.token to "".
-func NewClient(filepath string) (*Client, error) {
-	token := ""
+// NewClient returns a new client pointer with filepath of `authentication token` configured by *opts*.
+// If filepath is "", then it means no filepath is given, and no token is sent unless it is set by *opts*.
+// Otherwise, the token file is read again when its token is about to expire or rejected by server, see FileTokenSource.
+func NewClient(filepath string, opts ...Option) (*Client, error) {
+	var tokens *cachedTokenSource
 	if filepath != "" {
-		var err error
-		token, err = ReadAuthToken(filepath)
+		token, err := ReadAuthToken(filepath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create a new Client, read token failed: %v", err)
 		}
+		tokens = &cachedTokenSource{source: FileTokenSource(filepath), token: newToken(token)}
+	}
+	c := &Client{
+		tokens:     tokens,
+		httpClient: &http.Client{},
+		timeout:    DefaultTimeout,
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// GraphQLError is an entry of `errors` array of GraphQL response.
+type GraphQLError struct {
+	Message string        `json:"message"` // Message of error, e.g., `CreateModule: validate token failed: ...`.
+	Path    []interface{} `json:"path"`    // Path of field causing error, e.g., `["CreateModule"]`.
+}
+
+// ResponseError is returned when GraphQL response contains errors.
+type ResponseError struct {
+	Errors []GraphQLError // Errors returned by server.
+}
+
+// Error joins messages of all errors of response.
+func (e *ResponseError) Error() string {
+	var msgs []string
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Message)
 	}
-	return &Client{token: token}, nil
+	return "catalog server returned errors: " + strings.Join(msgs, "; ")
+}
+
+// graphQLRequest is body of GraphQL request sent by POST.
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// graphQLResponse is body of GraphQL response.
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphQLError  `json:"errors"`
+}
+
+// Do sends GraphQL *query* with *variables* to catalog server set by WithServerURL by POST,
+// and decodes `data` of response into *out* if *out* is not nil.
+// If response contains GraphQL errors, a *ResponseError is returned, and a *StatusError is returned
+// if server responds status other than 200 without GraphQL errors.
+// Failed requests are retried as configured by WithRetryPolicy until *ctx* is done.
+// Results of queries are cached if cache is enabled by WithCache, and a successful mutation invalidates the cache.
+func (c *Client) Do(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	if c.serverURL == "" {
+		return fmt.Errorf("Do: server URL is not set, use WithServerURL")
+	}
+	body, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("Do: marshal request failed: %v", err)
+	}
+	var status int
+	var respBody []byte
+	mutation := isMutation(query, "")
+	if mutation || c.cache == nil {
+		var resp *response
+		if resp, err = c.post(ctx, body, "", mutation); err == nil {
+			status, respBody = resp.status, resp.body
+		}
+	} else {
+		status, respBody, err = c.postCached(ctx, body)
+	}
+	if err != nil {
+		return fmt.Errorf("Do: %w", err)
+	}
+
+	// Server responds errors of invalid queries with status other than 200, which are decoded if possible.
+	data, err := decodeResponse(respBody)
+	var respErr *ResponseError
+	if status != http.StatusOK && !errors.As(err, &respErr) {
+		return fmt.Errorf("Do: %w", &StatusError{StatusCode: status, Body: string(respBody)})
+	}
+	if err != nil {
+		return err
+	}
+	// Results cached before a successful mutation of this client may be stale.
+	if mutation && c.cache != nil {
+		if err := c.cache.clear(); err != nil {
+			return fmt.Errorf("Do: %v", err)
+		}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("Do: unmarshal data of response failed: %v", err)
+	}
+	return nil
+}
+
+// isMutation checks whether operation *operationName* of GraphQL *query* is a mutation,
+// the only operation of *query* is checked if *operationName* is "".
+// Queries that cannot be parsed or whose operation cannot be selected are treated as mutations,
+// so that they are neither cached nor retried.
+func isMutation(query string, operationName string) bool {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return true
+	}
+	var op *ast.OperationDefinition
+	if operationName != "" {
+		op = doc.Operations.ForName(operationName)
+	} else if len(doc.Operations) == 1 {
+		op = doc.Operations[0]
+	}
+	return op == nil || op.Operation != ast.Query
+}
+
+// post sends GraphQL request *body* to catalog server by POST, with If-None-Match header if *etag* is not "".
+// Request of *mutation* is only retried if server did not process it.
+// It returns response of server with token finally sent, which may be refreshed after status 401.
+func (c *Client) post(ctx context.Context, body []byte, etag string, mutation bool) (*response, error) {
+	return c.sendAuthorized(ctx, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", c.serverURL+queryPath, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		return req, nil
+	}, !mutation)
+}
+
+// authToken returns token sent to server, which is refreshed if it is about to expire or it is *stale* token rejected by server.
+// It returns "" if client has no token.
+func (c *Client) authToken(ctx context.Context, stale string) (string, error) {
+	if c.tokens == nil {
+		return "", nil
+	}
+	return c.tokens.get(ctx, stale)
+}
+
+// sendAuthorized sends request built by *newRequest* with token of client like send.
+// If server rejects the token with status 401, the request is sent once more with a refreshed token.
+func (c *Client) sendAuthorized(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error), idempotent bool) (*response, error) {
+	token, err := c.authToken(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	sendWithToken := func(token string) (*response, error) {
+		resp, err := c.send(ctx, func(ctx context.Context) (*http.Request, error) {
+			req, err := newRequest(ctx)
+			if err != nil {
+				return nil, err
+			}
+			// if Client's token is "", it means no token is given, do not append header.
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			return req, nil
+		}, idempotent)
+		if err != nil {
+			return nil, err
+		}
+		resp.token = token
+		return resp, nil
+	}
+	resp, err := sendWithToken(token)
+	if err != nil || resp.status != http.StatusUnauthorized || c.tokens == nil {
+		return resp, err
+	}
+	if token, err = c.authToken(ctx, token); err != nil {
+		return nil, err
+	}
+	return sendWithToken(token)
 }
 
 // ReadAuthToken takes in *filepath* of token file, reads token and returns token string.
@@ -70,53 +275,22 @@ func ReadAuthToken(filepath string) (string, error) {
 // QueryServer first generates a HTTP request that can be used to query catalog server.
 // It then sends formatted query request (*req*) to catalog server and returns string of body if no errors encountered.
 // It takes in query string, example query looks like: HOST_ADDR/query?query=GRAPHQL_QUERY.
-func (c *Client) QueryServer(query string) (string, error) {
-	req, err := http.NewRequest("GET", query, nil)
+// A *StatusError is returned if server does not respond status 200 after retries.
+func (c *Client) QueryServer(ctx context.Context, query string) (string, error) {
+	u, err := url.Parse(query)
 	if err != nil {
-		return "", fmt.Errorf("Format new HTTP request failed: %v", err)
-	}
-	// if Client's token is "", it means no token is given, do not append header.
-	if c.token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.token)
+		return "", fmt.Errorf("QueryServer: parse URL failed: %v", err)
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	params := u.Query()
+	resp, err := c.sendAuthorized(ctx, func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, "GET", query, nil)
+	}, !isMutation(params.Get("query"), params.Get("operationName")))
 	if err != nil {
-		return "", fmt.Errorf("Send formatted query to server failed: %v", err)
+		return "", fmt.Errorf("QueryServer: %w", err)
 	}
 	// Check whether status code is OK.
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("Query does not receive Status OK 200, status code: %d", resp.StatusCode)
-	}
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("Read response body failed: %v", err)
-	}
-	return string(body), nil
-}
-
-// ParseModule parses query results into slice of ygot go structs of Module.
-// To use this function, graphQL query should always include raw JSON data field.
-// It takes in two parameters:
-// * resp: query response string in json format.
-// * queryName: name of query users want to extract response from as GraphQL supports composing multiple queries into one request.
-func ParseModule(resp string, queryName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
-	var moduleMap map[string]interface{}
-	if err := json.Unmarshal([]byte(resp), &moduleMap); err != nil {
-		return nil, fmt.Errorf("Unmarshal response into json failed: %v", err)
-	}
-	// Retrive *dataField* field from response json string.
-	// Then we retrive responses from filed of *queryName*.
-	dataJSON := moduleMap[dataField].(map[string]interface{})[queryName].([]interface{})
-	var modules []oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module
-	for i := 0; i < len(dataJSON); i++ {
-		// Retrieve json string of Module from *JSONFieldName* field.
-		moduleJson := dataJSON[i].(map[string]interface{})[JSONFieldName].(string)
-		module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
-		if err := oc.Unmarshal([]byte(moduleJson), module); err != nil {
-			return nil, fmt.Errorf("Cannot unmarshal JSON: %v", err)
-		}
-		modules = append(modules, *module)
+	if resp.status != http.StatusOK {
+		return "", fmt.Errorf("QueryServer: %w", &StatusError{StatusCode: resp.status, Body: string(resp.body)})
 	}
-	return modules, nil
+	return string(resp.body), nil
 }
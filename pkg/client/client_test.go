@@ -16,6 +16,7 @@ package client
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -26,7 +27,7 @@ import (
 
 const (
 	// File name of testdata of mocked response template, server could set queryName inside this response.
-	testdataFile = `testdata/testdata`
+	testdataFile = `testdata/module-data`
 )
 
 // ReadTestData reads testdata from file and strips away invalid "\n" from test file.
@@ -82,6 +83,7 @@ func SetupHTTPTestServer() (*httptest.Server, error) {
 // TestQuery serves as a function to test all three functions inside client pkg,
 // including: (1) FormatHTTPQuery, (2) QueryServer, and (3) ParseModule.
 func TestQuery(t *testing.T) {
+	ctx := context.Background()
 	ts, err := SetupHTTPTestServer()
 	if err != nil {
 		t.Errorf("Set up HTTP server failed: %v", err)
@@ -115,7 +117,7 @@ func TestQuery(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(fmt.Sprintf("TestQueryServer %s", tc.desc), func(t *testing.T) {
-			resp, err := c.QueryServer(ts.URL + "/query?query=" + tc.query)
+			resp, err := c.QueryServer(ctx, ts.URL+"/query?query="+tc.query)
 			if err != nil {
 				t.Errorf("Send query to server failed: %v", err)
 			}
@@ -134,3 +136,60 @@ func TestQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestIsMutation(t *testing.T) {
+	tests := []struct {
+		desc          string
+		query         string
+		operationName string
+		want          bool
+	}{
+		{
+			desc:  "anonymous query",
+			query: `{ModulesByOrgName(OrgName:"openconfig"){Data}}`,
+			want:  false,
+		},
+		{
+			desc:  "mutation",
+			query: `mutation { DeleteModule(Input: {OrgName: "openconfig"}) }`,
+			want:  true,
+		},
+		{
+			desc:  "mutation after comment",
+			query: "# delete module\nmutation { DeleteModule(Input: {OrgName: \"openconfig\"}) }",
+			want:  true,
+		},
+		{
+			desc:  "query whose name starts with mutation",
+			query: `query mutationHistory { ModulesByOrgName(OrgName: "openconfig") { Data } }`,
+			want:  false,
+		},
+		{
+			desc:          "selected operation is query",
+			query:         `mutation M { DeleteModule(Input: {}) } query Q { ModulesByOrgName(OrgName: "openconfig") { Data } }`,
+			operationName: "Q",
+			want:          false,
+		},
+		{
+			desc:          "selected operation is mutation",
+			query:         `query Q { ModulesByOrgName(OrgName: "openconfig") { Data } } mutation M { DeleteModule(Input: {}) }`,
+			operationName: "M",
+			want:          true,
+		},
+		{
+			desc:  "operation is not selected",
+			query: `query Q { ModulesByOrgName(OrgName: "openconfig") { Data } } mutation M { DeleteModule(Input: {}) }`,
+			want:  true,
+		},
+		{
+			desc:  "invalid query",
+			query: `{ModulesByOrgName(`,
+			want:  true,
+		},
+	}
+	for _, tc := range tests {
+		if got := isMutation(tc.query, tc.operationName); got != tc.want {
+			t.Errorf("%s: isMutation(%q, %q) = %v, want: %v", tc.desc, tc.query, tc.operationName, got, tc.want)
+		}
+	}
+}
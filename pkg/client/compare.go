@@ -0,0 +1,142 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+// This file contains comparison of modules and feature bundles of two catalogs, e.g., while migrating
+// catalog to another server, and Mirror sending each query to two catalogs and reporting their differences.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/openconfig/catalog-server/pkg/catalogdiff"
+)
+
+// Kinds of compared entries.
+const (
+	KindModule        = catalogdiff.KindModule
+	KindFeatureBundle = catalogdiff.KindFeatureBundle
+)
+
+// Status of compared entries.
+const (
+	OnlyInPrimary   = `onlyInPrimary`   // Entry only exists in primary catalog.
+	OnlyInSecondary = `onlyInSecondary` // Entry only exists in secondary catalog.
+	Changed         = `changed`         // Entry exists in both catalogs with different fields.
+)
+
+// FieldDiff is a leaf of module or feature bundle whose values differ between two catalogs.
+type FieldDiff struct {
+	Path      string `json:"path" yaml:"path"`                               // Path of leaf in module or feature bundle, e.g., `/summary`.
+	Primary   string `json:"primary,omitempty" yaml:"primary,omitempty"`     // Value in primary catalog, "" if it is not set.
+	Secondary string `json:"secondary,omitempty" yaml:"secondary,omitempty"` // Value in secondary catalog, "" if it is not set.
+}
+
+// Difference is a module or feature bundle which differs between two catalogs.
+type Difference struct {
+	Kind    string      `json:"kind" yaml:"kind"` // KindModule or KindFeatureBundle.
+	OrgName string      `json:"orgName" yaml:"orgName"`
+	Name    string      `json:"name" yaml:"name"`
+	Version string      `json:"version" yaml:"version"`
+	Status  string      `json:"status" yaml:"status"`                     // OnlyInPrimary, OnlyInSecondary or Changed.
+	Fields  []FieldDiff `json:"fields,omitempty" yaml:"fields,omitempty"` // Differing leaves if Status is Changed.
+}
+
+// toDiffEntries converts *entries* into entries compared by catalogdiff.
+func toDiffEntries(entries []Entry) []catalogdiff.Entry {
+	res := []catalogdiff.Entry{}
+	for _, entry := range entries {
+		res = append(res, catalogdiff.Entry{OrgName: entry.OrgName, Data: entry.Data})
+	}
+	return res
+}
+
+// CompareEntries compares *primary* and *secondary* entries of *kind*, which are matched by organization, name and version.
+// Differences are sorted by organization, name and version.
+func CompareEntries(kind string, primary []Entry, secondary []Entry) ([]Difference, error) {
+	changes, err := catalogdiff.DiffEntries(kind, toDiffEntries(primary), toDiffEntries(secondary), false)
+	if err != nil {
+		return nil, fmt.Errorf("CompareEntries: %v", err)
+	}
+	diffs := []Difference{}
+	for _, c := range changes {
+		d := Difference{Kind: kind, OrgName: c.OrgName, Name: c.Name, Version: c.FromVersion, Status: Changed}
+		switch c.Status {
+		case catalogdiff.Removed:
+			d.Status = OnlyInPrimary
+		case catalogdiff.Added:
+			d.Version, d.Status = c.ToVersion, OnlyInSecondary
+		}
+		for _, f := range c.Fields {
+			d.Fields = append(d.Fields, FieldDiff{Path: f.Path, Primary: f.From, Secondary: f.To})
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// listEntries lists entries of *kind* in organization *orgName* of *catalog*, or of all organizations if *orgName* is "".
+func listEntries(ctx context.Context, catalog Catalog, kind string, orgName string) ([]Entry, error) {
+	if kind == KindModule {
+		return catalog.ListModuleEntries(ctx, orgName)
+	}
+	return catalog.ListFeatureBundleEntries(ctx, orgName)
+}
+
+// CompareOrg compares modules and feature bundles of organization *orgName* in *primary* and *secondary* catalogs,
+// or of all organizations if *orgName* is "".
+func CompareOrg(ctx context.Context, primary Catalog, secondary Catalog, orgName string) ([]Difference, error) {
+	diffs := []Difference{}
+	for _, kind := range []string{KindModule, KindFeatureBundle} {
+		p, err := listEntries(ctx, primary, kind, orgName)
+		if err != nil {
+			return nil, fmt.Errorf("CompareOrg: primary: %w", err)
+		}
+		s, err := listEntries(ctx, secondary, kind, orgName)
+		if err != nil {
+			return nil, fmt.Errorf("CompareOrg: secondary: %w", err)
+		}
+		d, err := CompareEntries(kind, p, s)
+		if err != nil {
+			return nil, fmt.Errorf("CompareOrg: %v", err)
+		}
+		diffs = append(diffs, d...)
+	}
+	return diffs, nil
+}
+
+// OrgNames returns sorted names of organizations having modules or feature bundles in any of *catalogs*,
+// e.g., to compare catalogs org by org with CompareOrg.
+func OrgNames(ctx context.Context, catalogs ...Catalog) ([]string, error) {
+	names := map[string]bool{}
+	for _, catalog := range catalogs {
+		for _, kind := range []string{KindModule, KindFeatureBundle} {
+			entries, err := listEntries(ctx, catalog, kind, "")
+			if err != nil {
+				return nil, fmt.Errorf("OrgNames: %w", err)
+			}
+			for _, entry := range entries {
+				names[entry.OrgName] = true
+			}
+		}
+	}
+	res := []string{}
+	for name := range names {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+	"github.com/openconfig/ygot/ygot"
+)
+
+// newSummarizedModuleEntry returns entry of module *name* of *version* in organization *orgName* with *summary*.
+func newSummarizedModuleEntry(t *testing.T, orgName string, name string, version string, summary string) Entry {
+	module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{Name: ygot.String(name), Version: ygot.String(version)}
+	if summary != "" {
+		module.Summary = ygot.String(summary)
+	}
+	data, err := toJSON(module)
+	if err != nil {
+		t.Fatalf("marshal module failed: %v", err)
+	}
+	return Entry{OrgName: orgName, Data: data}
+}
+
+func TestCompareEntries(t *testing.T) {
+	tests := []struct {
+		desc      string
+		primary   []Entry
+		secondary []Entry
+		want      []Difference
+	}{
+		{
+			desc:      "same entries",
+			primary:   []Entry{newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0", "interfaces")},
+			secondary: []Entry{newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0", "interfaces")},
+			want:      []Difference{},
+		},
+		{
+			desc: "missing entries",
+			primary: []Entry{
+				newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0", ""),
+				newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "2.0.0", ""),
+			},
+			secondary: []Entry{
+				newSummarizedModuleEntry(t, "ietf", "openconfig-interfaces", "1.0.0", ""),
+				newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "2.0.0", ""),
+			},
+			want: []Difference{
+				{Kind: KindModule, OrgName: "ietf", Name: "openconfig-interfaces", Version: "1.0.0", Status: OnlyInSecondary},
+				{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-interfaces", Version: "1.0.0", Status: OnlyInPrimary},
+			},
+		},
+		{
+			desc:      "changed fields",
+			primary:   []Entry{newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0", "interfaces")},
+			secondary: []Entry{newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0", "")},
+			want: []Difference{{
+				Kind: KindModule, OrgName: "openconfig", Name: "openconfig-interfaces", Version: "1.0.0", Status: Changed,
+				Fields: []FieldDiff{{Path: "/summary", Primary: "interfaces"}},
+			}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := CompareEntries(KindModule, tc.primary, tc.secondary)
+			if err != nil {
+				t.Fatalf("CompareEntries failed: %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("CompareEntries mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMirror(t *testing.T) {
+	ctx := context.Background()
+	primary := &Snapshot{Modules: []Entry{
+		newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0", "interfaces"),
+		newSummarizedModuleEntry(t, "openconfig", "openconfig-bgp", "1.0.0", "bgp"),
+	}}
+	secondary := &Snapshot{Modules: []Entry{
+		newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0", "interfaces"),
+		newSummarizedModuleEntry(t, "openconfig", "openconfig-bgp", "1.0.0", "BGP"),
+	}}
+	var reported []string
+	m := &Mirror{Primary: primary, Secondary: secondary, Report: func(query string, diffs []Difference, err error) {
+		reported = append(reported, query)
+	}}
+
+	modules, err := m.ListModules(ctx, "openconfig")
+	if err != nil || len(modules) != 2 {
+		t.Errorf("ListModules should return 2 modules of primary, got: %v, err: %v", modules, err)
+	}
+	module, err := m.GetModule(ctx, "openconfig", "openconfig-bgp", "1.0.0")
+	if err != nil || module.GetSummary() != "bgp" {
+		t.Errorf("GetModule should return module of primary, got: %v, err: %v", module, err)
+	}
+	if _, err := m.GetModule(ctx, "openconfig", "openconfig-interfaces", "1.0.0"); err != nil {
+		t.Errorf("GetModule failed: %v", err)
+	}
+	if _, err := m.GetModule(ctx, "ietf", "openconfig-bgp", "1.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetModule of missing module should return ErrNotFound, got: %v", err)
+	}
+	// Only queries whose results differ are reported.
+	want := []string{`ListModules("openconfig")`, `GetModule("openconfig", "openconfig-bgp", "1.0.0")`}
+	if diff := cmp.Diff(want, reported); diff != "" {
+		t.Errorf("reported queries mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// failingCatalog is a Snapshot whose listing of modules fails.
+type failingCatalog struct {
+	Snapshot
+}
+
+func (c *failingCatalog) ListModuleEntries(ctx context.Context, orgName string) ([]Entry, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestMirrorFailingSecondary(t *testing.T) {
+	ctx := context.Background()
+	primary := &Snapshot{Modules: []Entry{newSummarizedModuleEntry(t, "openconfig", "openconfig-bgp", "1.0.0", "bgp")}}
+
+	// Failure of Secondary is ignored without Report.
+	m := &Mirror{Primary: primary, Secondary: &failingCatalog{}}
+	if entries, err := m.ListModuleEntries(ctx, "openconfig"); err != nil || len(entries) != 1 {
+		t.Errorf("ListModuleEntries should return entries of primary, got: %v, err: %v", entries, err)
+	}
+
+	var reported error
+	m.Report = func(query string, diffs []Difference, err error) { reported = err }
+	if _, err := m.ListModuleEntries(ctx, "openconfig"); err != nil {
+		t.Errorf("ListModuleEntries failed: %v", err)
+	}
+	if reported == nil {
+		t.Errorf("failure of secondary should be reported")
+	}
+	// Failure of Primary fails the query.
+	m = &Mirror{Primary: &failingCatalog{}, Secondary: primary}
+	if _, err := m.ListModuleEntries(ctx, "openconfig"); err == nil {
+		t.Errorf("ListModuleEntries should fail when primary fails")
+	}
+}
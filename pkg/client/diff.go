@@ -0,0 +1,73 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openconfig/catalog-server/pkg/catalogdiff"
+	"github.com/openconfig/catalog-server/pkg/client/gqlclient"
+)
+
+// Diff returns changes of modules and feature bundles from set *from* to set *to* computed by catalog server.
+// A set is either a release feature bundle in catalog, or modules and feature bundles in JSON.
+// Entries are matched by organization and name if *matchByName* is true, or by organization, name and version otherwise.
+func (c *Client) Diff(ctx context.Context, from gqlclient.CatalogSet, to gqlclient.CatalogSet, matchByName bool) ([]catalogdiff.Change, error) {
+	resp, err := gqlclient.CatalogDiff(ctx, c, from, to, matchByName)
+	if err != nil {
+		return nil, fmt.Errorf("Diff: %w", err)
+	}
+	changes := []catalogdiff.Change{}
+	for _, change := range resp.CatalogDiff {
+		res := catalogdiff.Change{
+			Kind:        change.Kind,
+			OrgName:     change.OrgName,
+			Name:        change.Name,
+			FromVersion: change.FromVersion,
+			ToVersion:   change.ToVersion,
+			Status:      change.Status,
+		}
+		for _, f := range change.Fields {
+			res.Fields = append(res.Fields, catalogdiff.FieldDiff{Path: f.Path, From: f.From, To: f.To})
+		}
+		changes = append(changes, res)
+	}
+	return changes, nil
+}
+
+// DiffReleases returns changes from release feature bundle *name* of *fromVersion* to its *toVersion* in organization *orgName*,
+// e.g., what changed between two OpenConfig releases. Included feature bundles and modules in dependency closure of releases
+// are matched by name, so that version changes are reported as changed fields.
+func (c *Client) DiffReleases(ctx context.Context, orgName string, name string, fromVersion string, toVersion string) ([]catalogdiff.Change, error) {
+	from := gqlclient.CatalogSet{FeatureBundle: &gqlclient.FeatureBundleKey{OrgName: orgName, Name: name, Version: fromVersion}}
+	to := gqlclient.CatalogSet{FeatureBundle: &gqlclient.FeatureBundleKey{OrgName: orgName, Name: name, Version: toVersion}}
+	return c.Diff(ctx, from, to, true)
+}
+
+// DiffSnapshots returns changes from snapshot *from* to snapshot *to* without catalog server.
+// Entries are matched by organization and name if *matchByName* is true, or by organization, name and version otherwise.
+func DiffSnapshots(from *Snapshot, to *Snapshot, matchByName bool) ([]catalogdiff.Change, error) {
+	changes, err := catalogdiff.Diff(snapshotSet(from), snapshotSet(to), matchByName)
+	if err != nil {
+		return nil, fmt.Errorf("DiffSnapshots: %v", err)
+	}
+	return changes, nil
+}
+
+// snapshotSet returns entries of snapshot *s* compared by catalogdiff.
+func snapshotSet(s *Snapshot) catalogdiff.Set {
+	return catalogdiff.Set{Modules: toDiffEntries(s.Modules), FeatureBundles: toDiffEntries(s.FeatureBundles)}
+}
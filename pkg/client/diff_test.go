@@ -0,0 +1,72 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/catalog-server/pkg/catalogdiff"
+)
+
+func TestDiffReleases(t *testing.T) {
+	changes := []catalogdiff.Change{
+		{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-aft", ToVersion: "1.0.0", Status: catalogdiff.Added},
+		{Kind: KindModule, OrgName: "openconfig", Name: "openconfig-interfaces", FromVersion: "2.4.3", ToVersion: "3.0.0", Status: catalogdiff.Changed,
+			Fields: []catalogdiff.FieldDiff{{Path: "/version", From: "2.4.3", To: "3.0.0"}}},
+	}
+	catalog := &fakeCatalog{changes: changes}
+	ts := httptest.NewServer(catalog)
+	defer ts.Close()
+	c, err := NewClient("", WithServerURL(ts.URL+"/"))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	got, err := c.DiffReleases(context.Background(), "openconfig", "release", "2023-06", "2023-12")
+	if err != nil {
+		t.Fatalf("DiffReleases failed: %v", err)
+	}
+	if diff := cmp.Diff(changes, got); diff != "" {
+		t.Errorf("DiffReleases mismatch (-want +got):\n%s", diff)
+	}
+	// Releases are given by feature bundle keys, whose entries are matched by name.
+	wantVars := map[string]interface{}{
+		"From":        map[string]interface{}{"FeatureBundle": map[string]interface{}{"OrgName": "openconfig", "Name": "release", "Version": "2023-06"}},
+		"To":          map[string]interface{}{"FeatureBundle": map[string]interface{}{"OrgName": "openconfig", "Name": "release", "Version": "2023-12"}},
+		"MatchByName": true,
+	}
+	if diff := cmp.Diff(wantVars, catalog.requests[0].Variables); diff != "" {
+		t.Errorf("DiffReleases variables mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	from := &Snapshot{Modules: []Entry{newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "2.4.3", "interfaces")}}
+	to := &Snapshot{Modules: []Entry{newSummarizedModuleEntry(t, "openconfig", "openconfig-interfaces", "3.0.0", "interfaces")}}
+	got, err := DiffSnapshots(from, to, true)
+	if err != nil {
+		t.Fatalf("DiffSnapshots failed: %v", err)
+	}
+	want := []catalogdiff.Change{{
+		Kind: KindModule, OrgName: "openconfig", Name: "openconfig-interfaces", FromVersion: "2.4.3", ToVersion: "3.0.0", Status: catalogdiff.Changed,
+		Fields: []catalogdiff.FieldDiff{{Path: "/version", From: "2.4.3", To: "3.0.0"}},
+	}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DiffSnapshots mismatch (-want +got):\n%s", diff)
+	}
+}
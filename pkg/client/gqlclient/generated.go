@@ -0,0 +1,311 @@
+// Code generated by scripts/clientgen, DO NOT EDIT.
+
+package gqlclient
+
+import "context"
+
+// Doer sends GraphQL *query* with *variables* and decodes data of response into *out*,
+// e.g., Client of package github.com/openconfig/catalog-server/pkg/client.
+type Doer interface {
+	Do(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error
+}
+
+// ModulesByOrgNameOperation is GraphQL query ModulesByOrgName defined in operations.graphql.
+const ModulesByOrgNameOperation = `query ModulesByOrgName ($OrgName: String) {
+	ModulesByOrgName(OrgName: $OrgName) {
+		OrgName
+		Data
+	}
+}`
+
+// ModulesByOrgName sends GraphQL query ModulesByOrgName by *client*.
+func ModulesByOrgName(ctx context.Context, client Doer, orgName *string) (*ModulesByOrgNameResponse, error) {
+	var resp ModulesByOrgNameResponse
+	if err := client.Do(ctx, ModulesByOrgNameOperation, map[string]interface{}{
+		"OrgName": orgName,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ModulesByKeyOperation is GraphQL query ModulesByKey defined in operations.graphql.
+const ModulesByKeyOperation = `query ModulesByKey ($Name: String, $Version: String) {
+	ModulesByKey(Name: $Name, Version: $Version) {
+		OrgName
+		Data
+	}
+}`
+
+// ModulesByKey sends GraphQL query ModulesByKey by *client*.
+func ModulesByKey(ctx context.Context, client Doer, name *string, version *string) (*ModulesByKeyResponse, error) {
+	var resp ModulesByKeyResponse
+	if err := client.Do(ctx, ModulesByKeyOperation, map[string]interface{}{
+		"Name":    name,
+		"Version": version,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FeatureBundlesByOrgNameOperation is GraphQL query FeatureBundlesByOrgName defined in operations.graphql.
+const FeatureBundlesByOrgNameOperation = `query FeatureBundlesByOrgName ($OrgName: String) {
+	FeatureBundlesByOrgName(OrgName: $OrgName) {
+		OrgName
+		Data
+	}
+}`
+
+// FeatureBundlesByOrgName sends GraphQL query FeatureBundlesByOrgName by *client*.
+func FeatureBundlesByOrgName(ctx context.Context, client Doer, orgName *string) (*FeatureBundlesByOrgNameResponse, error) {
+	var resp FeatureBundlesByOrgNameResponse
+	if err := client.Do(ctx, FeatureBundlesByOrgNameOperation, map[string]interface{}{
+		"OrgName": orgName,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FeatureBundlesByKeyOperation is GraphQL query FeatureBundlesByKey defined in operations.graphql.
+const FeatureBundlesByKeyOperation = `query FeatureBundlesByKey ($Name: String, $Version: String) {
+	FeatureBundlesByKey(Name: $Name, Version: $Version) {
+		OrgName
+		Data
+	}
+}`
+
+// FeatureBundlesByKey sends GraphQL query FeatureBundlesByKey by *client*.
+func FeatureBundlesByKey(ctx context.Context, client Doer, name *string, version *string) (*FeatureBundlesByKeyResponse, error) {
+	var resp FeatureBundlesByKeyResponse
+	if err := client.Do(ctx, FeatureBundlesByKeyOperation, map[string]interface{}{
+		"Name":    name,
+		"Version": version,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateModuleOperation is GraphQL mutation CreateModule defined in operations.graphql.
+const CreateModuleOperation = `mutation CreateModule ($Input: NewModule!) {
+	CreateModule(Input: $Input)
+}`
+
+// CreateModule sends GraphQL mutation CreateModule by *client*.
+func CreateModule(ctx context.Context, client Doer, input NewModule) (*CreateModuleResponse, error) {
+	var resp CreateModuleResponse
+	if err := client.Do(ctx, CreateModuleOperation, map[string]interface{}{
+		"Input": input,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteModuleOperation is GraphQL mutation DeleteModule defined in operations.graphql.
+const DeleteModuleOperation = `mutation DeleteModule ($Input: ModuleKey!) {
+	DeleteModule(Input: $Input)
+}`
+
+// DeleteModule sends GraphQL mutation DeleteModule by *client*.
+func DeleteModule(ctx context.Context, client Doer, input ModuleKey) (*DeleteModuleResponse, error) {
+	var resp DeleteModuleResponse
+	if err := client.Do(ctx, DeleteModuleOperation, map[string]interface{}{
+		"Input": input,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateFeatureBundleOperation is GraphQL mutation CreateFeatureBundle defined in operations.graphql.
+const CreateFeatureBundleOperation = `mutation CreateFeatureBundle ($Input: NewFeatureBundle!) {
+	CreateFeatureBundle(Input: $Input)
+}`
+
+// CreateFeatureBundle sends GraphQL mutation CreateFeatureBundle by *client*.
+func CreateFeatureBundle(ctx context.Context, client Doer, input NewFeatureBundle) (*CreateFeatureBundleResponse, error) {
+	var resp CreateFeatureBundleResponse
+	if err := client.Do(ctx, CreateFeatureBundleOperation, map[string]interface{}{
+		"Input": input,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DeleteFeatureBundleOperation is GraphQL mutation DeleteFeatureBundle defined in operations.graphql.
+const DeleteFeatureBundleOperation = `mutation DeleteFeatureBundle ($Input: FeatureBundleKey!) {
+	DeleteFeatureBundle(Input: $Input)
+}`
+
+// DeleteFeatureBundle sends GraphQL mutation DeleteFeatureBundle by *client*.
+func DeleteFeatureBundle(ctx context.Context, client Doer, input FeatureBundleKey) (*DeleteFeatureBundleResponse, error) {
+	var resp DeleteFeatureBundleResponse
+	if err := client.Do(ctx, DeleteFeatureBundleOperation, map[string]interface{}{
+		"Input": input,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CatalogDiffOperation is GraphQL query CatalogDiff defined in operations.graphql.
+const CatalogDiffOperation = `query CatalogDiff ($From: CatalogSet!, $To: CatalogSet!, $MatchByName: Boolean!) {
+	CatalogDiff(From: $From, To: $To, MatchByName: $MatchByName) {
+		Kind
+		OrgName
+		Name
+		FromVersion
+		ToVersion
+		Status
+		Fields {
+			Path
+			From
+			To
+		}
+	}
+}`
+
+// CatalogDiff sends GraphQL query CatalogDiff by *client*.
+func CatalogDiff(ctx context.Context, client Doer, from CatalogSet, to CatalogSet, matchByName bool) (*CatalogDiffResponse, error) {
+	var resp CatalogDiffResponse
+	if err := client.Do(ctx, CatalogDiffOperation, map[string]interface{}{
+		"From":        from,
+		"To":          to,
+		"MatchByName": matchByName,
+	}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ModulesByOrgNameModulesByOrgName is selection ModulesByOrgName of ModulesByOrgNameResponse.
+type ModulesByOrgNameModulesByOrgName struct {
+	OrgName string `json:"OrgName"`
+	Data    string `json:"Data"`
+}
+
+// ModulesByOrgNameResponse is data of response of query ModulesByOrgName.
+type ModulesByOrgNameResponse struct {
+	ModulesByOrgName []ModulesByOrgNameModulesByOrgName `json:"ModulesByOrgName"`
+}
+
+// ModulesByKeyModulesByKey is selection ModulesByKey of ModulesByKeyResponse.
+type ModulesByKeyModulesByKey struct {
+	OrgName string `json:"OrgName"`
+	Data    string `json:"Data"`
+}
+
+// ModulesByKeyResponse is data of response of query ModulesByKey.
+type ModulesByKeyResponse struct {
+	ModulesByKey []ModulesByKeyModulesByKey `json:"ModulesByKey"`
+}
+
+// FeatureBundlesByOrgNameFeatureBundlesByOrgName is selection FeatureBundlesByOrgName of FeatureBundlesByOrgNameResponse.
+type FeatureBundlesByOrgNameFeatureBundlesByOrgName struct {
+	OrgName string `json:"OrgName"`
+	Data    string `json:"Data"`
+}
+
+// FeatureBundlesByOrgNameResponse is data of response of query FeatureBundlesByOrgName.
+type FeatureBundlesByOrgNameResponse struct {
+	FeatureBundlesByOrgName []FeatureBundlesByOrgNameFeatureBundlesByOrgName `json:"FeatureBundlesByOrgName"`
+}
+
+// FeatureBundlesByKeyFeatureBundlesByKey is selection FeatureBundlesByKey of FeatureBundlesByKeyResponse.
+type FeatureBundlesByKeyFeatureBundlesByKey struct {
+	OrgName string `json:"OrgName"`
+	Data    string `json:"Data"`
+}
+
+// FeatureBundlesByKeyResponse is data of response of query FeatureBundlesByKey.
+type FeatureBundlesByKeyResponse struct {
+	FeatureBundlesByKey []FeatureBundlesByKeyFeatureBundlesByKey `json:"FeatureBundlesByKey"`
+}
+
+// CreateModuleResponse is data of response of mutation CreateModule.
+type CreateModuleResponse struct {
+	CreateModule string `json:"CreateModule"`
+}
+
+// DeleteModuleResponse is data of response of mutation DeleteModule.
+type DeleteModuleResponse struct {
+	DeleteModule string `json:"DeleteModule"`
+}
+
+// CreateFeatureBundleResponse is data of response of mutation CreateFeatureBundle.
+type CreateFeatureBundleResponse struct {
+	CreateFeatureBundle string `json:"CreateFeatureBundle"`
+}
+
+// DeleteFeatureBundleResponse is data of response of mutation DeleteFeatureBundle.
+type DeleteFeatureBundleResponse struct {
+	DeleteFeatureBundle string `json:"DeleteFeatureBundle"`
+}
+
+// CatalogDiffCatalogDiffFields is selection Fields of CatalogDiffCatalogDiff.
+type CatalogDiffCatalogDiffFields struct {
+	Path string `json:"Path"`
+	From string `json:"From"`
+	To   string `json:"To"`
+}
+
+// CatalogDiffCatalogDiff is selection CatalogDiff of CatalogDiffResponse.
+type CatalogDiffCatalogDiff struct {
+	Kind        string                         `json:"Kind"`
+	OrgName     string                         `json:"OrgName"`
+	Name        string                         `json:"Name"`
+	FromVersion string                         `json:"FromVersion"`
+	ToVersion   string                         `json:"ToVersion"`
+	Status      string                         `json:"Status"`
+	Fields      []CatalogDiffCatalogDiffFields `json:"Fields"`
+}
+
+// CatalogDiffResponse is data of response of query CatalogDiff.
+type CatalogDiffResponse struct {
+	CatalogDiff []CatalogDiffCatalogDiff `json:"CatalogDiff"`
+}
+
+// CatalogEntry is GraphQL input type CatalogEntry.
+type CatalogEntry struct {
+	OrgName string `json:"OrgName"`
+	Data    string `json:"Data"`
+}
+
+// CatalogSet is GraphQL input type CatalogSet.
+type CatalogSet struct {
+	FeatureBundle  *FeatureBundleKey `json:"FeatureBundle,omitempty"`
+	Modules        []CatalogEntry    `json:"Modules,omitempty"`
+	FeatureBundles []CatalogEntry    `json:"FeatureBundles,omitempty"`
+}
+
+// FeatureBundleKey is GraphQL input type FeatureBundleKey.
+type FeatureBundleKey struct {
+	OrgName string `json:"OrgName"`
+	Name    string `json:"Name"`
+	Version string `json:"Version"`
+}
+
+// ModuleKey is GraphQL input type ModuleKey.
+type ModuleKey struct {
+	OrgName string `json:"OrgName"`
+	Name    string `json:"Name"`
+	Version string `json:"Version"`
+}
+
+// NewFeatureBundle is GraphQL input type NewFeatureBundle.
+type NewFeatureBundle struct {
+	OrgName string `json:"OrgName"`
+	Data    string `json:"Data"`
+}
+
+// NewModule is GraphQL input type NewModule.
+type NewModule struct {
+	OrgName          string   `json:"OrgName"`
+	Data             string   `json:"Data"`
+	Source           *string  `json:"Source,omitempty"`
+	SubmoduleSources []string `json:"SubmoduleSources,omitempty"`
+}
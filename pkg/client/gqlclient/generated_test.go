@@ -0,0 +1,56 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gqlclient
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/catalog-server/pkg/clientgen"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// TestGeneratedUpToDate checks that generated.go is generated from current schema and operations,
+// run `go generate` in this directory if it fails.
+func TestGeneratedUpToDate(t *testing.T) {
+	load := func(pattern string) []*ast.Source {
+		files, err := filepath.Glob(pattern)
+		if err != nil || len(files) == 0 {
+			t.Fatalf("no files match %s: %v", pattern, err)
+		}
+		var sources []*ast.Source
+		for _, file := range files {
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatalf("read %s failed: %v", file, err)
+			}
+			sources = append(sources, &ast.Source{Name: filepath.Base(file), Input: string(data)})
+		}
+		return sources
+	}
+	want, err := clientgen.Generate(load("../../../graph/*.graphqls"), load("*.graphql"), "gqlclient", "scripts/clientgen")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	got, err := ioutil.ReadFile("generated.go")
+	if err != nil {
+		t.Fatalf("read generated.go failed: %v", err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("generated.go is out of date, run go generate (-want +got):\n%s", diff)
+	}
+}
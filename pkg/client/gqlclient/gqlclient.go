@@ -0,0 +1,20 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gqlclient contains typed functions of GraphQL operations in operations.graphql,
+// which are generated from schema of catalog server by scripts/clientgen into generated.go.
+// Use typed methods of package pkg/client, which wrap these functions.
+package gqlclient
+
+//go:generate go run ../../../scripts/clientgen -schema ../../../graph/*.graphqls -operations *.graphql -out generated.go -package gqlclient
@@ -0,0 +1,197 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+)
+
+// Mirror is a Catalog sending each query to both Primary and Secondary catalogs concurrently, e.g., to verify
+// a new catalog server before clients are switched to it. Results of Primary are returned,
+// and differences of results of Secondary are reported.
+type Mirror struct {
+	Primary   Catalog
+	Secondary Catalog
+	// Report is called with description of query and differences of its results when they differ,
+	// or with error of Secondary or of comparison, which does not fail the query.
+	// Differences are not reported if it is nil.
+	Report func(query string, diffs []Difference, err error)
+}
+
+var _ Catalog = (*Mirror)(nil)
+
+// entriesResult is result of getting entries from a catalog.
+type entriesResult struct {
+	entries []Entry
+	err     error
+}
+
+// mirror gets entries of *kind* by *get* from both catalogs concurrently, reports their differences for *query*,
+// and returns entries of Primary.
+func (m *Mirror) mirror(query string, kind string, get func(catalog Catalog) ([]Entry, error)) ([]Entry, error) {
+	// Buffered so that the goroutine does not leak when Primary fails.
+	secondaryResult := make(chan entriesResult, 1)
+	go func() {
+		entries, err := get(m.Secondary)
+		secondaryResult <- entriesResult{entries: entries, err: err}
+	}()
+	primary, err := get(m.Primary)
+	if err != nil {
+		return nil, err
+	}
+	secondary := <-secondaryResult
+	if secondary.err != nil {
+		m.report(query, nil, fmt.Errorf("secondary: %w", secondary.err))
+		return primary, nil
+	}
+	diffs, err := CompareEntries(kind, primary, secondary.entries)
+	if err != nil || len(diffs) > 0 {
+		m.report(query, diffs, err)
+	}
+	return primary, nil
+}
+
+// report calls Report of *m* if it is set.
+func (m *Mirror) report(query string, diffs []Difference, err error) {
+	if m.Report != nil {
+		m.Report(query, diffs, err)
+	}
+}
+
+// fromEntries converts *entries* into entries of query results.
+func fromEntries(entries []Entry) []dataEntry {
+	var res []dataEntry
+	for _, entry := range entries {
+		res = append(res, newDataEntry(entry.OrgName, entry.Data))
+	}
+	return res
+}
+
+// inOrg returns *entries* of organization *orgName*.
+func inOrg(entries []Entry, orgName string) []Entry {
+	res := []Entry{}
+	for _, entry := range entries {
+		if entry.OrgName == orgName {
+			res = append(res, entry)
+		}
+	}
+	return res
+}
+
+// ListModuleEntries returns modules of organization *orgName* in Primary, or of all organizations if *orgName* is "".
+func (m *Mirror) ListModuleEntries(ctx context.Context, orgName string) ([]Entry, error) {
+	return m.mirror(fmt.Sprintf("ListModules(%q)", orgName), KindModule, func(catalog Catalog) ([]Entry, error) {
+		return catalog.ListModuleEntries(ctx, orgName)
+	})
+}
+
+// ListModules returns modules of organization *orgName* in Primary, or of all organizations if *orgName* is "".
+func (m *Mirror) ListModules(ctx context.Context, orgName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	entries, err := m.ListModuleEntries(ctx, orgName)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalModules(fromEntries(entries))
+}
+
+// SearchModuleEntries returns modules of name *name* and version *version* in Primary, empty *name* or *version* matches any.
+func (m *Mirror) SearchModuleEntries(ctx context.Context, name string, version string) ([]Entry, error) {
+	return m.mirror(fmt.Sprintf("SearchModules(%q, %q)", name, version), KindModule, func(catalog Catalog) ([]Entry, error) {
+		return catalog.SearchModuleEntries(ctx, name, version)
+	})
+}
+
+// SearchModules returns modules of name *name* and version *version* in Primary, empty *name* or *version* matches any.
+func (m *Mirror) SearchModules(ctx context.Context, name string, version string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	entries, err := m.SearchModuleEntries(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalModules(fromEntries(entries))
+}
+
+// GetModule returns module of *name* and *version* of organization *orgName* in Primary, ErrNotFound is returned if it does not exist.
+func (m *Mirror) GetModule(ctx context.Context, orgName string, name string, version string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	entries, err := m.mirror(fmt.Sprintf("GetModule(%q, %q, %q)", orgName, name, version), KindModule, func(catalog Catalog) ([]Entry, error) {
+		entries, err := catalog.SearchModuleEntries(ctx, name, version)
+		return inOrg(entries, orgName), err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetModule: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("GetModule: module %s@%s of %s %w", name, version, orgName, ErrNotFound)
+	}
+	modules, err := unmarshalModules(fromEntries(entries[:1]))
+	if err != nil {
+		return nil, fmt.Errorf("GetModule: %w", err)
+	}
+	return &modules[0], nil
+}
+
+// ListFeatureBundleEntries returns feature bundles of organization *orgName* in Primary, or of all organizations if *orgName* is "".
+func (m *Mirror) ListFeatureBundleEntries(ctx context.Context, orgName string) ([]Entry, error) {
+	return m.mirror(fmt.Sprintf("ListFeatureBundles(%q)", orgName), KindFeatureBundle, func(catalog Catalog) ([]Entry, error) {
+		return catalog.ListFeatureBundleEntries(ctx, orgName)
+	})
+}
+
+// ListFeatureBundles returns feature bundles of organization *orgName* in Primary, or of all organizations if *orgName* is "".
+func (m *Mirror) ListFeatureBundles(ctx context.Context, orgName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	entries, err := m.ListFeatureBundleEntries(ctx, orgName)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalFeatureBundles(fromEntries(entries))
+}
+
+// SearchFeatureBundleEntries returns feature bundles of name *name* and version *version* in Primary, empty *name* or *version* matches any.
+func (m *Mirror) SearchFeatureBundleEntries(ctx context.Context, name string, version string) ([]Entry, error) {
+	return m.mirror(fmt.Sprintf("SearchFeatureBundles(%q, %q)", name, version), KindFeatureBundle, func(catalog Catalog) ([]Entry, error) {
+		return catalog.SearchFeatureBundleEntries(ctx, name, version)
+	})
+}
+
+// SearchFeatureBundles returns feature bundles of name *name* and version *version* in Primary, empty *name* or *version* matches any.
+func (m *Mirror) SearchFeatureBundles(ctx context.Context, name string, version string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	entries, err := m.SearchFeatureBundleEntries(ctx, name, version)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalFeatureBundles(fromEntries(entries))
+}
+
+// GetFeatureBundle returns feature bundle of *name* and *version* of organization *orgName* in Primary,
+// ErrNotFound is returned if it does not exist.
+func (m *Mirror) GetFeatureBundle(ctx context.Context, orgName string, name string, version string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	entries, err := m.mirror(fmt.Sprintf("GetFeatureBundle(%q, %q, %q)", orgName, name, version), KindFeatureBundle, func(catalog Catalog) ([]Entry, error) {
+		entries, err := catalog.SearchFeatureBundleEntries(ctx, name, version)
+		return inOrg(entries, orgName), err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetFeatureBundle: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("GetFeatureBundle: feature bundle %s@%s of %s %w", name, version, orgName, ErrNotFound)
+	}
+	featureBundles, err := unmarshalFeatureBundles(fromEntries(entries[:1]))
+	if err != nil {
+		return nil, fmt.Errorf("GetFeatureBundle: %w", err)
+	}
+	return &featureBundles[0], nil
+}
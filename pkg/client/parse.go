@@ -0,0 +1,163 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+// This file contains functions parsing GraphQL responses of catalog server,
+// which decode responses into typed envelopes instead of asserting types of fields.
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openconfig/catalog-server/pkg/validate"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+)
+
+// dataEntry is an entry of query results containing raw JSON data field.
+type dataEntry struct {
+	OrgName string
+	Data    *string
+}
+
+// decodeResponse decodes GraphQL response *resp*, and returns its `data` field.
+// If response contains GraphQL errors, a *ResponseError is returned.
+func decodeResponse(resp []byte) (json.RawMessage, error) {
+	var gqlResp graphQLResponse
+	if err := json.Unmarshal(resp, &gqlResp); err != nil {
+		return nil, fmt.Errorf("unmarshal response into json failed: %v", err)
+	}
+	if len(gqlResp.Errors) > 0 {
+		return nil, &ResponseError{Errors: gqlResp.Errors}
+	}
+	if len(gqlResp.Data) == 0 || string(gqlResp.Data) == "null" {
+		return nil, fmt.Errorf("response does not contain %s field", dataField)
+	}
+	return gqlResp.Data, nil
+}
+
+// decodeResult decodes GraphQL response *resp*, and returns results of query *queryName* in its `data` field.
+func decodeResult(resp []byte, queryName string) (json.RawMessage, error) {
+	data, err := decodeResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	var results map[string]json.RawMessage
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("%s field is not an object: %v", dataField, err)
+	}
+	result, ok := results[queryName]
+	if !ok {
+		return nil, fmt.Errorf("response does not contain results of query %s", queryName)
+	}
+	return result, nil
+}
+
+// decodeEntries decodes *results* of query *queryName* into entries containing raw JSON data field.
+func decodeEntries(results json.RawMessage, queryName string) ([]dataEntry, error) {
+	var entries []dataEntry
+	if err := json.Unmarshal(results, &entries); err != nil {
+		return nil, fmt.Errorf("results of query %s are not a list of entries: %v", queryName, err)
+	}
+	for i, entry := range entries {
+		if entry.Data == nil {
+			return nil, fmt.Errorf("entry %d of query %s does not contain %s field", i, queryName, JSONFieldName)
+		}
+	}
+	return entries, nil
+}
+
+// ParseData decodes results of query *queryName* in response *resp* into *out*, which should be a pointer,
+// e.g., pointer to a slice of structs with fields named by fields of GraphQL types in query.
+// Errors of response are returned as *ResponseError.
+func ParseData(resp string, queryName string, out interface{}) error {
+	result, err := decodeResult([]byte(resp), queryName)
+	if err != nil {
+		return fmt.Errorf("ParseData: %w", err)
+	}
+	if err := json.Unmarshal(result, out); err != nil {
+		return fmt.Errorf("ParseData: decode results of query %s failed: %v", queryName, err)
+	}
+	return nil
+}
+
+// parseEntries decodes results of query *queryName* in response *resp* into entries containing raw JSON data field.
+func parseEntries(resp string, queryName string) ([]dataEntry, error) {
+	result, err := decodeResult([]byte(resp), queryName)
+	if err != nil {
+		return nil, err
+	}
+	return decodeEntries(result, queryName)
+}
+
+// ParseModule parses query results into slice of ygot go structs of Module.
+// To use this function, graphQL query should always include raw JSON data field.
+// It takes in two parameters:
+// * resp: query response string in json format.
+// * queryName: name of query users want to extract response from as GraphQL supports composing multiple queries into one request.
+// Errors of response are returned as *ResponseError.
+func ParseModule(resp string, queryName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	entries, err := parseEntries(resp, queryName)
+	if err != nil {
+		return nil, fmt.Errorf("ParseModule: %w", err)
+	}
+	return unmarshalModules(entries)
+}
+
+// ParseFeatureBundle parses query results into slice of ygot go structs of FeatureBundle.
+// Like ParseModule, graphQL query should include raw JSON data field, and *queryName* is name of query in response.
+// Errors of response are returned as *ResponseError.
+func ParseFeatureBundle(resp string, queryName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	entries, err := parseEntries(resp, queryName)
+	if err != nil {
+		return nil, fmt.Errorf("ParseFeatureBundle: %w", err)
+	}
+	return unmarshalFeatureBundles(entries)
+}
+
+// ParseValidationIssues parses results of validation or lint query *queryName*, e.g., `ValidateModule`, into issues.
+// Errors of response are returned as *ResponseError.
+func ParseValidationIssues(resp string, queryName string) ([]validate.Issue, error) {
+	var issues []validate.Issue
+	if err := ParseData(resp, queryName, &issues); err != nil {
+		return nil, fmt.Errorf("ParseValidationIssues: %w", err)
+	}
+	return issues, nil
+}
+
+// unmarshalModules unmarshals JSON data of *entries* into ygot go structs of Module.
+func unmarshalModules(entries []dataEntry) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	var modules []oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module
+	for _, entry := range entries {
+		module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+		if err := oc.Unmarshal([]byte(*entry.Data), module); err != nil {
+			return nil, fmt.Errorf("Cannot unmarshal JSON: %v", err)
+		}
+		modules = append(modules, *module)
+	}
+	return modules, nil
+}
+
+// unmarshalFeatureBundles unmarshals JSON data of *entries* into ygot go structs of FeatureBundle.
+func unmarshalFeatureBundles(entries []dataEntry) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	var featureBundles []oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle
+	for _, entry := range entries {
+		featureBundle := &oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle{}
+		if err := oc.Unmarshal([]byte(*entry.Data), featureBundle); err != nil {
+			return nil, fmt.Errorf("Cannot unmarshal JSON: %v", err)
+		}
+		featureBundles = append(featureBundles, *featureBundle)
+	}
+	return featureBundles, nil
+}
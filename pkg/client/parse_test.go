@@ -0,0 +1,131 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/catalog-server/pkg/validate"
+)
+
+func TestParseModule(t *testing.T) {
+	respTemplate, err := ReadTestData(testdataFile)
+	if err != nil {
+		t.Fatalf("Read test data failed: %v", err)
+	}
+
+	tests := []struct {
+		desc            string
+		resp            string
+		wantModuleNames []string
+		wantRespErr     bool
+		wantErr         bool
+	}{
+		{
+			desc:            "valid response",
+			resp:            fmt.Sprintf(respTemplate, "ModulesByOrgName"),
+			wantModuleNames: []string{`0`, `1`, `2`},
+		},
+		{
+			desc:            "empty results",
+			resp:            `{"data": {"ModulesByOrgName": []}}`,
+			wantModuleNames: nil,
+		},
+		{
+			desc:        "errors of response",
+			resp:        `{"errors": [{"message": "validate token failed", "path": ["ModulesByOrgName"]}], "data": null}`,
+			wantRespErr: true,
+			wantErr:     true,
+		},
+		{
+			desc:    "results of other query",
+			resp:    fmt.Sprintf(respTemplate, "ModulesByKey"),
+			wantErr: true,
+		},
+		{
+			desc:    "entry without Data field",
+			resp:    `{"data": {"ModulesByOrgName": [{"Name": "0"}]}}`,
+			wantErr: true,
+		},
+		{
+			desc:    "results are not a list",
+			resp:    `{"data": {"ModulesByOrgName": "Fail"}}`,
+			wantErr: true,
+		},
+		{
+			desc:    "response without data",
+			resp:    `{}`,
+			wantErr: true,
+		},
+		{
+			desc:    "invalid json",
+			resp:    `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			modules, err := ParseModule(tc.resp, "ModulesByOrgName")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseModule error mismatch, got: %v, wantErr: %v", err, tc.wantErr)
+			}
+			var respErr *ResponseError
+			if errors.As(err, &respErr) != tc.wantRespErr {
+				t.Errorf("ParseModule should return ResponseError: %v, got: %v", tc.wantRespErr, err)
+			}
+			var names []string
+			for _, module := range modules {
+				names = append(names, module.GetName())
+			}
+			if diff := cmp.Diff(tc.wantModuleNames, names); diff != "" {
+				t.Errorf("ParseModule names mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseFeatureBundle(t *testing.T) {
+	resp := `{"data": {"FeatureBundlesByOrgName": [{"Data": "{\"openconfig-module-catalog:name\": \"routing\", \"openconfig-module-catalog:version\": \"1.0.0\"}"}]}}`
+	featureBundles, err := ParseFeatureBundle(resp, "FeatureBundlesByOrgName")
+	if err != nil {
+		t.Fatalf("ParseFeatureBundle failed: %v", err)
+	}
+	if len(featureBundles) != 1 || featureBundles[0].GetName() != "routing" || featureBundles[0].GetVersion() != "1.0.0" {
+		t.Errorf("ParseFeatureBundle mismatch, got: %v", featureBundles)
+	}
+	if _, err := ParseFeatureBundle(`{"data": {"FeatureBundlesByOrgName": [{"Data": "{\"unknown\": 1}"}]}}`, "FeatureBundlesByOrgName"); err == nil {
+		t.Errorf("ParseFeatureBundle of invalid feature bundle should fail")
+	}
+}
+
+func TestParseValidationIssues(t *testing.T) {
+	resp := `{"data": {"ValidateModule": [{"Path": "/access/uri", "Severity": "error", "Message": "invalid uri", "Rule": ""}]}}`
+	issues, err := ParseValidationIssues(resp, "ValidateModule")
+	if err != nil {
+		t.Fatalf("ParseValidationIssues failed: %v", err)
+	}
+	want := []validate.Issue{{Path: "/access/uri", Severity: "error", Message: "invalid uri"}}
+	if diff := cmp.Diff(want, issues); diff != "" {
+		t.Errorf("ParseValidationIssues mismatch (-want +got):\n%s", diff)
+	}
+	var respErr *ResponseError
+	if _, err := ParseValidationIssues(`{"errors": [{"message": "bad"}]}`, "ValidateModule"); !errors.As(err, &respErr) {
+		t.Errorf("ParseValidationIssues should return ResponseError, got: %v", err)
+	}
+}
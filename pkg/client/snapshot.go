@@ -0,0 +1,337 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+// This file contains Snapshot of whole catalog, which is downloaded from catalog server into a file
+// and answers the same queries as Client locally, e.g., on machines without access to catalog server.
+// Queries of Snapshot never block, their contexts are only accepted to implement Catalog.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+)
+
+// Snapshot is content of catalog visible to a client at a point in time.
+type Snapshot struct {
+	Server         string    `json:"server,omitempty"` // URL of catalog server the snapshot is downloaded from.
+	CreatedAt      time.Time `json:"createdAt"`        // Time when the snapshot is downloaded.
+	Digest         string    `json:"digest"`           // Digest of modules and feature bundles, identifying catalog state.
+	Modules        []Entry   `json:"modules"`
+	FeatureBundles []Entry   `json:"featureBundles"`
+
+	// Modules and feature bundles unmarshalled into ygot go structs, built once on first query,
+	// so that Snapshot can be queried concurrently, e.g., as Secondary of Mirror.
+	indexOnce      sync.Once
+	indexErr       error
+	modules        []snapshotModule
+	featureBundles []snapshotFeatureBundle
+}
+
+type snapshotModule struct {
+	Entry
+	module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module
+}
+
+type snapshotFeatureBundle struct {
+	Entry
+	featureBundle *oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle
+}
+
+// Snapshot downloads all modules and feature bundles visible to token of client.
+func (c *Client) Snapshot(ctx context.Context) (*Snapshot, error) {
+	modules, err := c.ListModuleEntries(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("Snapshot: %w", err)
+	}
+	featureBundles, err := c.ListFeatureBundleEntries(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("Snapshot: %w", err)
+	}
+	s := &Snapshot{
+		Server:         c.serverURL,
+		CreatedAt:      timeNow().UTC(),
+		Modules:        modules,
+		FeatureBundles: featureBundles,
+	}
+	sortEntries(s.Modules)
+	sortEntries(s.FeatureBundles)
+	s.Digest = s.computeDigest()
+	return s, nil
+}
+
+// DownloadSnapshot downloads snapshot of catalog and saves it into file *path*.
+func (c *Client) DownloadSnapshot(ctx context.Context, path string) (*Snapshot, error) {
+	s, err := c.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Save(path); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadSnapshot reads snapshot saved in file *path*, and checks its digest if it is given.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadSnapshot: %v", err)
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("LoadSnapshot: parse %s failed: %v", path, err)
+	}
+	if digest := s.computeDigest(); s.Digest != "" && s.Digest != digest {
+		return nil, fmt.Errorf("LoadSnapshot: digest of %s mismatch, got %s, want %s", path, digest, s.Digest)
+	}
+	return s, nil
+}
+
+// Save writes snapshot into file *path* atomically.
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Save: marshal snapshot failed: %v", err)
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("Save: write %s failed: %v", path, err)
+	}
+	return nil
+}
+
+// sortEntries sorts *entries* by organization and data, so that snapshots of the same catalog are identical.
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].OrgName != entries[j].OrgName {
+			return entries[i].OrgName < entries[j].OrgName
+		}
+		return entries[i].Data < entries[j].Data
+	})
+}
+
+// computeDigest returns hex encoded sha256 hash of modules and feature bundles of snapshot.
+func (s *Snapshot) computeDigest() string {
+	h := sha256.New()
+	for _, entries := range [][]Entry{s.Modules, s.FeatureBundles} {
+		// Lengths are written before values, so that different contents never have the same input of hash.
+		fmt.Fprintf(h, "%d\n", len(entries))
+		for _, entry := range entries {
+			fmt.Fprintf(h, "%d:%s%d:%s", len(entry.OrgName), entry.OrgName, len(entry.Data), entry.Data)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// index unmarshals modules and feature bundles of snapshot into ygot go structs once,
+// and returns error of unmarshalling to all queries.
+func (s *Snapshot) index() error {
+	s.indexOnce.Do(func() { s.indexErr = s.buildIndex() })
+	return s.indexErr
+}
+
+// buildIndex unmarshals modules and feature bundles of snapshot into ygot go structs.
+func (s *Snapshot) buildIndex() error {
+	modules := []snapshotModule{}
+	for _, entry := range s.Modules {
+		module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+		if err := oc.Unmarshal([]byte(entry.Data), module); err != nil {
+			return fmt.Errorf("Cannot unmarshal module of %s: %v", entry.OrgName, err)
+		}
+		modules = append(modules, snapshotModule{Entry: entry, module: module})
+	}
+	featureBundles := []snapshotFeatureBundle{}
+	for _, entry := range s.FeatureBundles {
+		featureBundle := &oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle{}
+		if err := oc.Unmarshal([]byte(entry.Data), featureBundle); err != nil {
+			return fmt.Errorf("Cannot unmarshal feature bundle of %s: %v", entry.OrgName, err)
+		}
+		featureBundles = append(featureBundles, snapshotFeatureBundle{Entry: entry, featureBundle: featureBundle})
+	}
+	s.modules, s.featureBundles = modules, featureBundles
+	return nil
+}
+
+// matches returns whether *value* matches *want*, empty *want* matches any.
+func matches(want string, value string) bool {
+	return want == "" || want == value
+}
+
+// findModules returns modules of snapshot matching *orgName*, *name* and *version*, empty ones match any.
+func (s *Snapshot) findModules(orgName string, name string, version string) ([]snapshotModule, error) {
+	if err := s.index(); err != nil {
+		return nil, err
+	}
+	var res []snapshotModule
+	for _, m := range s.modules {
+		if matches(orgName, m.OrgName) && matches(name, m.module.GetName()) && matches(version, m.module.GetVersion()) {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
+
+// findFeatureBundles returns feature bundles of snapshot matching *orgName*, *name* and *version*, empty ones match any.
+func (s *Snapshot) findFeatureBundles(orgName string, name string, version string) ([]snapshotFeatureBundle, error) {
+	if err := s.index(); err != nil {
+		return nil, err
+	}
+	var res []snapshotFeatureBundle
+	for _, fb := range s.featureBundles {
+		if matches(orgName, fb.OrgName) && matches(name, fb.featureBundle.GetName()) && matches(version, fb.featureBundle.GetVersion()) {
+			res = append(res, fb)
+		}
+	}
+	return res, nil
+}
+
+func moduleStructs(modules []snapshotModule) []oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module {
+	var res []oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module
+	for _, m := range modules {
+		res = append(res, *m.module)
+	}
+	return res
+}
+
+func moduleEntries(modules []snapshotModule) []Entry {
+	res := []Entry{}
+	for _, m := range modules {
+		res = append(res, m.Entry)
+	}
+	return res
+}
+
+func featureBundleStructs(featureBundles []snapshotFeatureBundle) []oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle {
+	var res []oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle
+	for _, fb := range featureBundles {
+		res = append(res, *fb.featureBundle)
+	}
+	return res
+}
+
+func featureBundleEntries(featureBundles []snapshotFeatureBundle) []Entry {
+	res := []Entry{}
+	for _, fb := range featureBundles {
+		res = append(res, fb.Entry)
+	}
+	return res
+}
+
+// ListModules returns modules of organization *orgName* in snapshot, or modules of all organizations if *orgName* is "".
+func (s *Snapshot) ListModules(ctx context.Context, orgName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	modules, err := s.findModules(orgName, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("ListModules: %v", err)
+	}
+	return moduleStructs(modules), nil
+}
+
+// ListModuleEntries returns modules of organization *orgName* in snapshot, or of all organizations if *orgName* is "", in raw JSON data.
+func (s *Snapshot) ListModuleEntries(ctx context.Context, orgName string) ([]Entry, error) {
+	modules, err := s.findModules(orgName, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("ListModuleEntries: %v", err)
+	}
+	return moduleEntries(modules), nil
+}
+
+// SearchModules returns modules of name *name* and version *version* in snapshot, empty *name* or *version* matches any.
+func (s *Snapshot) SearchModules(ctx context.Context, name string, version string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	modules, err := s.findModules("", name, version)
+	if err != nil {
+		return nil, fmt.Errorf("SearchModules: %v", err)
+	}
+	return moduleStructs(modules), nil
+}
+
+// SearchModuleEntries returns modules of name *name* and version *version* in snapshot in raw JSON data,
+// empty *name* or *version* matches any.
+func (s *Snapshot) SearchModuleEntries(ctx context.Context, name string, version string) ([]Entry, error) {
+	modules, err := s.findModules("", name, version)
+	if err != nil {
+		return nil, fmt.Errorf("SearchModuleEntries: %v", err)
+	}
+	return moduleEntries(modules), nil
+}
+
+// GetModule returns module of *name* and *version* of organization *orgName* in snapshot, ErrNotFound is returned if it does not exist.
+func (s *Snapshot) GetModule(ctx context.Context, orgName string, name string, version string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
+	modules, err := s.findModules(orgName, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("GetModule: %v", err)
+	}
+	if orgName == "" || name == "" || version == "" || len(modules) == 0 {
+		return nil, fmt.Errorf("GetModule: module %s@%s of %s %w", name, version, orgName, ErrNotFound)
+	}
+	return modules[0].module, nil
+}
+
+// ListFeatureBundles returns feature bundles of organization *orgName* in snapshot, or of all organizations if *orgName* is "".
+func (s *Snapshot) ListFeatureBundles(ctx context.Context, orgName string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	featureBundles, err := s.findFeatureBundles(orgName, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("ListFeatureBundles: %v", err)
+	}
+	return featureBundleStructs(featureBundles), nil
+}
+
+// ListFeatureBundleEntries returns feature bundles of organization *orgName* in snapshot, or of all organizations if *orgName* is "", in raw JSON data.
+func (s *Snapshot) ListFeatureBundleEntries(ctx context.Context, orgName string) ([]Entry, error) {
+	featureBundles, err := s.findFeatureBundles(orgName, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("ListFeatureBundleEntries: %v", err)
+	}
+	return featureBundleEntries(featureBundles), nil
+}
+
+// SearchFeatureBundles returns feature bundles of name *name* and version *version* in snapshot, empty *name* or *version* matches any.
+func (s *Snapshot) SearchFeatureBundles(ctx context.Context, name string, version string) ([]oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	featureBundles, err := s.findFeatureBundles("", name, version)
+	if err != nil {
+		return nil, fmt.Errorf("SearchFeatureBundles: %v", err)
+	}
+	return featureBundleStructs(featureBundles), nil
+}
+
+// SearchFeatureBundleEntries returns feature bundles of name *name* and version *version* in snapshot in raw JSON data,
+// empty *name* or *version* matches any.
+func (s *Snapshot) SearchFeatureBundleEntries(ctx context.Context, name string, version string) ([]Entry, error) {
+	featureBundles, err := s.findFeatureBundles("", name, version)
+	if err != nil {
+		return nil, fmt.Errorf("SearchFeatureBundleEntries: %v", err)
+	}
+	return featureBundleEntries(featureBundles), nil
+}
+
+// GetFeatureBundle returns feature bundle of *name* and *version* of organization *orgName* in snapshot,
+// ErrNotFound is returned if it does not exist.
+func (s *Snapshot) GetFeatureBundle(ctx context.Context, orgName string, name string, version string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
+	featureBundles, err := s.findFeatureBundles(orgName, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("GetFeatureBundle: %v", err)
+	}
+	if orgName == "" || name == "" || version == "" || len(featureBundles) == 0 {
+		return nil, fmt.Errorf("GetFeatureBundle: feature bundle %s@%s of %s %w", name, version, orgName, ErrNotFound)
+	}
+	return featureBundles[0].featureBundle, nil
+}
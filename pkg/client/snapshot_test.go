@@ -0,0 +1,202 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+	"github.com/openconfig/ygot/ygot"
+)
+
+// newDependentModuleEntry returns entry of module *name* of *version* in organization *orgName* requiring modules *required*.
+func newDependentModuleEntry(t *testing.T, orgName string, name string, version string, required ...string) Entry {
+	module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{Name: ygot.String(name), Version: ygot.String(version)}
+	if len(required) > 0 {
+		module.GetOrCreateDependencies().RequiredModule = required
+	}
+	data, err := toJSON(module)
+	if err != nil {
+		t.Fatalf("marshal module failed: %v", err)
+	}
+	return Entry{OrgName: orgName, Data: data}
+}
+
+func TestDownloadSnapshot(t *testing.T) {
+	ctx := context.Background()
+	catalog := &fakeCatalog{
+		modules: []dataEntry{
+			newModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0"),
+			newModuleEntry(t, "ietf", "ietf-interfaces", "2.0.0"),
+		},
+		featureBundles: []dataEntry{newFeatureBundleEntry(t, "openconfig", "routing", "1.0.0")},
+	}
+	ts := httptest.NewServer(catalog)
+	defer ts.Close()
+	c, err := NewClient("", WithServerURL(ts.URL+"/"))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	now := time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	downloaded, err := c.DownloadSnapshot(ctx, path)
+	if err != nil {
+		t.Fatalf("DownloadSnapshot failed: %v", err)
+	}
+	if downloaded.CreatedAt != now || downloaded.Digest == "" {
+		t.Errorf("DownloadSnapshot returned invalid metadata, got: %v, %s", downloaded.CreatedAt, downloaded.Digest)
+	}
+	// Entries are sorted by organization so that the same catalog always has the same snapshot.
+	if len(downloaded.Modules) != 2 || downloaded.Modules[0].OrgName != "ietf" {
+		t.Errorf("DownloadSnapshot returned unsorted modules: %v", downloaded.Modules)
+	}
+
+	loaded, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	if diff := cmp.Diff(downloaded, loaded, cmpopts.IgnoreUnexported(Snapshot{})); diff != "" {
+		t.Errorf("LoadSnapshot mismatch (-want +got):\n%s", diff)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read snapshot failed: %v", err)
+	}
+	tampered := filepath.Join(t.TempDir(), "tampered.json")
+	if err := ioutil.WriteFile(tampered, []byte(strings.Replace(string(data), "2.0.0", "2.0.1", 1)), 0644); err != nil {
+		t.Fatalf("write snapshot failed: %v", err)
+	}
+	if _, err := LoadSnapshot(tampered); err == nil || !strings.Contains(err.Error(), "digest") {
+		t.Errorf("LoadSnapshot of modified snapshot should fail by digest, got: %v", err)
+	}
+}
+
+func TestSnapshotQueries(t *testing.T) {
+	ctx := context.Background()
+	featureBundle := newFeatureBundleEntry(t, "openconfig", "routing", "1.0.0")
+	s := &Snapshot{
+		Modules: []Entry{
+			newDependentModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0"),
+			newDependentModuleEntry(t, "openconfig", "openconfig-interfaces", "2.0.0"),
+			newDependentModuleEntry(t, "ietf", "ietf-interfaces", "2.0.0"),
+		},
+		FeatureBundles: []Entry{{OrgName: featureBundle.OrgName, Data: *featureBundle.Data}},
+	}
+
+	modules, err := s.ListModules(ctx, "openconfig")
+	if err != nil || len(modules) != 2 {
+		t.Errorf("ListModules of openconfig should return 2 modules, got: %v, err: %v", modules, err)
+	}
+	entries, err := s.SearchModuleEntries(ctx, "", "2.0.0")
+	if err != nil || len(entries) != 2 {
+		t.Errorf("SearchModuleEntries of version 2.0.0 should return 2 modules, got: %v, err: %v", entries, err)
+	}
+	module, err := s.GetModule(ctx, "openconfig", "openconfig-interfaces", "2.0.0")
+	if err != nil || module.GetVersion() != "2.0.0" {
+		t.Errorf("GetModule mismatch, got: %v, err: %v", module, err)
+	}
+	if _, err := s.GetModule(ctx, "ietf", "openconfig-interfaces", "2.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetModule of other organization should not be found, got: %v", err)
+	}
+	featureBundles, err := s.SearchFeatureBundles(ctx, "routing", "")
+	if err != nil || len(featureBundles) != 1 {
+		t.Errorf("SearchFeatureBundles should return routing, got: %v, err: %v", featureBundles, err)
+	}
+	if _, err := s.GetFeatureBundle(ctx, "openconfig", "routing", "2.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetFeatureBundle of other version should not be found, got: %v", err)
+	}
+
+	invalid := &Snapshot{Modules: []Entry{{OrgName: "openconfig", Data: `{"unknown": 1}`}}}
+	if _, err := invalid.ListModules(ctx, ""); err == nil {
+		t.Errorf("ListModules of invalid snapshot should fail")
+	}
+}
+
+// TestSnapshotConcurrentQueries is meant to be run with -race, queries of a shared Snapshot should not race on its index.
+func TestSnapshotConcurrentQueries(t *testing.T) {
+	ctx := context.Background()
+	s := &Snapshot{Modules: []Entry{
+		newDependentModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0"),
+		newDependentModuleEntry(t, "ietf", "ietf-interfaces", "2.0.0"),
+	}}
+	invalid := &Snapshot{Modules: []Entry{{OrgName: "openconfig", Data: `{"unknown": 1}`}}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if modules, err := s.ListModules(ctx, ""); err != nil || len(modules) != 2 {
+				t.Errorf("ListModules should return 2 modules, got: %v, err: %v", modules, err)
+			}
+			if _, err := invalid.ListModules(ctx, ""); err == nil {
+				t.Errorf("ListModules of invalid snapshot should fail")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestResolveDependencies(t *testing.T) {
+	ctx := context.Background()
+	s := &Snapshot{
+		Modules: []Entry{
+			newDependentModuleEntry(t, "openconfig", "openconfig-interfaces", "1.0.0", "openconfig-types", "ietf-interfaces"),
+			newDependentModuleEntry(t, "openconfig", "openconfig-types", "0.1.0", "openconfig-extensions"),
+			newDependentModuleEntry(t, "openconfig", "openconfig-types", "0.10.0", "openconfig-extensions"),
+			newDependentModuleEntry(t, "vendor", "openconfig-types", "9.0.0"),
+			newDependentModuleEntry(t, "openconfig", "openconfig-extensions", "1.0.0", "openconfig-interfaces"),
+			newDependentModuleEntry(t, "ietf", "ietf-interfaces", "1.0.0", "ietf-yang-types"),
+		},
+	}
+	deps, unresolved, err := ResolveDependencies(ctx, s, "openconfig", "openconfig-interfaces", "1.0.0")
+	if err != nil {
+		t.Fatalf("ResolveDependencies failed: %v", err)
+	}
+	var got []string
+	for _, dep := range deps {
+		got = append(got, dep.OrgName+"/"+dep.Module.GetName()+"@"+dep.Module.GetVersion())
+	}
+	want := []string{
+		"ietf/ietf-interfaces@1.0.0",
+		"openconfig/openconfig-extensions@1.0.0",
+		"openconfig/openconfig-interfaces@1.0.0",
+		"openconfig/openconfig-types@0.10.0",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ResolveDependencies mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"ietf-yang-types"}, unresolved); diff != "" {
+		t.Errorf("ResolveDependencies unresolved mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, err := ResolveDependencies(ctx, s, "openconfig", "openconfig-bgp", "1.0.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ResolveDependencies of missing module should return ErrNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+// This file contains token sources of Client, which provide tokens sent as bearer tokens
+// and refresh them before they expire, e.g., identity tokens of gcloud expiring after an hour.
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// refreshBefore is how long before expiry a token is refreshed, so that it does not expire during requests.
+const refreshBefore = 5 * time.Minute
+
+// Token is a token sent as bearer token to catalog server.
+type Token struct {
+	Value  string    // Value of token.
+	Expiry time.Time // Time when token expires, zero if it is unknown.
+}
+
+// TokenSource provides tokens of Client, it is asked for a new token when the current one is about to expire,
+// or when server rejects the current one with status 401 (Unauthorized).
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc adapts a function into TokenSource.
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token calls *f*.
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// newToken returns Token of *value* with surrounding spaces trimmed, whose expiry is read from it if it is a JWT.
+func newToken(value string) *Token {
+	value = strings.TrimSpace(value)
+	return &Token{Value: value, Expiry: jwtExpiry(value)}
+}
+
+// jwtClaims are claims in payload of a JWT used by client.
+type jwtClaims struct {
+	Exp int64  `json:"exp"`
+	Iss string `json:"iss"`
+	Sub string `json:"sub"`
+}
+
+// parseJWT returns claims of *token*, nil is returned if it is not a JWT.
+// Signature of token is not verified, claims are only used to manage token and cached results.
+func parseJWT(token string) *jwtClaims {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil
+	}
+	claims := &jwtClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil
+	}
+	return claims
+}
+
+// jwtExpiry returns expiry in `exp` claim of *token* if it is a JWT, or zero time otherwise.
+func jwtExpiry(token string) time.Time {
+	claims := parseJWT(token)
+	if claims == nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// tokenIdentity returns identity of user of *token*, which does not change when token is refreshed.
+// It is issuer and subject of token if it is a JWT, or token itself otherwise.
+func tokenIdentity(token string) string {
+	if claims := parseJWT(token); claims != nil && claims.Sub != "" {
+		return "jwt:" + claims.Iss + "\x00" + claims.Sub
+	}
+	return "token:" + token
+}
+
+// StaticTokenSource returns source of constant *token*.
+func StaticTokenSource(token string) TokenSource {
+	t := newToken(token)
+	return TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		return t, nil
+	})
+}
+
+// FileTokenSource returns source of token read from file *path*, the file is read again on every refresh,
+// so that it can be rewritten by other programs.
+func FileTokenSource(path string) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		token, err := ReadAuthToken(path)
+		if err != nil {
+			return nil, err
+		}
+		return newToken(token), nil
+	})
+}
+
+// EnvTokenSource returns source of token in environment variable *name*, which is looked up on every refresh.
+func EnvTokenSource(name string) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		token, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+		return newToken(token), nil
+	})
+}
+
+// CommandTokenSource returns source of token printed by command *name* with *args*, which is run on every refresh,
+// e.g., `gcloud auth print-identity-token`.
+func CommandTokenSource(name string, args ...string) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("run %s failed: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+		}
+		return newToken(string(out)), nil
+	})
+}
+
+// OAuth2TokenSource adapts *ts* into TokenSource, e.g., identity token source of `google.golang.org/api/idtoken`.
+// Access token of tokens of *ts* is sent.
+func OAuth2TokenSource(ts oauth2.TokenSource) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		t, err := ts.Token()
+		if err != nil {
+			return nil, err
+		}
+		return &Token{Value: t.AccessToken, Expiry: t.Expiry}, nil
+	})
+}
+
+// WithTokenSource sets *source* of tokens, which overrides token read from file by NewClient.
+func WithTokenSource(source TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = &cachedTokenSource{source: source}
+	}
+}
+
+// cachedTokenSource caches token of source until it is about to expire.
+type cachedTokenSource struct {
+	source TokenSource
+	mu     sync.Mutex
+	token  *Token
+}
+
+// get returns cached token, or a new token from source if the cached one is about to expire,
+// or if *stale* is the value of cached token rejected by server.
+func (s *cachedTokenSource) get(ctx context.Context, stale string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != nil && (stale == "" || s.token.Value != stale) &&
+		(s.token.Expiry.IsZero() || timeNow().Add(refreshBefore).Before(s.token.Expiry)) {
+		return s.token.Value, nil
+	}
+	token, err := s.source.Token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get token failed: %v", err)
+	}
+	s.token = token
+	return token.Value, nil
+}
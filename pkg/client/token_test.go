@@ -0,0 +1,176 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+)
+
+// newJWT returns an unsigned JWT expiring at *expiry*.
+func newJWT(expiry time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, expiry.Unix())))
+	return "eyJhbGciOiJub25lIn0." + payload + ".sig"
+}
+
+func TestTokenSources(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Unix(1627776000, 0)
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte(newJWT(expiry)+"\n"), 0600); err != nil {
+		t.Fatalf("write token file failed: %v", err)
+	}
+	os.Setenv("CATALOG_TEST_TOKEN", " env-token ")
+	defer os.Unsetenv("CATALOG_TEST_TOKEN")
+
+	tests := []struct {
+		desc    string
+		source  TokenSource
+		want    *Token
+		wantErr bool
+	}{
+		{
+			desc:   "static token",
+			source: StaticTokenSource("static-token\n"),
+			want:   &Token{Value: "static-token"},
+		},
+		{
+			desc:   "file containing JWT",
+			source: FileTokenSource(tokenFile),
+			want:   &Token{Value: newJWT(expiry), Expiry: expiry},
+		},
+		{
+			desc:    "missing file",
+			source:  FileTokenSource(filepath.Join(dir, "missing")),
+			wantErr: true,
+		},
+		{
+			desc:   "environment variable",
+			source: EnvTokenSource("CATALOG_TEST_TOKEN"),
+			want:   &Token{Value: "env-token"},
+		},
+		{
+			desc:    "unset environment variable",
+			source:  EnvTokenSource("CATALOG_TEST_UNSET_TOKEN"),
+			wantErr: true,
+		},
+		{
+			desc:   "command",
+			source: CommandTokenSource("echo", "command-token"),
+			want:   &Token{Value: "command-token"},
+		},
+		{
+			desc:    "failed command",
+			source:  CommandTokenSource("false"),
+			wantErr: true,
+		},
+		{
+			desc:   "oauth2 token source",
+			source: OAuth2TokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "oauth2-token", Expiry: expiry})),
+			want:   &Token{Value: "oauth2-token", Expiry: expiry},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := tc.source.Token(ctx)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Token returned error %v, wantErr: %v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Token mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCachedTokenSource(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	calls := 0
+	s := &cachedTokenSource{source: TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		calls++
+		return &Token{Value: fmt.Sprintf("token-%d", calls), Expiry: now.Add(time.Hour)}, nil
+	})}
+
+	get := func(desc string, stale string, want string) {
+		t.Helper()
+		got, err := s.get(ctx, stale)
+		if err != nil {
+			t.Fatalf("%s: get failed: %v", desc, err)
+		}
+		if got != want {
+			t.Errorf("%s: get returned %s, want %s", desc, got, want)
+		}
+	}
+	get("first token", "", "token-1")
+	get("cached token", "", "token-1")
+	get("token refreshed by another request", "token-0", "token-1")
+	get("rejected token", "token-1", "token-2")
+	now = now.Add(56 * time.Minute)
+	get("token about to expire", "", "token-3")
+}
+
+func TestRetryUnauthorized(t *testing.T) {
+	ctx := context.Background()
+	var authorizations []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data": {}}`))
+	}))
+	defer ts.Close()
+
+	calls := 0
+	source := TokenSourceFunc(func(ctx context.Context) (*Token, error) {
+		calls++
+		return &Token{Value: fmt.Sprintf("token-%d", calls)}, nil
+	})
+	c, err := NewClient("", WithServerURL(ts.URL), WithTokenSource(source))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if err := c.Do(ctx, `mutation { DeleteModule(Input: {}) }`, nil, nil); err != nil {
+		t.Errorf("Do should succeed with refreshed token, got: %v", err)
+	}
+	// Requests rejected again with refreshed token fail without further refresh.
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	if _, err := c.QueryServer(ctx, ts.URL+"/query?query={}"); err == nil {
+		t.Errorf("QueryServer with rejected token should fail")
+	}
+	want := []string{"Bearer token-1", "Bearer token-2", "Bearer token-2", "Bearer token-3"}
+	if diff := cmp.Diff(want, authorizations); diff != "" {
+		t.Errorf("sent tokens mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+// This file contains transport of Client, which sends HTTP requests with timeouts,
+// and retries them with exponential backoff on transient failures.
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultTimeout is timeout of each attempt of requests, unless it is set by WithTimeout.
+const DefaultTimeout = 30 * time.Second
+
+// RetryPolicy configures retries of requests failed by network errors, status 429 (Too Many Requests) or 5xx.
+// Mutations are only retried on 429 and 503 (Service Unavailable), which are not processed by server,
+// since retrying other failures may apply mutations twice.
+type RetryPolicy struct {
+	MaxRetries     int           // Maximum number of retries after the first attempt, 0 disables retries.
+	InitialBackoff time.Duration // Backoff before the first retry, which doubles for each further retry.
+	MaxBackoff     time.Duration // Maximum backoff between attempts, Retry-After of responses is also limited by it.
+}
+
+// DefaultRetryPolicy is used unless retries are configured by WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 3, InitialBackoff: 200 * time.Millisecond, MaxBackoff: 10 * time.Second}
+
+// sleep waits between attempts, it is replaced in tests.
+var sleep = sleepContext
+
+// sleepContext waits for *d* or until *ctx* is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WithHTTPClient sets *httpClient* used to send requests, e.g., to customize its transport.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout sets *timeout* of each attempt of requests, 0 disables timeout besides deadline of context of calls.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetryPolicy sets *policy* of retries of failed requests.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// HTTPClient returns underlying HTTP client of Client, whose transport can be customized.
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+// StatusError is returned when server responds with status other than 200 and no GraphQL errors.
+type StatusError struct {
+	StatusCode int    // Status code of response.
+	Body       string // Body of response.
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("query does not receive Status OK 200, status code: %d", e.StatusCode)
+}
+
+// response is a response of server whose body is already read.
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+	token  string // Token sent with the request, set by sendAuthorized.
+}
+
+// send sends request built by *newRequest* until it succeeds or retries are exhausted.
+// Each attempt is limited by timeout of client, and backoff between attempts stops when *ctx* is done.
+// Only requests of *idempotent* operations are retried on all transient failures.
+func (c *Client) send(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error), idempotent bool) (*response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, networkErr, err := c.attempt(ctx, newRequest)
+		if err == nil && !retriable(resp.status, idempotent) {
+			return resp, nil
+		}
+		if err != nil && (!networkErr || !idempotent || ctx.Err() != nil) {
+			return nil, err
+		}
+		if attempt >= c.retry.MaxRetries {
+			if err != nil {
+				return nil, fmt.Errorf("%w, gave up after %d attempts", err, attempt+1)
+			}
+			return resp, nil
+		}
+		var header http.Header
+		if resp != nil {
+			header = resp.header
+		}
+		if err := sleep(ctx, c.backoff(attempt, header)); err != nil {
+			return nil, fmt.Errorf("wait for retry failed: %v", err)
+		}
+	}
+}
+
+// attempt sends request built by *newRequest* once, and reads its response.
+// If it fails, whether it is caused by network is also returned.
+func (c *Client) attempt(ctx context.Context, newRequest func(ctx context.Context) (*http.Request, error)) (*response, bool, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	req, err := newRequest(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("format new HTTP request failed: %v", err)
+	}
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("send request to server failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("read response body failed: %w", err)
+	}
+	return &response{status: resp.StatusCode, header: resp.Header, body: body}, false, nil
+}
+
+// retriable checks whether request failed with *status* can be retried, requests of operations
+// that are not *idempotent* are only retried if server did not process them.
+func retriable(status int, idempotent bool) bool {
+	switch {
+	case status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable:
+		return true
+	case status >= 500:
+		return idempotent
+	}
+	return false
+}
+
+// backoff returns time to wait before retry after *attempt*, which is Retry-After in *header* if it is given,
+// or exponential backoff with jitter otherwise.
+func (c *Client) backoff(attempt int, header http.Header) time.Duration {
+	max := c.retry.MaxBackoff
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds >= 0 {
+		if d := time.Duration(seconds) * time.Second; max <= 0 || d < max {
+			return d
+		}
+		return max
+	}
+	d := c.retry.InitialBackoff
+	for i := 0; i < attempt && (max <= 0 || d < max); i++ {
+		d *= 2
+	}
+	if max > 0 && d > max {
+		d = max
+	}
+	// Jitter spreads retries of concurrent clients, waiting between half and whole of backoff.
+	if d > 1 {
+		d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	}
+	return d
+}
@@ -0,0 +1,215 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// countingTransport counts requests sent through it.
+type countingTransport struct {
+	count int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.count++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc         string
+		query        string
+		statuses     []int // Statuses responded by server in order, the last one is repeated.
+		retryAfter   string
+		wantRequests int
+		wantSleeps   []time.Duration
+		wantStatus   int // Status of StatusError expected, 0 if query should succeed.
+	}{
+		{
+			desc:         "query retried on 5xx until success",
+			query:        `{ ModulesByOrgName { Data } }`,
+			statuses:     []int{http.StatusBadGateway, http.StatusInternalServerError, http.StatusOK},
+			wantRequests: 3,
+			wantSleeps:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			desc:         "retries exhausted",
+			query:        `{ ModulesByOrgName { Data } }`,
+			statuses:     []int{http.StatusServiceUnavailable},
+			wantRequests: 4,
+			wantSleeps:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond},
+			wantStatus:   http.StatusServiceUnavailable,
+		},
+		{
+			desc:         "retry after given by server",
+			query:        `{ ModulesByOrgName { Data } }`,
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:   "2",
+			wantRequests: 2,
+			wantSleeps:   []time.Duration{2 * time.Second},
+		},
+		{
+			desc:         "mutation not retried on 500",
+			query:        `mutation { DeleteModule(Input: {}) }`,
+			statuses:     []int{http.StatusInternalServerError, http.StatusOK},
+			wantRequests: 1,
+			wantStatus:   http.StatusInternalServerError,
+		},
+		{
+			desc:         "mutation retried on 503",
+			query:        `mutation { DeleteModule(Input: {}) }`,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantRequests: 2,
+			wantSleeps:   []time.Duration{100 * time.Millisecond},
+		},
+		{
+			desc:         "client error not retried",
+			query:        `{ ModulesByOrgName { Data } }`,
+			statuses:     []int{http.StatusForbidden},
+			wantRequests: 1,
+			wantStatus:   http.StatusForbidden,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			requests := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tc.statuses[len(tc.statuses)-1]
+				if requests < len(tc.statuses) {
+					status = tc.statuses[requests]
+				}
+				requests++
+				if tc.retryAfter != "" {
+					w.Header().Set("Retry-After", tc.retryAfter)
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(`{"data": {}}`))
+			}))
+			defer ts.Close()
+			var sleeps []time.Duration
+			sleep = func(ctx context.Context, d time.Duration) error {
+				sleeps = append(sleeps, d)
+				return nil
+			}
+			defer func() { sleep = sleepContext }()
+			// Backoff without jitter is 200ms, 400ms and 800ms, and jitter waits at least half of them.
+			c, err := NewClient("", WithServerURL(ts.URL), WithRetryPolicy(RetryPolicy{MaxRetries: 3, InitialBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}))
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			err = c.Do(ctx, tc.query, nil, nil)
+			var statusErr *StatusError
+			switch {
+			case tc.wantStatus == 0 && err != nil:
+				t.Errorf("Do failed: %v", err)
+			case tc.wantStatus != 0 && (!errors.As(err, &statusErr) || statusErr.StatusCode != tc.wantStatus):
+				t.Errorf("Do should return StatusError of %d, got: %v", tc.wantStatus, err)
+			}
+			if requests != tc.wantRequests {
+				t.Errorf("server received %d requests, want %d", requests, tc.wantRequests)
+			}
+			if len(sleeps) != len(tc.wantSleeps) {
+				t.Fatalf("Do waited %v, want at least %v", sleeps, tc.wantSleeps)
+			}
+			for i, d := range sleeps {
+				if d < tc.wantSleeps[i] || d > 2*tc.wantSleeps[i] {
+					t.Errorf("backoff %d is %v, want between %v and %v", i, d, tc.wantSleeps[i], 2*tc.wantSleeps[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNetworkErrors(t *testing.T) {
+	ctx := context.Background()
+	sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	defer func() { sleep = sleepContext }()
+
+	// Requests to closed server fail by network errors, which are retried for queries only.
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+	transport := &countingTransport{}
+	c, err := NewClient("", WithServerURL(ts.URL), WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if c.HTTPClient().Transport != transport {
+		t.Errorf("HTTPClient should return client set by WithHTTPClient")
+	}
+	if err := c.Do(ctx, `{ ModulesByOrgName { Data } }`, nil, nil); err == nil {
+		t.Errorf("Do to closed server should fail")
+	}
+	if transport.count != DefaultRetryPolicy.MaxRetries+1 {
+		t.Errorf("query should be sent %d times, got: %d", DefaultRetryPolicy.MaxRetries+1, transport.count)
+	}
+	transport.count = 0
+	if err := c.Do(ctx, `mutation { DeleteModule(Input: {}) }`, nil, nil); err == nil {
+		t.Errorf("Do to closed server should fail")
+	}
+	if transport.count != 1 {
+		t.Errorf("mutation should be sent once, got: %d", transport.count)
+	}
+}
+
+func TestTimeoutAndCancel(t *testing.T) {
+	block := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(block)
+
+	var sleeps []time.Duration
+	sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	defer func() { sleep = sleepContext }()
+	c, err := NewClient("", WithServerURL(ts.URL), WithTimeout(10*time.Millisecond), WithRetryPolicy(RetryPolicy{MaxRetries: 1}))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	// Each attempt times out, and query is retried once.
+	if err := c.Do(context.Background(), `{ ModulesByOrgName { Data } }`, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do should time out, got: %v", err)
+	}
+	if diff := cmp.Diff([]time.Duration{0}, sleeps); diff != "" {
+		t.Errorf("Do should retry once after timeout (-want +got):\n%s", diff)
+	}
+
+	// Canceled context is not retried.
+	sleeps = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Do(ctx, `{ ModulesByOrgName { Data } }`, nil, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Do with canceled context should fail, got: %v", err)
+	}
+	if len(sleeps) != 0 {
+		t.Errorf("Do with canceled context should not retry, got: %v", sleeps)
+	}
+}
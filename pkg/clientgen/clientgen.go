@@ -0,0 +1,297 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clientgen generates typed Go client of catalog server from GraphQL operations.
+// Operations are validated against schema of catalog server, and each of them is generated into
+// a function with typed variables and response, so that changes of schema break compilation of clients
+// instead of failing at runtime. See scripts/clientgen for the command running it by `go generate`.
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+)
+
+// scalars maps built-in GraphQL scalars to Go types.
+var scalars = map[string]string{
+	"String":  "string",
+	"ID":      "string",
+	"Int":     "int",
+	"Float":   "float64",
+	"Boolean": "bool",
+}
+
+// generator keeps state while generating a Go file.
+type generator struct {
+	schema *ast.Schema
+	ops    bytes.Buffer    // Generated code of operations.
+	types  bytes.Buffer    // Generated types of selections of operations.
+	names  map[string]bool // Names of generated declarations, which should be unique.
+	inputs map[string]bool // Names of input types used by variables of operations.
+}
+
+// Generate generates source of Go package *pkg* containing a function for each operation in *operations*,
+// validated against *schema*. *generatedBy* is name of generator written in header of the file.
+func Generate(schema []*ast.Source, operations []*ast.Source, pkg string, generatedBy string) ([]byte, error) {
+	s, loadErr := gqlparser.LoadSchema(schema...)
+	if loadErr != nil {
+		return nil, fmt.Errorf("Generate: load schema failed: %v", loadErr)
+	}
+	g := &generator{schema: s, names: map[string]bool{}, inputs: map[string]bool{}}
+	for _, source := range operations {
+		doc, errs := gqlparser.LoadQuery(s, source.Input)
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("Generate: invalid operations in %s: %v", source.Name, errs)
+		}
+		if len(doc.Fragments) > 0 {
+			return nil, fmt.Errorf("Generate: fragments in %s are not supported", source.Name)
+		}
+		for _, op := range doc.Operations {
+			if err := g.operation(op, source.Name); err != nil {
+				return nil, fmt.Errorf("Generate: %v", err)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by %s, DO NOT EDIT.\n\n", generatedBy)
+	fmt.Fprintf(&out, "package %s\n\nimport \"context\"\n\n", pkg)
+	out.WriteString("// Doer sends GraphQL *query* with *variables* and decodes data of response into *out*,\n")
+	out.WriteString("// e.g., Client of package github.com/openconfig/catalog-server/pkg/client.\n")
+	out.WriteString("type Doer interface {\n\tDo(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error\n}\n\n")
+	out.Write(g.ops.Bytes())
+	out.Write(g.types.Bytes())
+	if err := g.inputTypes(&out); err != nil {
+		return nil, fmt.Errorf("Generate: %v", err)
+	}
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Generate: format generated code failed: %v", err)
+	}
+	return src, nil
+}
+
+// exported returns exported Go identifier of GraphQL *name*.
+func exported(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// unexported returns unexported Go identifier of GraphQL *name*, which is not a Go keyword.
+func unexported(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	if token.Lookup(string(r)).IsKeyword() {
+		return string(r) + "Arg"
+	}
+	return string(r)
+}
+
+// declare reserves name of declaration *name*, which conflicts if it is already declared.
+func (g *generator) declare(name string) error {
+	if g.names[name] {
+		return fmt.Errorf("generated name %s is declared twice", name)
+	}
+	g.names[name] = true
+	return nil
+}
+
+// operation generates constant of operation *op* defined in *file*, types of its response, and function sending it.
+func (g *generator) operation(op *ast.OperationDefinition, file string) error {
+	if op.Name == "" {
+		return fmt.Errorf("operation in %s has no name", file)
+	}
+	name := exported(op.Name)
+	for _, decl := range []string{name, name + "Operation", name + "Response"} {
+		if err := g.declare(decl); err != nil {
+			return fmt.Errorf("operation %s: %v", op.Name, err)
+		}
+	}
+
+	var text bytes.Buffer
+	formatter.NewFormatter(&text).FormatQueryDocument(&ast.QueryDocument{Operations: ast.OperationList{op}})
+	query := strings.TrimSpace(text.String())
+	quoted := "`" + query + "`"
+	if strings.Contains(query, "`") {
+		quoted = strconv.Quote(query)
+	}
+	fmt.Fprintf(&g.ops, "// %sOperation is GraphQL %s %s defined in %s.\n", name, op.Operation, op.Name, file)
+	fmt.Fprintf(&g.ops, "const %sOperation = %s\n\n", name, quoted)
+
+	var params, vars []string
+	for _, v := range op.VariableDefinitions {
+		goType, err := g.inputType(v.Type)
+		if err != nil {
+			return fmt.Errorf("variable %s of operation %s: %v", v.Variable, op.Name, err)
+		}
+		params = append(params, fmt.Sprintf("%s %s", unexported(v.Variable), goType))
+		vars = append(vars, fmt.Sprintf("%q: %s,", v.Variable, unexported(v.Variable)))
+	}
+	variables := "nil"
+	if len(vars) > 0 {
+		variables = "map[string]interface{}{\n" + strings.Join(vars, "\n") + "\n}"
+	}
+	fmt.Fprintf(&g.ops, "// %s sends GraphQL %s %s by *client*.\n", name, op.Operation, op.Name)
+	fmt.Fprintf(&g.ops, "func %s(ctx context.Context, client Doer", name)
+	for _, param := range params {
+		fmt.Fprintf(&g.ops, ", %s", param)
+	}
+	fmt.Fprintf(&g.ops, ") (*%sResponse, error) {\n", name)
+	fmt.Fprintf(&g.ops, "var resp %sResponse\n", name)
+	fmt.Fprintf(&g.ops, "if err := client.Do(ctx, %sOperation, %s, &resp); err != nil {\nreturn nil, err\n}\n", name, variables)
+	fmt.Fprintf(&g.ops, "return &resp, nil\n}\n\n")
+
+	return g.selectionType(name+"Response", fmt.Sprintf("data of response of %s %s", op.Operation, op.Name), name, op.SelectionSet)
+}
+
+// selectionType generates struct *typeName* of fields selected by *selections*, and types of their subselections
+// named with prefix *prefix*. *desc* describes the struct in its comment.
+func (g *generator) selectionType(typeName string, desc string, prefix string, selections ast.SelectionSet) error {
+	var fields bytes.Buffer
+	keys := map[string]bool{}
+	for _, selection := range selections {
+		field, ok := selection.(*ast.Field)
+		if !ok {
+			return fmt.Errorf("%s: only fields can be selected, fragments are not supported", typeName)
+		}
+		key := field.Alias
+		if key == "" {
+			key = field.Name
+		}
+		// Validation ensures that fields of the same response key are merged into the same selection.
+		if keys[key] {
+			continue
+		}
+		keys[key] = true
+		goType, err := g.outputType(field.Definition.Type, func(named string) (string, error) {
+			if goType, ok := scalars[named]; ok {
+				return goType, nil
+			}
+			def := g.schema.Types[named]
+			if def == nil {
+				return "", fmt.Errorf("unknown type %s", named)
+			}
+			switch def.Kind {
+			case ast.Enum:
+				return "string", nil
+			case ast.Object:
+				nested := prefix + exported(key)
+				if err := g.declare(nested); err != nil {
+					return "", err
+				}
+				return nested, g.selectionType(nested, fmt.Sprintf("selection %s of %s", key, typeName), nested, field.SelectionSet)
+			}
+			return "", fmt.Errorf("field %s of %s type %s is not supported", key, def.Kind, named)
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&fields, "%s %s `json:%q`\n", exported(key), goType, key)
+	}
+	fmt.Fprintf(&g.types, "// %s is %s.\n", typeName, desc)
+	fmt.Fprintf(&g.types, "type %s struct {\n%s}\n\n", typeName, fields.String())
+	return nil
+}
+
+// outputType returns Go type of GraphQL type *t* of response, whose named type is converted by *named*.
+// Nullable named types are pointers, and lists are slices.
+func (g *generator) outputType(t *ast.Type, named func(string) (string, error)) (string, error) {
+	if t.Elem != nil {
+		elem, err := g.outputType(t.Elem, named)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	}
+	goType, err := named(t.NamedType)
+	if err != nil {
+		return "", err
+	}
+	if !t.NonNull {
+		return "*" + goType, nil
+	}
+	return goType, nil
+}
+
+// inputType returns Go type of GraphQL type *t* of variables and fields of input types,
+// and records input types it refers to.
+func (g *generator) inputType(t *ast.Type) (string, error) {
+	return g.outputType(t, func(named string) (string, error) {
+		if goType, ok := scalars[named]; ok {
+			return goType, nil
+		}
+		def := g.schema.Types[named]
+		if def == nil {
+			return "", fmt.Errorf("unknown type %s", named)
+		}
+		switch def.Kind {
+		case ast.Enum:
+			return "string", nil
+		case ast.InputObject:
+			if !g.inputs[named] {
+				g.inputs[named] = true
+				// Fields of input types are visited to record input types they refer to.
+				for _, field := range def.Fields {
+					if _, err := g.inputType(field.Type); err != nil {
+						return "", fmt.Errorf("field %s of %s: %v", field.Name, named, err)
+					}
+				}
+			}
+			return exported(named), nil
+		}
+		return "", fmt.Errorf("%s type %s is not supported", def.Kind, named)
+	})
+}
+
+// inputTypes generates structs of input types used by operations into *out*, sorted by name.
+// Nullable fields are omitted when they are nil.
+func (g *generator) inputTypes(out *bytes.Buffer) error {
+	var names []string
+	for name := range g.inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := g.declare(exported(name)); err != nil {
+			return err
+		}
+		def := g.schema.Types[name]
+		fmt.Fprintf(out, "// %s is GraphQL input type %s.\n", exported(name), name)
+		fmt.Fprintf(out, "type %s struct {\n", exported(name))
+		for _, field := range def.Fields {
+			goType, err := g.inputType(field.Type)
+			if err != nil {
+				return err
+			}
+			tag := field.Name
+			if !field.Type.NonNull {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(out, "%s %s `json:%q`\n", exported(field.Name), goType, tag)
+		}
+		out.WriteString("}\n\n")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const testSchema = `
+type Module {
+  OrgName: String!
+  Name: String!
+  Source: String
+  Owners: [Owner!]
+}
+
+type Owner {
+  Email: String!
+  Primary: Boolean!
+}
+
+input ModuleKey {
+  OrgName: String!
+  Name: String!
+  Tags: [String!]
+}
+
+type Query {
+  Modules(OrgName: String, Limit: Int): [Module!]!
+}
+
+type Mutation {
+  DeleteModule(Input: ModuleKey!): String!
+}
+`
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		desc       string
+		operations string
+		other      string   // Operations in another file.
+		want       []string // Snippets of generated code, compared with spaces collapsed.
+		wantErr    string
+	}{
+		{
+			desc: "query with nested selections",
+			operations: `query ListModules($OrgName: String, $Limit: Int) {
+  Modules(OrgName: $OrgName, Limit: $Limit) { OrgName Source owners: Owners { Email } }
+}`,
+			want: []string{
+				"func ListModules(ctx context.Context, client Doer, orgName *string, limit *int) (*ListModulesResponse, error) {",
+				"Modules []ListModulesModules `json:\"Modules\"`",
+				"Source  *string                   `json:\"Source\"`",
+				"Owners  []ListModulesModulesOwners `json:\"owners\"`",
+				"type ListModulesModulesOwners struct {\n\tEmail string `json:\"Email\"`\n}",
+				"\"Limit\":   limit,",
+			},
+		},
+		{
+			desc:       "mutation with input type",
+			operations: `mutation DeleteModule($Input: ModuleKey!) { DeleteModule(Input: $Input) }`,
+			want: []string{
+				"func DeleteModule(ctx context.Context, client Doer, input ModuleKey) (*DeleteModuleResponse, error) {",
+				"type ModuleKey struct {",
+				"Tags    []string `json:\"Tags,omitempty\"`",
+				"const DeleteModuleOperation = `mutation DeleteModule ($Input: ModuleKey!) {",
+			},
+		},
+		{
+			desc:       "field not in schema",
+			operations: `query ListModules { Modules { Version } }`,
+			wantErr:    `Cannot query field "Version" on type "Module"`,
+		},
+		{
+			desc:       "operation without name",
+			operations: `{ Modules { Name } }`,
+			wantErr:    "has no name",
+		},
+		{
+			desc:       "fragments",
+			operations: `query ListModules { Modules { ...Names } } fragment Names on Module { Name }`,
+			wantErr:    "fragments",
+		},
+		{
+			desc:       "conflicting names",
+			operations: `query ModuleKey { Modules { Name } }`,
+			other:      `mutation DeleteModule($Input: ModuleKey!) { DeleteModule(Input: $Input) }`,
+			wantErr:    "ModuleKey is declared twice",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			operations := []*ast.Source{{Name: "operations.graphql", Input: tc.operations}}
+			if tc.other != "" {
+				operations = append(operations, &ast.Source{Name: "other.graphql", Input: tc.other})
+			}
+			src, err := Generate([]*ast.Source{{Name: "schema.graphqls", Input: testSchema}}, operations, "gqlclient", "clientgen_test")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Generate should fail with %q, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(strings.Join(strings.Fields(string(src)), " "), strings.Join(strings.Fields(want), " ")) {
+					t.Errorf("generated code does not contain %q:\n%s", want, src)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// These are SQL statements of tables related to access control.
+const (
+	// $2 and $3 should be assigned with the same value (visibility of organization).
+	upsertOrganization     = `INSERT INTO organizations (orgName, visibility) VALUES($1, $2) on conflict (orgName) do update set visibility=$3`
+	selectOrgsByVisibility = `select orgName from organizations where visibility = $1 order by orgName`
+	insertAPIToken         = `INSERT INTO apiTokens (id, hash, orgNames, operations, description, createdBy, creatorEmail, creatorRoles, createdAt, expiresAt, revoked) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, false)`
+	selectAPITokens        = `select id, hash, orgNames, operations, description, createdBy, creatorEmail, creatorRoles, createdAt, expiresAt, revoked from apiTokens`
+	// $1 is name of organization, tokens scoped to it are selected.
+	selectAPITokensByOrgName = selectAPITokens + ` where $1 = any(orgNames) order by createdAt`
+	selectAPITokenByHash     = selectAPITokens + ` where hash = $1`
+	selectAPITokenByID       = selectAPITokens + ` where id = $1`
+	revokeAPIToken           = `update apiTokens set revoked = true where id = $1`
+	// $6 and $7 should be assigned with the same value (role of member).
+	upsertAccessGrant          = `INSERT INTO accessGrants (orgName, member, role, grantedBy, grantedAt) VALUES($1, $2, $3, $4, $5) on conflict (orgName, member) do update set role=$6, grantedBy=$4, grantedAt=$5`
+	deleteAccessGrant          = `delete from accessGrants where orgName = $1 and member = $2`
+	selectAccessGrants         = `select orgName, member, role, grantedBy, grantedAt from accessGrants`
+	selectAccessGrantsByOrg    = selectAccessGrants + ` where orgName = $1 order by member`
+	selectAccessGrantsByMember = selectAccessGrants + ` where member = any($1) order by orgName`
+	insertAccessRequest        = `INSERT INTO accessRequests (id, orgName, member, role, message, requestedBy, requestedAt, status) VALUES($1, $2, $3, $4, $5, $6, $7, $8)`
+	selectAccessRequests       = `select id, orgName, member, role, message, requestedBy, requestedAt, status, reviewedBy, reviewedAt from accessRequests`
+	selectAccessRequestByID    = selectAccessRequests + ` where id = $1`
+	// $2 is status of requests, e.g., AccessRequestPending.
+	selectAccessRequestsByOrgName = selectAccessRequests + ` where orgName = $1 and status = $2 order by requestedAt`
+	// Only pending requests can be reviewed, $5 should be AccessRequestPending.
+	reviewAccessRequest = `update accessRequests set status = $2, reviewedBy = $3, reviewedAt = $4 where id = $1 and status = $5`
+)
+
+// SetOrgVisibility sets visibility of organization *orgName* to *visibility*,
+// organizations not in database have VisibilityPublic.
+// Error is returned when insertion failed.
+func SetOrgVisibility(orgName string, visibility string) error {
+	if _, err := db.Exec(upsertOrganization, orgName, visibility, visibility); err != nil {
+		return fmt.Errorf("SetOrgVisibility: insert/update organization into db failed: %v", err)
+	}
+	return nil
+}
+
+// QueryOrgsByVisibility queries names of organizations of *visibility*.
+// Error is returned when query or reading data failed.
+func QueryOrgsByVisibility(visibility string) ([]string, error) {
+	rows, err := db.Query(selectOrgsByVisibility, visibility)
+	if err != nil {
+		return nil, fmt.Errorf("QueryOrgsByVisibility failed: %v", err)
+	}
+	defer rows.Close()
+
+	var orgNames []string
+	for rows.Next() {
+		var orgName string
+		if err := rows.Scan(&orgName); err != nil {
+			return nil, fmt.Errorf("QueryOrgsByVisibility: scan db rows failure, %v", err)
+		}
+		orgNames = append(orgNames, orgName)
+	}
+	return orgNames, nil
+}
+
+// InsertAPIToken inserts API *token* into database, only hash of the token is stored.
+// Error is returned when insertion failed.
+func InsertAPIToken(token APIToken) error {
+	if _, err := db.Exec(insertAPIToken, token.ID, token.Hash, pq.Array(token.OrgNames), pq.Array(token.Operations),
+		token.Description, token.CreatedBy, token.CreatorEmail, token.CreatorRoles, token.CreatedAt, token.ExpiresAt); err != nil {
+		return fmt.Errorf("InsertAPIToken: insert API token into db failed: %v", err)
+	}
+	return nil
+}
+
+// ReadAPITokensByRow scans queried API tokens from rows one by one, rows are closed inside.
+// Error is returned when scan rows failed.
+func ReadAPITokensByRow(rows *sql.Rows) ([]APIToken, error) {
+	defer rows.Close()
+	var tokens []APIToken
+	for rows.Next() {
+		var token APIToken
+		if err := rows.Scan(&token.ID, &token.Hash, pq.Array(&token.OrgNames), pq.Array(&token.Operations),
+			&token.Description, &token.CreatedBy, &token.CreatorEmail, &token.CreatorRoles, &token.CreatedAt, &token.ExpiresAt, &token.Revoked); err != nil {
+			return nil, fmt.Errorf("scan db rows failure, %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// QueryAPITokensByOrgName queries API tokens scoped to organization *orgName*, ordered by creation time.
+// Error is returned when query or reading data failed.
+func QueryAPITokensByOrgName(orgName string) ([]APIToken, error) {
+	rows, err := db.Query(selectAPITokensByOrgName, orgName)
+	if err != nil {
+		return nil, fmt.Errorf("QueryAPITokensByOrgName failed: %v", err)
+	}
+	return ReadAPITokensByRow(rows)
+}
+
+// QueryAPITokenByHash queries API token whose hash is *hash*, a nil pointer is returned if there is no such token.
+// Error is returned when query or reading data failed.
+func QueryAPITokenByHash(hash string) (*APIToken, error) {
+	return queryAPIToken(selectAPITokenByHash, hash)
+}
+
+// QueryAPITokenByID queries API token of *id*, a nil pointer is returned if there is no such token.
+// Error is returned when query or reading data failed.
+func QueryAPITokenByID(id string) (*APIToken, error) {
+	return queryAPIToken(selectAPITokenByID, id)
+}
+
+// queryAPIToken queries at most one API token by *stmt* with parameter *parm*.
+func queryAPIToken(stmt string, parm string) (*APIToken, error) {
+	rows, err := db.Query(stmt, parm)
+	if err != nil {
+		return nil, fmt.Errorf("query API token failed: %v", err)
+	}
+	tokens, err := ReadAPITokensByRow(rows)
+	if err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+	return &tokens[0], nil
+}
+
+// RevokeAPIToken revokes API token of *id*, revoked tokens are kept in database for auditing.
+// Error is returned when update failed or there is no such token.
+func RevokeAPIToken(id string) error {
+	res, err := db.Exec(revokeAPIToken, id)
+	if err != nil {
+		return fmt.Errorf("RevokeAPIToken: update API token failed: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("RevokeAPIToken: API token %s does not exist", id)
+	}
+	return nil
+}
+
+// InsertAccessGrant grants role to member in organization given values of fields of AccessGrant schema.
+// If member already has a role in that organization, the role is replaced.
+// Error is returned when insertion failed.
+func InsertAccessGrant(grant AccessGrant) error {
+	if _, err := db.Exec(upsertAccessGrant, grant.OrgName, grant.Member, grant.Role, grant.GrantedBy, grant.GrantedAt, grant.Role); err != nil {
+		return fmt.Errorf("InsertAccessGrant: insert/update access grant into db failed: %v", err)
+	}
+	return nil
+}
+
+// DeleteAccessGrant revokes role of *member* in organization *orgName*.
+// Error is returned when deletion failed or member does not have a role in that organization.
+func DeleteAccessGrant(orgName string, member string) error {
+	res, err := db.Exec(deleteAccessGrant, orgName, member)
+	if err != nil {
+		return fmt.Errorf("DeleteAccessGrant: delete access grant failed: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("DeleteAccessGrant: %s does not have access to organization %s", member, orgName)
+	}
+	return nil
+}
+
+// ReadAccessGrantsByRow scans queried access grants from rows one by one, rows are closed inside.
+// Error is returned when scan rows failed.
+func ReadAccessGrantsByRow(rows *sql.Rows) ([]AccessGrant, error) {
+	defer rows.Close()
+	var grants []AccessGrant
+	for rows.Next() {
+		var grant AccessGrant
+		if err := rows.Scan(&grant.OrgName, &grant.Member, &grant.Role, &grant.GrantedBy, &grant.GrantedAt); err != nil {
+			return nil, fmt.Errorf("scan db rows failure, %v", err)
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+// QueryAccessGrantsByOrgName queries access grants of organization *orgName* ordered by members.
+// Error is returned when query or reading data failed.
+func QueryAccessGrantsByOrgName(orgName string) ([]AccessGrant, error) {
+	rows, err := db.Query(selectAccessGrantsByOrg, orgName)
+	if err != nil {
+		return nil, fmt.Errorf("QueryAccessGrantsByOrgName failed: %v", err)
+	}
+	return ReadAccessGrantsByRow(rows)
+}
+
+// QueryAccessGrantsByMembers queries access grants of any of *members*, e.g., subject and email of a user.
+// Error is returned when query or reading data failed.
+func QueryAccessGrantsByMembers(members []string) ([]AccessGrant, error) {
+	rows, err := db.Query(selectAccessGrantsByMember, pq.Array(members))
+	if err != nil {
+		return nil, fmt.Errorf("QueryAccessGrantsByMembers failed: %v", err)
+	}
+	return ReadAccessGrantsByRow(rows)
+}
+
+// InsertAccessRequest inserts a request of access to organization given values of fields of AccessRequest schema.
+// Error is returned when insertion failed.
+func InsertAccessRequest(request AccessRequest) error {
+	if _, err := db.Exec(insertAccessRequest, request.ID, request.OrgName, request.Member, request.Role,
+		request.Message, request.RequestedBy, request.RequestedAt, request.Status); err != nil {
+		return fmt.Errorf("InsertAccessRequest: insert access request into db failed: %v", err)
+	}
+	return nil
+}
+
+// ReadAccessRequestsByRow scans queried access requests from rows one by one, rows are closed inside.
+// Error is returned when scan rows failed.
+func ReadAccessRequestsByRow(rows *sql.Rows) ([]AccessRequest, error) {
+	defer rows.Close()
+	var requests []AccessRequest
+	for rows.Next() {
+		var request AccessRequest
+		var reviewedBy sql.NullString
+		if err := rows.Scan(&request.ID, &request.OrgName, &request.Member, &request.Role, &request.Message,
+			&request.RequestedBy, &request.RequestedAt, &request.Status, &reviewedBy, &request.ReviewedAt); err != nil {
+			return nil, fmt.Errorf("scan db rows failure, %v", err)
+		}
+		request.ReviewedBy = reviewedBy.String
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// QueryAccessRequestByID queries access request of id *id*, a nil pointer is returned if there is no such request.
+// Error is returned when query or reading data failed.
+func QueryAccessRequestByID(id string) (*AccessRequest, error) {
+	rows, err := db.Query(selectAccessRequestByID, id)
+	if err != nil {
+		return nil, fmt.Errorf("QueryAccessRequestByID failed: %v", err)
+	}
+	requests, err := ReadAccessRequestsByRow(rows)
+	if err != nil || len(requests) == 0 {
+		return nil, err
+	}
+	return &requests[0], nil
+}
+
+// QueryAccessRequestsByOrgName queries access requests of organization *orgName* in *status*, ordered by request time.
+// Error is returned when query or reading data failed.
+func QueryAccessRequestsByOrgName(orgName string, status string) ([]AccessRequest, error) {
+	rows, err := db.Query(selectAccessRequestsByOrgName, orgName, status)
+	if err != nil {
+		return nil, fmt.Errorf("QueryAccessRequestsByOrgName failed: %v", err)
+	}
+	return ReadAccessRequestsByRow(rows)
+}
+
+// ReviewAccessRequest sets status of pending access request *id* to *status* reviewed by *reviewedBy* at *reviewedAt*.
+// Error is returned when update failed or request is not pending.
+func ReviewAccessRequest(id string, status string, reviewedBy string, reviewedAt time.Time) error {
+	res, err := db.Exec(reviewAccessRequest, id, status, reviewedBy, reviewedAt, AccessRequestPending)
+	if err != nil {
+		return fmt.Errorf("ReviewAccessRequest: update access request failed: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("ReviewAccessRequest: access request %s does not exist or is not pending", id)
+	}
+	return nil
+}
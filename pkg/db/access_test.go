@@ -0,0 +1,280 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	createOrganizationTable = `CREATE TABLE organizations (
+		orgName text NOT NULL,
+		visibility text NOT NULL,
+		primary key (orgName)
+	);`
+	dropOrganizationTable = `drop table organizations`
+	createAPITokenTable   = `CREATE TABLE apiTokens (
+		id text NOT NULL,
+		hash text NOT NULL UNIQUE,
+		orgNames text[] NOT NULL,
+		operations text[] NOT NULL,
+		description text NOT NULL,
+		createdBy text NOT NULL,
+		creatorEmail text NOT NULL DEFAULT '',
+		creatorRoles text NOT NULL DEFAULT '',
+		createdAt timestamptz NOT NULL,
+		expiresAt timestamptz,
+		revoked boolean NOT NULL DEFAULT false,
+		primary key (id)
+	);`
+	dropAPITokenTable      = `drop table apiTokens`
+	createAccessGrantTable = `CREATE TABLE accessGrants (
+		orgName text NOT NULL,
+		member text NOT NULL,
+		role text NOT NULL,
+		grantedBy text NOT NULL,
+		grantedAt timestamptz NOT NULL,
+		primary key (orgName, member)
+	)`
+	dropAccessGrantTable     = `drop table accessGrants`
+	createAccessRequestTable = `CREATE TABLE accessRequests (
+		id text NOT NULL,
+		orgName text NOT NULL,
+		member text NOT NULL,
+		role text NOT NULL,
+		message text NOT NULL,
+		requestedBy text NOT NULL,
+		requestedAt timestamptz NOT NULL,
+		status text NOT NULL,
+		reviewedBy text,
+		reviewedAt timestamptz,
+		primary key (id)
+	)`
+	dropAccessRequestTable = `drop table accessRequests`
+)
+
+// TestQueryOrgsByVisibility tests setting visibility of organizations and query them by visibility.
+func TestQueryOrgsByVisibility(t *testing.T) {
+	inputs := []Organization{
+		{OrgName: "org_A", Visibility: VisibilityPrivate},
+		{OrgName: "org_B", Visibility: VisibilityPrivate},
+		{OrgName: "org_C", Visibility: VisibilityPublic},
+		// Update visibility of existing organization.
+		{OrgName: "org_B", Visibility: VisibilityPublic},
+	}
+	tests := []struct {
+		visibility string
+		want       []string
+		desc       string
+	}{
+		{
+			visibility: VisibilityPrivate,
+			want:       []string{"org_A"},
+			desc:       "Test to query private organizations",
+		},
+		{
+			visibility: VisibilityPublic,
+			want:       []string{"org_B", "org_C"},
+			desc:       "Test to query public organizations",
+		},
+	}
+
+	err := ConnectDB()
+	if err != nil {
+		t.Errorf("connect to db failed: %v", err)
+	}
+	defer Close()
+	if _, err := db.Exec(createOrganizationTable); err != nil {
+		t.Errorf("create table failed: %v", err)
+	}
+	for _, in := range inputs {
+		if err := SetOrgVisibility(in.OrgName, in.Visibility); err != nil {
+			t.Errorf("pre insertion before query test failed: %v", err)
+		}
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			orgNames, err := QueryOrgsByVisibility(tc.visibility)
+			if err != nil {
+				t.Errorf("query organizations failed, visibility: %s, err: %v", tc.visibility, err)
+			}
+			if !reflect.DeepEqual(orgNames, tc.want) {
+				t.Errorf("query results mismatch, visibility: %s, got: %v", tc.visibility, orgNames)
+			}
+		})
+	}
+
+	if _, err := db.Exec(dropOrganizationTable); err != nil {
+		t.Errorf("drop table failed, err: %v", err)
+	}
+}
+
+// TestAPITokens tests insertion, query and revocation of API tokens.
+func TestAPITokens(t *testing.T) {
+	createdAt := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(time.Hour)
+	inputs := []APIToken{
+		{ID: "id_A", Hash: "hash_A", OrgNames: []string{"org_A", "org_B"}, Operations: []string{"publish"}, Description: "ci", CreatedBy: "user", CreatorEmail: "user@example.com", CreatorRoles: `{"org_A": "admin"}`, CreatedAt: createdAt, ExpiresAt: &expiresAt},
+		{ID: "id_B", Hash: "hash_B", OrgNames: []string{"org_B"}, Operations: []string{"read"}, CreatedBy: "user", CreatedAt: createdAt.Add(time.Minute)},
+	}
+
+	err := ConnectDB()
+	if err != nil {
+		t.Errorf("connect to db failed: %v", err)
+	}
+	defer Close()
+	if _, err := db.Exec(createAPITokenTable); err != nil {
+		t.Errorf("create table failed: %v", err)
+	}
+	for _, in := range inputs {
+		if err := InsertAPIToken(in); err != nil {
+			t.Errorf("pre insertion before query test failed: %v", err)
+		}
+	}
+
+	tokens, err := QueryAPITokensByOrgName("org_B")
+	if err != nil {
+		t.Errorf("query API tokens by orgName failed: %v", err)
+	}
+	if len(tokens) != 2 || tokens[0].ID != "id_A" || tokens[1].ID != "id_B" || tokens[1].ExpiresAt != nil {
+		t.Errorf("query API tokens by orgName mismatch, got: %v", tokens)
+	}
+	token, err := QueryAPITokenByHash("hash_A")
+	if err != nil {
+		t.Errorf("query API token by hash failed: %v", err)
+	}
+	if token == nil || !reflect.DeepEqual(token.OrgNames, inputs[0].OrgNames) || !token.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("query API token by hash mismatch, got: %v", token)
+	}
+	if err := RevokeAPIToken("id_A"); err != nil {
+		t.Errorf("revoke API token failed: %v", err)
+	}
+	if token, err := QueryAPITokenByID("id_A"); err != nil || token == nil || !token.Revoked {
+		t.Errorf("API token should be revoked, got: %v, err: %v", token, err)
+	}
+	if err := RevokeAPIToken("id_C"); err == nil {
+		t.Errorf("revoke API token which does not exist should fail")
+	}
+
+	if _, err := db.Exec(dropAPITokenTable); err != nil {
+		t.Errorf("drop table failed, err: %v", err)
+	}
+}
+
+// TestAccessGrants tests insertion, replacement, query and deletion of access grants.
+func TestAccessGrants(t *testing.T) {
+	grantedAt := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	inputs := []AccessGrant{
+		{OrgName: "org_A", Member: "alice@example.com", Role: "reader", GrantedBy: "admin", GrantedAt: grantedAt},
+		{OrgName: "org_B", Member: "alice@example.com", Role: "publisher", GrantedBy: "admin", GrantedAt: grantedAt},
+		{OrgName: "org_A", Member: "bob", Role: "admin", GrantedBy: "admin", GrantedAt: grantedAt},
+		// Role of alice in org_A is replaced.
+		{OrgName: "org_A", Member: "alice@example.com", Role: "maintainer", GrantedBy: "bob", GrantedAt: grantedAt.Add(time.Minute)},
+	}
+
+	err := ConnectDB()
+	if err != nil {
+		t.Errorf("connect to db failed: %v", err)
+	}
+	defer Close()
+	if _, err := db.Exec(createAccessGrantTable); err != nil {
+		t.Errorf("create table failed: %v", err)
+	}
+	for _, in := range inputs {
+		if err := InsertAccessGrant(in); err != nil {
+			t.Errorf("pre insertion before query test failed: %v", err)
+		}
+	}
+
+	grants, err := QueryAccessGrantsByOrgName("org_A")
+	if err != nil {
+		t.Errorf("query access grants by orgName failed: %v", err)
+	}
+	if len(grants) != 2 || grants[0].Member != "alice@example.com" || grants[0].Role != "maintainer" || grants[0].GrantedBy != "bob" || grants[1].Member != "bob" {
+		t.Errorf("query access grants by orgName mismatch, got: %v", grants)
+	}
+	grants, err = QueryAccessGrantsByMembers([]string{"uid_alice", "alice@example.com"})
+	if err != nil {
+		t.Errorf("query access grants by members failed: %v", err)
+	}
+	if len(grants) != 2 || grants[0].OrgName != "org_A" || grants[1].OrgName != "org_B" {
+		t.Errorf("query access grants by members mismatch, got: %v", grants)
+	}
+	if err := DeleteAccessGrant("org_B", "alice@example.com"); err != nil {
+		t.Errorf("delete access grant failed: %v", err)
+	}
+	if err := DeleteAccessGrant("org_B", "alice@example.com"); err == nil {
+		t.Errorf("delete access grant which does not exist should fail")
+	}
+
+	if _, err := db.Exec(dropAccessGrantTable); err != nil {
+		t.Errorf("drop table failed, err: %v", err)
+	}
+}
+
+// TestAccessRequests tests insertion, query and review of access requests.
+func TestAccessRequests(t *testing.T) {
+	requestedAt := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	inputs := []AccessRequest{
+		{ID: "id_A", OrgName: "org_A", Member: "alice@example.com", Role: "publisher", Message: "CI of org_A", RequestedBy: "uid_alice", RequestedAt: requestedAt, Status: AccessRequestPending},
+		{ID: "id_B", OrgName: "org_A", Member: "bob", Role: "reader", RequestedBy: "bob", RequestedAt: requestedAt.Add(time.Minute), Status: AccessRequestPending},
+		{ID: "id_C", OrgName: "org_B", Member: "bob", Role: "reader", RequestedBy: "bob", RequestedAt: requestedAt, Status: AccessRequestPending},
+	}
+
+	err := ConnectDB()
+	if err != nil {
+		t.Errorf("connect to db failed: %v", err)
+	}
+	defer Close()
+	if _, err := db.Exec(createAccessRequestTable); err != nil {
+		t.Errorf("create table failed: %v", err)
+	}
+	for _, in := range inputs {
+		if err := InsertAccessRequest(in); err != nil {
+			t.Errorf("pre insertion before query test failed: %v", err)
+		}
+	}
+
+	requests, err := QueryAccessRequestsByOrgName("org_A", AccessRequestPending)
+	if err != nil {
+		t.Errorf("query access requests by orgName failed: %v", err)
+	}
+	if len(requests) != 2 || requests[0].ID != "id_A" || requests[0].Message != "CI of org_A" || requests[1].ID != "id_B" || requests[1].ReviewedAt != nil {
+		t.Errorf("query access requests by orgName mismatch, got: %v", requests)
+	}
+	reviewedAt := requestedAt.Add(time.Hour)
+	if err := ReviewAccessRequest("id_A", AccessRequestApproved, "admin", reviewedAt); err != nil {
+		t.Errorf("review access request failed: %v", err)
+	}
+	if err := ReviewAccessRequest("id_A", AccessRequestRejected, "admin", reviewedAt); err == nil {
+		t.Errorf("review access request which is not pending should fail")
+	}
+	request, err := QueryAccessRequestByID("id_A")
+	if err != nil {
+		t.Errorf("query access request by id failed: %v", err)
+	}
+	if request == nil || request.Status != AccessRequestApproved || request.ReviewedBy != "admin" || !request.ReviewedAt.Equal(reviewedAt) {
+		t.Errorf("query access request by id mismatch, got: %v", request)
+	}
+	if requests, err := QueryAccessRequestsByOrgName("org_A", AccessRequestPending); err != nil || len(requests) != 1 {
+		t.Errorf("reviewed access request should not be pending, got: %v, err: %v", requests, err)
+	}
+
+	if _, err := db.Exec(dropAccessRequestTable); err != nil {
+		t.Errorf("drop table failed, err: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// These are SQL statements of audit log.
+const (
+	insertAuditEntry = `INSERT INTO auditLog (time, principal, subject, orgName, operation, target, outcome, error) VALUES($1, $2, $3, $4, $5, $6, $7, $8)`
+	selectAuditLog   = `select id, time, principal, subject, orgName, operation, target, outcome, error from auditLog`
+)
+
+// InsertAuditEntry appends *entry* to audit log, ID of *entry* is assigned by database.
+// Error is returned when insertion failed.
+func InsertAuditEntry(entry AuditEntry) error {
+	if _, err := db.Exec(insertAuditEntry, entry.Time, entry.Principal, entry.Subject, entry.OrgName,
+		entry.Operation, entry.Target, entry.Outcome, entry.Error); err != nil {
+		return fmt.Errorf("InsertAuditEntry: insert audit entry into db failed: %v", err)
+	}
+	return nil
+}
+
+// AuditFilter selects entries of audit log, empty fields do not filter entries.
+type AuditFilter struct {
+	Principal string     // Principal matches either principal or subject of entries.
+	OrgName   string     // OrgName matches organization of entries.
+	Since     *time.Time // Since is the inclusive lower bound of time of entries.
+	Until     *time.Time // Until is the exclusive upper bound of time of entries.
+	Limit     int        // Limit is the maximum number of entries, which must be positive.
+}
+
+// QueryAuditLog queries entries of audit log selected by *filter*, latest entries first.
+// Error is returned when query or reading data failed.
+func QueryAuditLog(filter AuditFilter) ([]AuditEntry, error) {
+	if filter.Limit <= 0 {
+		return nil, fmt.Errorf("QueryAuditLog: limit should be positive")
+	}
+	var conds []string
+	var args []interface{}
+	addCond := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.Principal != "" {
+		addCond("(principal = $%[1]d or subject = $%[1]d)", filter.Principal)
+	}
+	if filter.OrgName != "" {
+		addCond("orgName = $%d", filter.OrgName)
+	}
+	if filter.Since != nil {
+		addCond("time >= $%d", *filter.Since)
+	}
+	if filter.Until != nil {
+		addCond("time < $%d", *filter.Until)
+	}
+	query := selectAuditLog
+	if len(conds) > 0 {
+		query += " where " + strings.Join(conds, " and ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" order by time desc, id desc limit $%d", len(args))
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("QueryAuditLog failed: %v", err)
+	}
+	defer rows.Close()
+	var entries []AuditEntry
+	for rows.Next() {
+		var entry AuditEntry
+		if err := rows.Scan(&entry.ID, &entry.Time, &entry.Principal, &entry.Subject, &entry.OrgName,
+			&entry.Operation, &entry.Target, &entry.Outcome, &entry.Error); err != nil {
+			return nil, fmt.Errorf("QueryAuditLog: scan db rows failure, %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
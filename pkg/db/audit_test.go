@@ -0,0 +1,117 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	createAuditLogTable = `CREATE TABLE auditLog (
+		id bigserial NOT NULL,
+		time timestamptz NOT NULL,
+		principal text NOT NULL,
+		subject text NOT NULL,
+		orgName text NOT NULL,
+		operation text NOT NULL,
+		target text NOT NULL,
+		outcome text NOT NULL,
+		error text NOT NULL,
+		primary key (id)
+	)`
+	dropAuditLogTable = `drop table auditLog`
+)
+
+// TestQueryAuditLog tests insertion of audit entries and query them by filters.
+func TestQueryAuditLog(t *testing.T) {
+	start := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	inputs := []AuditEntry{
+		{Time: start, Principal: "alice@example.com", Subject: "uid_alice", OrgName: "org_A", Operation: "CreateModule", Outcome: AuditSuccess},
+		{Time: start.Add(time.Minute), Principal: "bob", Subject: "bob", OrgName: "org_A", Operation: "DeleteModule", Target: "Name=a, Version=1", Outcome: AuditFailure, Error: "no access"},
+		{Time: start.Add(2 * time.Minute), Principal: "alice@example.com", Subject: "uid_alice", OrgName: "org_B", Operation: "GrantAccess", Outcome: AuditSuccess},
+	}
+
+	err := ConnectDB()
+	if err != nil {
+		t.Errorf("connect to db failed: %v", err)
+	}
+	defer Close()
+	if _, err := db.Exec(createAuditLogTable); err != nil {
+		t.Errorf("create table failed: %v", err)
+	}
+	for _, in := range inputs {
+		if err := InsertAuditEntry(in); err != nil {
+			t.Errorf("pre insertion before query test failed: %v", err)
+		}
+	}
+
+	since, until := start.Add(time.Minute), start.Add(2*time.Minute)
+	tests := []struct {
+		desc   string
+		filter AuditFilter
+		want   []string
+	}{
+		{
+			desc:   "all entries, latest first",
+			filter: AuditFilter{Limit: 10},
+			want:   []string{"GrantAccess", "DeleteModule", "CreateModule"},
+		},
+		{
+			desc:   "by subject of principal",
+			filter: AuditFilter{Principal: "uid_alice", Limit: 10},
+			want:   []string{"GrantAccess", "CreateModule"},
+		},
+		{
+			desc:   "by organization",
+			filter: AuditFilter{OrgName: "org_A", Limit: 10},
+			want:   []string{"DeleteModule", "CreateModule"},
+		},
+		{
+			desc:   "by time range",
+			filter: AuditFilter{Since: &since, Until: &until, Limit: 10},
+			want:   []string{"DeleteModule"},
+		},
+		{
+			desc:   "limited",
+			filter: AuditFilter{Limit: 1},
+			want:   []string{"GrantAccess"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			entries, err := QueryAuditLog(tc.filter)
+			if err != nil {
+				t.Fatalf("query audit log failed: %v", err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Operation)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("query audit log mismatch, got: %v, want: %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("query audit log mismatch, got: %v, want: %v", got, tc.want)
+				}
+			}
+		})
+	}
+
+	if _, err := db.Exec(dropAuditLogTable); err != nil {
+		t.Errorf("drop table failed, err: %v", err)
+	}
+}
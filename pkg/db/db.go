@@ -15,6 +15,8 @@
 /*
 Package db contains functions related to database.
  * db.go includes conneting to db, query and insertion.
+ * access.go includes query and insertion of tables related to access control.
+ * audit.go includes insertion and search of audit log of mutations.
  * dbschema.go contains definitions of struct for db tables.
    Currently it only contains Module struct.
 */
@@ -22,14 +24,15 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 
 	"github.com/golang/glog"
 
-	// Go postgres driver for Go's database/sql package
-	_ "github.com/lib/pq"
+	// Go postgres driver for Go's database/sql package, which also converts array parameters.
+	"github.com/lib/pq"
 )
 
 // These are SQL stataments used in this package.
@@ -38,6 +41,10 @@ const (
 	// $4 and $5 should be assigned with the same value (the JSON data of module).
 	insertModule  = `INSERT INTO modules (orgName, name, version, data) VALUES($1, $2, $3, $4) on conflict (orgName, name, version) do update set data=$5`
 	selectModules = `select * from modules`
+	// Prefix is stored in data of module with or without module name of catalog schema.
+	selectModulePrefixes = `select name, coalesce(data->>'openconfig-module-catalog:prefix', data->>'prefix', '') from modules where orgName = $1`
+	// $1 is an array of JSON documents, modules whose data contains any of them are selected.
+	selectModuleOrgsByData = `select distinct orgName from modules where data @> any($1::jsonb[]) order by orgName`
 	// We want to ensure that user has to provide all three inputs,
 	// instead of deleting too many modules by mistake with some fields missing.
 	deleteModule         = `delete from modules where orgName = $1 and name = $2 and version = $3`
@@ -45,6 +52,12 @@ const (
 	// $4 and $5 should be assigned with the same value (the JSON data of feature-bundle).
 	insertFeatureBundle = `INSERT INTO featureBundles (orgName, name, version, data) VALUES($1, $2, $3, $4) on conflict (orgName, name, version) do update set data=$5`
 	deleteFeatureBundle = `delete from featurebundles where orgName = $1 and name = $2 and version = $3`
+	// YANG sources are content-addressed, inserting an existing source does nothing.
+	insertYangSource = `INSERT INTO yangSources (sha256, md5, name, data) VALUES($1, $2, $3, $4) on conflict (sha256) do nothing`
+	// $1 is either sha256 or md5 hash of YANG source.
+	selectYangSources = `select sha256, md5, name, data from yangSources where sha256 = $1 or md5 = $1`
+	// $1 is an array of md5 hashes of YANG sources.
+	selectYangSourcesByMD5 = `select sha256, md5, name, data from yangSources where md5 = any($1)`
 )
 
 // db is the global variable of connection to database.
@@ -219,6 +232,64 @@ func QueryModulesByOrgName(orgName *string) ([]Module, error) {
 	return ReadModulesByRow(rows)
 }
 
+// ModulePrefix is name and prefix of a module, prefix is empty if the module does not have one.
+type ModulePrefix struct {
+	Name   string
+	Prefix string
+}
+
+// QueryModulePrefixes queries names and prefixes of all modules of organization with *orgName* from database,
+// without reading and unmarshalling whole data of modules.
+func QueryModulePrefixes(orgName string) ([]ModulePrefix, error) {
+	rows, err := db.Query(selectModulePrefixes, orgName)
+	if err != nil {
+		return nil, fmt.Errorf("QueryModulePrefixes failed: %v", err)
+	}
+	defer rows.Close()
+	var prefixes []ModulePrefix
+	for rows.Next() {
+		var p ModulePrefix
+		if err := rows.Scan(&p.Name, &p.Prefix); err != nil {
+			return nil, fmt.Errorf("QueryModulePrefixes: scan db rows failure, %v", err)
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, nil
+}
+
+// QueryModuleOrgsBySource queries names of organizations having modules whose access,
+// or access of one of whose submodules, refers to YANG source of *md5Hash*.
+// Error is returned when query or reading data failed.
+func QueryModuleOrgsBySource(md5Hash string) ([]string, error) {
+	access := map[string]interface{}{"md5-hash": md5Hash}
+	submodules := map[string]interface{}{"submodule": []interface{}{map[string]interface{}{"access": access}}}
+	var docs []string
+	// Top-level fields are stored in data of module with or without module name of catalog schema.
+	for _, prefix := range []string{"openconfig-module-catalog:", ""} {
+		for _, doc := range []map[string]interface{}{{prefix + "access": access}, {prefix + "submodules": submodules}} {
+			b, err := json.Marshal(doc)
+			if err != nil {
+				return nil, fmt.Errorf("QueryModuleOrgsBySource: marshal JSON failed: %v", err)
+			}
+			docs = append(docs, string(b))
+		}
+	}
+	rows, err := db.Query(selectModuleOrgsByData, pq.Array(docs))
+	if err != nil {
+		return nil, fmt.Errorf("QueryModuleOrgsBySource failed: %v", err)
+	}
+	defer rows.Close()
+	var orgNames []string
+	for rows.Next() {
+		var orgName string
+		if err := rows.Scan(&orgName); err != nil {
+			return nil, fmt.Errorf("QueryModuleOrgsBySource: scan db rows failure, %v", err)
+		}
+		orgNames = append(orgNames, orgName)
+	}
+	return orgNames, nil
+}
+
 // QueryModulesByKey queries modules by its key (name, version), it is possible that parameters are null.
 // If both parameters are null, this equals query for all modules.
 // Return slice of db Module struct each field of which corresponds to one column in db.
@@ -370,3 +441,50 @@ func QueryFeatureBundlesByKey(name *string, version *string) ([]FeatureBundle, e
 
 	return ReadFeatureBundlesByRow(rows)
 }
+
+// InsertYangSource inserts YANG source of a module or submodule into database, given its
+// sha256 hash, md5 hash, name of module or submodule, and content of YANG source.
+// Sources are keyed by sha256 hash, so inserting an existing source does nothing.
+// Error is returned when insertion failed.
+func InsertYangSource(sha256 string, md5 string, name string, data string) error {
+	if _, err := db.Exec(insertYangSource, sha256, md5, name, data); err != nil {
+		return fmt.Errorf("insert YANG source into db failed: %v", err)
+	}
+	return nil
+}
+
+// QueryYangSources queries YANG sources whose sha256 or md5 hash equals *hash*.
+// Return slice of db YangSource struct each field of which corresponds to one column in db.
+// Error is returned when query or reading data failed.
+func QueryYangSources(hash string) ([]YangSource, error) {
+	rows, err := db.Query(selectYangSources, hash)
+	if err != nil {
+		return nil, fmt.Errorf("QueryYangSources failed: %v", err)
+	}
+	return readYangSources(rows)
+}
+
+// QueryYangSourcesByMD5 queries YANG sources whose md5 hash is any of *md5Hashes* in one query.
+// Return slice of db YangSource struct each field of which corresponds to one column in db.
+// Error is returned when query or reading data failed.
+func QueryYangSourcesByMD5(md5Hashes []string) ([]YangSource, error) {
+	rows, err := db.Query(selectYangSourcesByMD5, pq.Array(md5Hashes))
+	if err != nil {
+		return nil, fmt.Errorf("QueryYangSourcesByMD5 failed: %v", err)
+	}
+	return readYangSources(rows)
+}
+
+// readYangSources scans queried YANG sources from rows one by one, rows are closed inside.
+func readYangSources(rows *sql.Rows) ([]YangSource, error) {
+	defer rows.Close()
+	var sources []YangSource
+	for rows.Next() {
+		var source YangSource
+		if err := rows.Scan(&source.SHA256, &source.MD5, &source.Name, &source.Data); err != nil {
+			return nil, fmt.Errorf("scan db rows failure, %v", err)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
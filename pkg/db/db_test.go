@@ -17,6 +17,7 @@ package db
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -41,6 +42,14 @@ const (
 		primary key (orgName, name, version)
 	);`
 	dropFeatureBundleTable = `drop table featureBundles`
+	createYangSourceTable  = `CREATE TABLE yangSources (
+		sha256 text NOT NULL,
+		md5 text NOT NULL,
+		name text NOT NULL,
+		data text NOT NULL,
+		primary key (sha256)
+	);`
+	dropYangSourceTable = `drop table yangSources`
 )
 
 // CreateTestModuleTable is helper function to create module table in test database.
@@ -223,6 +232,97 @@ func TestQueryModulesByOrgName(t *testing.T) {
 	}
 }
 
+// TestQueryModulePrefixes tests query of names and prefixes of modules of an organization.
+func TestQueryModulePrefixes(t *testing.T) {
+	inputs := []Module{
+		{OrgName: "org1", Name: "name1", Version: "v1", Data: `{"openconfig-module-catalog:prefix": "p1"}`},
+		{OrgName: "org1", Name: "name2", Version: "v1", Data: `{"prefix": "p2"}`},
+		{OrgName: "org1", Name: "name3", Version: "v1", Data: `{}`},
+		{OrgName: "org2", Name: "name4", Version: "v1", Data: `{"openconfig-module-catalog:prefix": "p4"}`},
+	}
+	want := []ModulePrefix{{Name: "name1", Prefix: "p1"}, {Name: "name2", Prefix: "p2"}, {Name: "name3"}}
+
+	if err := ConnectDB(); err != nil {
+		t.Errorf("connect to db failed: %v", err)
+	}
+	defer Close()
+	if err := CreateTestModuleTable(); err != nil {
+		t.Errorf("create table failed: %v", err)
+	}
+	for _, m := range inputs {
+		if err := InsertModule(m.OrgName, m.Name, m.Version, m.Data); err != nil {
+			t.Errorf("pre insertion before query test failed: %v", err)
+		}
+	}
+	prefixes, err := QueryModulePrefixes("org1")
+	if err != nil {
+		t.Errorf("query prefixes failed: %v", err)
+	}
+	if !reflect.DeepEqual(prefixes, want) {
+		t.Errorf("query results mismatch, got: %v, want: %v", prefixes, want)
+	}
+	if err := DropModuleTable(); err != nil {
+		t.Errorf("drop table failed, err: %v", err)
+	}
+}
+
+func TestQueryModuleOrgsBySource(t *testing.T) {
+	inputs := []Module{
+		{OrgName: "org1", Name: "name1", Version: "v1", Data: `{"openconfig-module-catalog:access": {"md5-hash": "md5_A"}}`},
+		{OrgName: "org2", Name: "name2", Version: "v1", Data: `{"access": {"md5-hash": "md5_B"}}`},
+		{OrgName: "org3", Name: "name3", Version: "v1", Data: `{"openconfig-module-catalog:submodules": {"submodule": [{"name": "sub", "access": {"md5-hash": "md5_A"}}]}}`},
+		{OrgName: "org4", Name: "name4", Version: "v1", Data: `{"openconfig-module-catalog:name": "md5_A"}`},
+	}
+	tests := []struct {
+		md5Hash string
+		want    []string
+		desc    string
+	}{
+		{
+			md5Hash: "md5_A",
+			want:    []string{"org1", "org3"},
+			desc:    "Test to query source referred by module and submodule",
+		},
+		{
+			md5Hash: "md5_B",
+			want:    []string{"org2"},
+			desc:    "Test to query source referred by module without module name of catalog schema",
+		},
+		{
+			md5Hash: "md5_C",
+			want:    nil,
+			desc:    "Test to query source not referred by any module",
+		},
+	}
+
+	if err := ConnectDB(); err != nil {
+		t.Errorf("connect to db failed: %v", err)
+	}
+	defer Close()
+	if err := CreateTestModuleTable(); err != nil {
+		t.Errorf("create table failed: %v", err)
+	}
+	for _, m := range inputs {
+		if err := InsertModule(m.OrgName, m.Name, m.Version, m.Data); err != nil {
+			t.Errorf("pre insertion before query test failed: %v", err)
+		}
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			orgNames, err := QueryModuleOrgsBySource(tc.md5Hash)
+			if err != nil {
+				t.Errorf("query organizations failed, md5 hash: %s, err: %v", tc.md5Hash, err)
+			}
+			if !reflect.DeepEqual(orgNames, tc.want) {
+				t.Errorf("query results mismatch, md5 hash: %s, got: %v, want: %v", tc.md5Hash, orgNames, tc.want)
+			}
+		})
+	}
+	if err := DropModuleTable(); err != nil {
+		t.Errorf("drop table failed, err: %v", err)
+	}
+}
+
 // TestQueryModulesByKey tests query Module by its key (name, version).
 func TestQueryModulesByKey(t *testing.T) {
 	inputs := struct {
@@ -927,3 +1027,117 @@ func TestQueryFeaturBundlesByKey(t *testing.T) {
 		t.Errorf("drop table failed, err: %v", err)
 	}
 }
+
+// TestQueryYangSources tests insertion of YANG sources and query them by sha256 or md5 hash.
+func TestQueryYangSources(t *testing.T) {
+	inputs := []YangSource{
+		{SHA256: "sha_A", MD5: "md5_A", Name: "module_A", Data: "module module_A {}"},
+		{SHA256: "sha_B", MD5: "md5_B", Name: "module_B", Data: "module module_B {}"},
+		// Inserting an existing source does nothing.
+		{SHA256: "sha_A", MD5: "md5_A", Name: "module_A", Data: "module module_A {}"},
+	}
+	tests := []struct {
+		hash string
+		want []YangSource
+		desc string
+	}{
+		{
+			hash: "sha_A",
+			want: []YangSource{inputs[0]},
+			desc: "Test to query with sha256 hash",
+		},
+		{
+			hash: "md5_B",
+			want: []YangSource{inputs[1]},
+			desc: "Test to query with md5 hash",
+		},
+		{
+			hash: "sha_C",
+			want: nil,
+			desc: "Test to query with hash of no matching YANG source",
+		},
+	}
+
+	err := ConnectDB()
+	if err != nil {
+		t.Errorf("connect to db failed: %v", err)
+	}
+	defer Close()
+	if _, err := db.Exec(createYangSourceTable); err != nil {
+		t.Errorf("create table failed: %v", err)
+	}
+	for _, in := range inputs {
+		if err := InsertYangSource(in.SHA256, in.MD5, in.Name, in.Data); err != nil {
+			t.Errorf("pre insertion before query test failed: %v", err)
+		}
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			sources, err := QueryYangSources(tc.hash)
+			if err != nil {
+				t.Errorf("query YANG sources failed, hash: %s, err: %v", tc.hash, err)
+			}
+			if !reflect.DeepEqual(sources, tc.want) {
+				t.Errorf("query results mismatch, hash: %s, got: %v", tc.hash, sources)
+			}
+		})
+	}
+
+	if _, err := db.Exec(dropYangSourceTable); err != nil {
+		t.Errorf("drop table failed, err: %v", err)
+	}
+}
+
+func TestQueryYangSourcesByMD5(t *testing.T) {
+	inputs := []YangSource{
+		{SHA256: "sha_A", MD5: "md5_A", Name: "module_A", Data: "module module_A {}"},
+		{SHA256: "sha_B", MD5: "md5_B", Name: "module_B", Data: "module module_B {}"},
+		{SHA256: "sha_C", MD5: "md5_C", Name: "module_C", Data: "module module_C {}"},
+	}
+	tests := []struct {
+		hashes []string
+		want   []YangSource
+		desc   string
+	}{
+		{
+			hashes: []string{"md5_A", "md5_C"},
+			want:   []YangSource{inputs[0], inputs[2]},
+			desc:   "Test to query with multiple md5 hashes",
+		},
+		{
+			hashes: []string{"sha_B", "md5_D"},
+			want:   nil,
+			desc:   "Test to query with hashes of no matching YANG source",
+		},
+	}
+
+	err := ConnectDB()
+	if err != nil {
+		t.Errorf("connect to db failed: %v", err)
+	}
+	defer Close()
+	if _, err := db.Exec(createYangSourceTable); err != nil {
+		t.Errorf("create table failed: %v", err)
+	}
+	for _, in := range inputs {
+		if err := InsertYangSource(in.SHA256, in.MD5, in.Name, in.Data); err != nil {
+			t.Errorf("pre insertion before query test failed: %v", err)
+		}
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			sources, err := QueryYangSourcesByMD5(tc.hashes)
+			if err != nil {
+				t.Errorf("query YANG sources failed, hashes: %v, err: %v", tc.hashes, err)
+			}
+			sort.Slice(sources, func(i, j int) bool { return sources[i].SHA256 < sources[j].SHA256 })
+			if !reflect.DeepEqual(sources, tc.want) {
+				t.Errorf("query results mismatch, hashes: %v, got: %v", tc.hashes, sources)
+			}
+		})
+	}
+
+	if _, err := db.Exec(dropYangSourceTable); err != nil {
+		t.Errorf("drop table failed, err: %v", err)
+	}
+}
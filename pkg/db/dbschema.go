@@ -14,6 +14,8 @@
 
 package db
 
+import "time"
+
 // This file contains definition for structs in database schema
 
 // Module is struct of Module table in db schema.
@@ -32,3 +34,88 @@ type FeatureBundle struct {
 	Version string // Version column refers to version of this FeatureBundle.
 	Data    string // Data column refers to json format string of this FeatureBundle in YANG schema.
 }
+
+// YangSource is struct of YangSource table in db schema, which stores YANG source files
+// of modules and submodules addressed by their content.
+type YangSource struct {
+	SHA256 string // SHA256 column refers to hex encoded sha256 hash of this YANG source, which is its key.
+	MD5    string // MD5 column refers to hex encoded md5 hash of this YANG source, which is md5-hash in Module's access.
+	Name   string // Name column refers to name of module or submodule defined in this YANG source.
+	Data   string // Data column refers to content of this YANG source.
+}
+
+// Visibility of organizations, data of private organizations can only be read by users with read access.
+const (
+	VisibilityPublic  = `public`
+	VisibilityPrivate = `private`
+)
+
+// Organization is struct of Organization table in db schema, which stores settings of organizations.
+type Organization struct {
+	OrgName    string // OrgName column refers to name of organization.
+	Visibility string // Visibility column refers to visibility of organization, VisibilityPublic or VisibilityPrivate.
+}
+
+// APIToken is struct of APIToken table in db schema, which stores API tokens scoped to organizations and operations.
+type APIToken struct {
+	ID           string     // ID column refers to public identifier of this token, which is used to revoke it.
+	Hash         string     // Hash column refers to hex encoded sha256 hash of this token, the token itself is not stored.
+	OrgNames     []string   // OrgNames column refers to names of organizations this token is scoped to.
+	Operations   []string   // Operations column refers to operations this token is allowed to perform.
+	Description  string     // Description column refers to description of this token given by its creator.
+	CreatedBy    string     // CreatedBy column refers to subject of the user who created this token.
+	CreatorEmail string     // CreatorEmail column refers to verified email of the user who created this token, empty if there is none.
+	CreatorRoles string     // CreatorRoles column refers to JSON object of roles in claims of the user who created this token.
+	CreatedAt    time.Time  // CreatedAt column refers to time when this token is created.
+	ExpiresAt    *time.Time // ExpiresAt column refers to time when this token expires, nil if it does not expire.
+	Revoked      bool       // Revoked column refers to whether this token is revoked.
+}
+
+// AccessGrant is struct of AccessGrant table in db schema, which stores roles of members in organizations.
+type AccessGrant struct {
+	OrgName   string    // OrgName column refers to name of organization, or "*" for all organizations.
+	Member    string    // Member column refers to verified email or subject of the user granted with role.
+	Role      string    // Role column refers to name of role of member in organization, e.g., `publisher`.
+	GrantedBy string    // GrantedBy column refers to subject of the user who granted this role.
+	GrantedAt time.Time // GrantedAt column refers to time when this role is granted.
+}
+
+// Status of access requests.
+const (
+	AccessRequestPending  = `pending`
+	AccessRequestApproved = `approved`
+	AccessRequestRejected = `rejected`
+)
+
+// AccessRequest is struct of AccessRequest table in db schema, which stores requests of users for roles in organizations.
+type AccessRequest struct {
+	ID          string     // ID column refers to random id of request.
+	OrgName     string     // OrgName column refers to name of requested organization.
+	Member      string     // Member column refers to verified email or subject of the requesting user, see AccessGrant.
+	Role        string     // Role column refers to name of requested role.
+	Message     string     // Message column refers to message to reviewers of request.
+	RequestedBy string     // RequestedBy column refers to subject of the requesting user.
+	RequestedAt time.Time  // RequestedAt column refers to time when request is created.
+	Status      string     // Status column refers to status of request, e.g., AccessRequestPending.
+	ReviewedBy  string     // ReviewedBy column refers to subject of the reviewer, empty if request is pending.
+	ReviewedAt  *time.Time // ReviewedAt column refers to time when request is reviewed, nil if request is pending.
+}
+
+// Outcomes of audited operations.
+const (
+	AuditSuccess = `success`
+	AuditFailure = `failure`
+)
+
+// AuditEntry is struct of AuditLog table in db schema, which records authenticated operations, e.g., mutations.
+type AuditEntry struct {
+	ID        int64     // ID column refers to serial id of entry assigned by database.
+	Time      time.Time // Time column refers to time when operation is performed.
+	Principal string    // Principal column refers to verified email or subject of the user, empty if not authenticated.
+	Subject   string    // Subject column refers to subject of token of the user, e.g., `api-token:ID` for API tokens.
+	OrgName   string    // OrgName column refers to organization of operation, empty if it is not known from arguments.
+	Operation string    // Operation column refers to name of operation, e.g., `CreateModule`.
+	Target    string    // Target column refers to arguments identifying target of operation, e.g., `Name=ietf-yang-types`.
+	Outcome   string    // Outcome column refers to outcome of operation, which is AuditSuccess or AuditFailure.
+	Error     string    // Error column refers to reason of failure, empty if operation succeeded.
+}
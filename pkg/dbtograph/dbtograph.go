@@ -23,6 +23,7 @@ package dbtograph
 
 import (
 	"fmt"
+	"time"
 
 	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
 
@@ -70,3 +71,89 @@ func FeatureBundleToGraphQL(dbFeatureBundles []db.FeatureBundle) ([]*model.Featu
 	}
 	return featureBundles, nil
 }
+
+// APITokenToGraphQL converts APIToken schema in database to graphQL APIToken response type.
+// Hash of token is not included in response, and times are formatted in RFC 3339.
+func APITokenToGraphQL(dbAPITokens []db.APIToken) []*model.APIToken {
+	apiTokens := []*model.APIToken{}
+	for i := 0; i < len(dbAPITokens); i++ {
+		apiToken := &model.APIToken{
+			ID:          dbAPITokens[i].ID,
+			OrgNames:    dbAPITokens[i].OrgNames,
+			Operations:  dbAPITokens[i].Operations,
+			Description: dbAPITokens[i].Description,
+			CreatedBy:   dbAPITokens[i].CreatedBy,
+			CreatedAt:   dbAPITokens[i].CreatedAt.Format(time.RFC3339),
+			Revoked:     dbAPITokens[i].Revoked,
+		}
+		if dbAPITokens[i].ExpiresAt != nil {
+			expiresAt := dbAPITokens[i].ExpiresAt.Format(time.RFC3339)
+			apiToken.ExpiresAt = &expiresAt
+		}
+		apiTokens = append(apiTokens, apiToken)
+	}
+	return apiTokens
+}
+
+// AccessGrantToGraphQL converts AccessGrant schema in database to graphQL AccessGrant response type.
+// Times are formatted in RFC 3339.
+func AccessGrantToGraphQL(dbAccessGrants []db.AccessGrant) []*model.AccessGrant {
+	grants := []*model.AccessGrant{}
+	for i := 0; i < len(dbAccessGrants); i++ {
+		grants = append(grants, &model.AccessGrant{
+			OrgName:   dbAccessGrants[i].OrgName,
+			Member:    dbAccessGrants[i].Member,
+			Role:      dbAccessGrants[i].Role,
+			GrantedBy: dbAccessGrants[i].GrantedBy,
+			GrantedAt: dbAccessGrants[i].GrantedAt.Format(time.RFC3339),
+		})
+	}
+	return grants
+}
+
+// AccessRequestToGraphQL converts AccessRequest schema in database to graphQL AccessRequest response type.
+// Times are formatted in RFC 3339.
+func AccessRequestToGraphQL(dbAccessRequests []db.AccessRequest) []*model.AccessRequest {
+	requests := []*model.AccessRequest{}
+	for i := 0; i < len(dbAccessRequests); i++ {
+		request := &model.AccessRequest{
+			ID:          dbAccessRequests[i].ID,
+			OrgName:     dbAccessRequests[i].OrgName,
+			Member:      dbAccessRequests[i].Member,
+			Role:        dbAccessRequests[i].Role,
+			Message:     dbAccessRequests[i].Message,
+			RequestedBy: dbAccessRequests[i].RequestedBy,
+			RequestedAt: dbAccessRequests[i].RequestedAt.Format(time.RFC3339),
+			Status:      dbAccessRequests[i].Status,
+		}
+		if dbAccessRequests[i].ReviewedBy != "" {
+			reviewedBy := dbAccessRequests[i].ReviewedBy
+			request.ReviewedBy = &reviewedBy
+		}
+		if dbAccessRequests[i].ReviewedAt != nil {
+			reviewedAt := dbAccessRequests[i].ReviewedAt.Format(time.RFC3339)
+			request.ReviewedAt = &reviewedAt
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+// AuditEntryToGraphQL converts AuditEntry schema in database to graphQL AuditEntry response type.
+// Times are formatted in RFC 3339.
+func AuditEntryToGraphQL(dbAuditEntries []db.AuditEntry) []*model.AuditEntry {
+	entries := []*model.AuditEntry{}
+	for i := 0; i < len(dbAuditEntries); i++ {
+		entries = append(entries, &model.AuditEntry{
+			Time:      dbAuditEntries[i].Time.Format(time.RFC3339),
+			Principal: dbAuditEntries[i].Principal,
+			Subject:   dbAuditEntries[i].Subject,
+			OrgName:   dbAuditEntries[i].OrgName,
+			Operation: dbAuditEntries[i].Operation,
+			Target:    dbAuditEntries[i].Target,
+			Outcome:   dbAuditEntries[i].Outcome,
+			Error:     dbAuditEntries[i].Error,
+		})
+	}
+	return entries
+}
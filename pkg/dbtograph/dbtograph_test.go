@@ -17,6 +17,7 @@ package dbtograph
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/openconfig/catalog-server/graph/model"
@@ -146,3 +147,93 @@ func TestFeatureBundleToGraphQL(t *testing.T) {
 		})
 	}
 }
+
+func TestAPITokenToGraphQL(t *testing.T) {
+	createdAt := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := createdAt.Add(24 * time.Hour)
+	inputs := []db.APIToken{
+		{
+			ID:          "id_A",
+			Hash:        "hash_A",
+			OrgNames:    []string{"org_A", "org_B"},
+			Operations:  []string{"publish"},
+			Description: "ci",
+			CreatedBy:   "user",
+			CreatedAt:   createdAt,
+			ExpiresAt:   &expiresAt,
+		},
+		{
+			ID:         "id_B",
+			Hash:       "hash_B",
+			OrgNames:   []string{"org_A"},
+			Operations: []string{"read", "delete"},
+			CreatedBy:  "user",
+			CreatedAt:  createdAt,
+			Revoked:    true,
+		},
+	}
+	wantExpiresAt := "2021-07-02T12:00:00Z"
+	want := []*model.APIToken{
+		{
+			ID:          "id_A",
+			OrgNames:    []string{"org_A", "org_B"},
+			Operations:  []string{"publish"},
+			Description: "ci",
+			CreatedBy:   "user",
+			CreatedAt:   "2021-07-01T12:00:00Z",
+			ExpiresAt:   &wantExpiresAt,
+		},
+		{
+			ID:         "id_B",
+			OrgNames:   []string{"org_A"},
+			Operations: []string{"read", "delete"},
+			CreatedBy:  "user",
+			CreatedAt:  "2021-07-01T12:00:00Z",
+			Revoked:    true,
+		},
+	}
+	if diff := cmp.Diff(want, APITokenToGraphQL(inputs)); diff != "" {
+		t.Errorf("APITokenToGraphQL mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAccessGrantToGraphQL(t *testing.T) {
+	inputs := []db.AccessGrant{
+		{OrgName: "org_A", Member: "alice@example.com", Role: "admin", GrantedBy: "user", GrantedAt: time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	want := []*model.AccessGrant{
+		{OrgName: "org_A", Member: "alice@example.com", Role: "admin", GrantedBy: "user", GrantedAt: "2021-07-01T12:00:00Z"},
+	}
+	if diff := cmp.Diff(want, AccessGrantToGraphQL(inputs)); diff != "" {
+		t.Errorf("AccessGrantToGraphQL mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAccessRequestToGraphQL(t *testing.T) {
+	requestedAt := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC)
+	reviewedAt := requestedAt.Add(time.Hour)
+	inputs := []db.AccessRequest{
+		{ID: "id_A", OrgName: "org_A", Member: "alice@example.com", Role: "publisher", RequestedBy: "uid", RequestedAt: requestedAt, Status: "pending"},
+		{ID: "id_B", OrgName: "org_A", Member: "bob", Role: "reader", RequestedBy: "bob", RequestedAt: requestedAt, Status: "approved", ReviewedBy: "admin", ReviewedAt: &reviewedAt},
+	}
+	wantReviewedBy, wantReviewedAt := "admin", "2021-07-01T13:00:00Z"
+	want := []*model.AccessRequest{
+		{ID: "id_A", OrgName: "org_A", Member: "alice@example.com", Role: "publisher", RequestedBy: "uid", RequestedAt: "2021-07-01T12:00:00Z", Status: "pending"},
+		{ID: "id_B", OrgName: "org_A", Member: "bob", Role: "reader", RequestedBy: "bob", RequestedAt: "2021-07-01T12:00:00Z", Status: "approved", ReviewedBy: &wantReviewedBy, ReviewedAt: &wantReviewedAt},
+	}
+	if diff := cmp.Diff(want, AccessRequestToGraphQL(inputs)); diff != "" {
+		t.Errorf("AccessRequestToGraphQL mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAuditEntryToGraphQL(t *testing.T) {
+	inputs := []db.AuditEntry{
+		{ID: 1, Time: time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC), Principal: "bob", Subject: "bob", OrgName: "org_A", Operation: "DeleteModule", Target: "Name=a", Outcome: "failure", Error: "no access"},
+	}
+	want := []*model.AuditEntry{
+		{Time: "2021-07-01T12:00:00Z", Principal: "bob", Subject: "bob", OrgName: "org_A", Operation: "DeleteModule", Target: "Name=a", Outcome: "failure", Error: "no access"},
+	}
+	if diff := cmp.Diff(want, AuditEntryToGraphQL(inputs)); diff != "" {
+		t.Errorf("AuditEntryToGraphQL mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package etag provides HTTP middleware setting ETag of responses of catalog server,
+// so that clients can revalidate cached query results by conditional requests.
+package etag
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// cacheControl is Cache-Control of responses with ETag. Responses may depend on Authorization header,
+// so they are private. Freshness is not specified, clients decide how long results are used before revalidation.
+const cacheControl = `private`
+
+// recorder buffers response of wrapped handler.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header { return r.header }
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+// Of returns strong ETag of response *body*.
+func Of(body []byte) string {
+	hash := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// matches checks whether If-None-Match header *ifNoneMatch* contains *etag*.
+func matches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == etag || candidate == "W/"+etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// graphQLParams are parameters of GraphQL request in URL query or JSON body.
+type graphQLParams struct {
+	Query         string `json:"query"`
+	OperationName string `json:"operationName"`
+}
+
+// isQuery checks whether *r* is a GraphQL query operation, given in URL query of GET requests or JSON body of POST requests.
+// Body of POST requests is read and replaced by a copy, so that it is still available to handlers.
+// Mutations, subscriptions, and requests that cannot be parsed are not queries.
+func isQuery(r *http.Request) bool {
+	var params graphQLParams
+	switch r.Method {
+	case http.MethodGet:
+		params.Query = r.URL.Query().Get("query")
+		params.OperationName = r.URL.Query().Get("operationName")
+	case http.MethodPost:
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			return false
+		}
+		body, err := io.ReadAll(r.Body)
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil || json.Unmarshal(body, &params) != nil {
+			return false
+		}
+	default:
+		return false
+	}
+	doc, err := parser.ParseQuery(&ast.Source{Input: params.Query})
+	if err != nil {
+		return false
+	}
+	var op *ast.OperationDefinition
+	if params.OperationName != "" {
+		op = doc.Operations.ForName(params.OperationName)
+	} else if len(doc.Operations) == 1 {
+		op = doc.Operations[0]
+	}
+	return op != nil && op.Operation == ast.Query
+}
+
+// Middleware returns a handler that sets ETag of successful responses of GraphQL queries of *next*,
+// which is hash of response body.
+// If ETag matches If-None-Match header of request, 304 Not Modified is responded without body.
+// Mutations are passed to *next* directly, since their responses report results of changes,
+// which should never be replaced by cached ones.
+// Requests upgrading connection, e.g., websockets, are also passed to *next* directly.
+// Responses of queries vary by Authorization header, as users may read different organizations,
+// so that shared caches never answer a user with results of another one.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" || !isQuery(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Authorization")
+		rec := &recorder{header: w.Header()}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		if rec.status == http.StatusOK {
+			etag := Of(rec.body.Bytes())
+			w.Header().Set("ETag", etag)
+			if w.Header().Get("Cache-Control") == "" {
+				w.Header().Set("Cache-Control", cacheControl)
+			}
+			if inm := r.Header.Get("If-None-Match"); inm != "" && matches(inm, etag) {
+				w.Header().Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etag
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	body := `{"data": {"ModulesByOrgName": []}}`
+	query := `{"query": "query { ModulesByOrgName(OrgName: \"openconfig\") { Name } }"}`
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+		// Request body should still be readable by handler.
+		if r.Method == http.MethodPost {
+			if b, err := io.ReadAll(r.Body); err != nil || len(b) == 0 {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}
+		fmt.Fprint(w, body)
+	}))
+	etag := Of([]byte(body))
+
+	tests := []struct {
+		desc        string
+		method      string
+		url         string
+		request     string // JSON body of POST request.
+		ifNoneMatch string
+		wantStatus  int
+		wantETag    string
+		wantBody    string
+		wantVary    string
+	}{
+		{
+			desc:       "ETag is set",
+			method:     http.MethodPost,
+			url:        "/query",
+			request:    query,
+			wantStatus: http.StatusOK,
+			wantETag:   etag,
+			wantBody:   body,
+			wantVary:   "Authorization",
+		},
+		{
+			desc:        "matching ETag is not modified",
+			method:      http.MethodPost,
+			url:         "/query",
+			request:     query,
+			ifNoneMatch: `"other", ` + etag,
+			wantStatus:  http.StatusNotModified,
+			wantETag:    etag,
+			wantVary:    "Authorization",
+		},
+		{
+			desc:        "ETag does not match",
+			method:      http.MethodPost,
+			url:         "/query",
+			request:     query,
+			ifNoneMatch: `"other"`,
+			wantStatus:  http.StatusOK,
+			wantETag:    etag,
+			wantBody:    body,
+			wantVary:    "Authorization",
+		},
+		{
+			desc:        "failed response has no ETag",
+			method:      http.MethodPost,
+			url:         "/query?fail=1",
+			request:     query,
+			ifNoneMatch: etag,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantBody:    body,
+			wantVary:    "Authorization",
+		},
+		{
+			desc:        "query in URL of GET request",
+			method:      http.MethodGet,
+			url:         "/query?query=%7BModulesByOrgName%28OrgName%3A%22openconfig%22%29%7BName%7D%7D",
+			ifNoneMatch: etag,
+			wantStatus:  http.StatusNotModified,
+			wantETag:    etag,
+			wantVary:    "Authorization",
+		},
+		{
+			desc:        "mutation has no ETag",
+			method:      http.MethodPost,
+			url:         "/query",
+			request:     `{"query": "mutation { DeleteModule(Input: {OrgName: \"openconfig\"}) }"}`,
+			ifNoneMatch: etag,
+			wantStatus:  http.StatusOK,
+			wantBody:    body,
+		},
+		{
+			desc:        "selected operation is mutation",
+			method:      http.MethodPost,
+			url:         "/query",
+			request:     `{"query": "query Q { Name } mutation M { Delete }", "operationName": "M"}`,
+			ifNoneMatch: etag,
+			wantStatus:  http.StatusOK,
+			wantBody:    body,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.request))
+			if tc.method == http.MethodPost {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tc.wantStatus || rec.Header().Get("ETag") != tc.wantETag || rec.Body.String() != tc.wantBody {
+				t.Errorf("response mismatch, got: %d %q %q, want: %d %q %q",
+					rec.Code, rec.Header().Get("ETag"), rec.Body.String(), tc.wantStatus, tc.wantETag, tc.wantBody)
+			}
+			if got := rec.Header().Get("Vary"); got != tc.wantVary {
+				t.Errorf("Vary mismatch, got: %q, want: %q", got, tc.wantVary)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+	"github.com/openconfig/ygot/util"
+	"github.com/openconfig/ygot/ygot"
+)
+
+// This file contains functions to produce detailed validation reports,
+// which list every problem found in the input JSON data instead of stopping at the first one.
+
+// Severity levels of an Issue.
+const (
+	SeverityError   = `ERROR`   // Data with an issue of this severity is rejected.
+	SeverityWarning = `WARNING` // Issue of this severity is reported but data is still accepted.
+)
+
+// rootPath is the schema path used for issues that are not specific to one field.
+const rootPath = `/`
+
+// Issue describes a single problem found when validating JSON data of a catalog entry.
+type Issue struct {
+	Path     string // Path is schema path of the field that the issue relates to, e.g., `/access/uri`.
+	Severity string // Severity is either SeverityError or SeverityWarning.
+	Message  string // Message explains the problem.
+	Rule     string // Rule is name of lint rule reporting this issue, it is empty for issues found by CheckModule and CheckFeatureBundle.
+}
+
+// CheckModule validates the input JSON data of a Module and reports every issue it finds.
+// It returns a pointer to Module unmarshalled from fields of *data* that are valid,
+// which is nil if *data* is not a JSON object, and a list of issues sorted by path.
+func CheckModule(data string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, []Issue) {
+	newModule := func() ygot.ValidatedGoStruct {
+		return &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+	}
+	s, issues := checkStruct(data, newModule)
+	if s == nil {
+		return nil, issues
+	}
+	module := s.(*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module)
+
+	// Check whether this module contains non-empty key of Module (i.e, name and version).
+	issues = append(issues, checkKey(module.GetName(), module.GetVersion(), issues)...)
+	sortIssues(issues)
+	return module, issues
+}
+
+// CheckFeatureBundle validates the input JSON data of a FeatureBundle and reports every issue it finds.
+// It returns a pointer to FeatureBundle unmarshalled from fields of *data* that are valid,
+// which is nil if *data* is not a JSON object, and a list of issues sorted by path.
+func CheckFeatureBundle(data string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, []Issue) {
+	newFeatureBundle := func() ygot.ValidatedGoStruct {
+		return &oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle{}
+	}
+	s, issues := checkStruct(data, newFeatureBundle)
+	if s == nil {
+		return nil, issues
+	}
+	featureBundle := s.(*oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle)
+
+	// Check whether this featureBundle contains non-empty key of FeatureBundle (i.e, name and version).
+	issues = append(issues, checkKey(featureBundle.GetName(), featureBundle.GetVersion(), issues)...)
+	sortIssues(issues)
+	return featureBundle, issues
+}
+
+// checkStruct unmarshals *data* into a struct created by *newStruct* and runs ygot validation over it.
+// Each top-level field is unmarshalled on its own, so that an invalid field is reported
+// without hiding problems of the other fields. Only valid fields are kept in returned struct.
+func checkStruct(data string, newStruct func() ygot.ValidatedGoStruct) (ygot.ValidatedGoStruct, []Issue) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &fields); err != nil {
+		return nil, []Issue{newError(rootPath, fmt.Sprintf("cannot unmarshal JSON: %v", err))}
+	}
+
+	var issues []Issue
+	validFields := map[string]interface{}{}
+	for name, value := range fields {
+		name := name
+		fieldIssues := checkField(fieldPath(name), value, func(v interface{}) interface{} {
+			return map[string]interface{}{name: v}
+		}, newStruct)
+		if len(fieldIssues) > 0 {
+			issues = append(issues, fieldIssues...)
+			continue
+		}
+		validFields[name] = value
+	}
+
+	// Unmarshal all valid fields together, this should not fail as each of them is unmarshalled successfully.
+	s := newStruct()
+	validJSON, err := json.Marshal(validFields)
+	if err != nil {
+		return nil, append(issues, newError(rootPath, fmt.Sprintf("cannot marshal valid fields: %v", err)))
+	}
+	if err := oc.Unmarshal(validJSON, s); err != nil {
+		return nil, append(issues, newError(rootPath, fmt.Sprintf("cannot unmarshal JSON: %v", err)))
+	}
+
+	// Check whether Validate function for the struct that comes from ygot package could pass.
+	if err := s.Validate(); err != nil {
+		var schemaPath string
+		if schema := oc.SchemaTree[reflect.TypeOf(s).Elem().Name()]; schema != nil {
+			schemaPath = schema.Path()
+		}
+		errs, ok := err.(util.Errors)
+		if !ok {
+			errs = util.Errors{err}
+		}
+		for _, e := range errs {
+			issues = append(issues, validationIssue(e, schemaPath))
+		}
+	}
+	return s, issues
+}
+
+// checkField unmarshals *value* of field at schema path *path* into a struct created by *newStruct*,
+// *wrap* nests a value of the field into JSON object of the entry.
+// If the field is a container that cannot be unmarshalled, its fields are checked one by one,
+// so that issues are reported at paths of the innermost invalid fields, e.g., `/access/md5-hash`.
+func checkField(path string, value interface{}, wrap func(interface{}) interface{}, newStruct func() ygot.ValidatedGoStruct) []Issue {
+	fieldJSON, err := json.Marshal(wrap(value))
+	if err != nil {
+		return []Issue{newError(path, fmt.Sprintf("cannot marshal field: %v", err))}
+	}
+	err = oc.Unmarshal(fieldJSON, newStruct())
+	if err == nil {
+		return nil
+	}
+	if children, ok := value.(map[string]interface{}); ok {
+		var issues []Issue
+		for name, child := range children {
+			name := name
+			issues = append(issues, checkField(path+fieldPath(name), child, func(v interface{}) interface{} {
+				return wrap(map[string]interface{}{name: v})
+			}, newStruct)...)
+		}
+		// A container may be invalid even if each of its fields is valid on its own.
+		if len(issues) > 0 {
+			return issues
+		}
+	}
+	return []Issue{newError(path, fmt.Sprintf("cannot unmarshal JSON: %v", err))}
+}
+
+// validationIssue converts error *err* of ygot validation of a catalog entry of schema path *schemaPath*
+// into an Issue at path of the invalid field. ygot prefixes errors with schema paths of the containers
+// and the field from the entry down, so the last prefix is schema path of the invalid field.
+func validationIssue(err error, schemaPath string) Issue {
+	path, msg := "", err.Error()
+	for strings.HasPrefix(msg, "/") {
+		i := strings.Index(msg, ": ")
+		if i < 0 {
+			break
+		}
+		path, msg = msg[:i], msg[i+2:]
+	}
+	if schemaPath == "" || !strings.HasPrefix(path, schemaPath+"/") {
+		return newError(rootPath, fmt.Sprintf("Validate function failed: %s", msg))
+	}
+	return newError(strings.TrimPrefix(path, schemaPath), fmt.Sprintf("Validate function failed: %s", msg))
+}
+
+// checkKey checks whether key of a catalog entry (i.e., name and version) is non-empty.
+// Empty name or version is not reported if *issues* already has an issue of that field, e.g., it cannot be unmarshalled.
+func checkKey(name string, version string, issues []Issue) []Issue {
+	var res []Issue
+	if name == "" && !hasIssue(issues, "/name") {
+		res = append(res, newError("/name", "name cannot be empty"))
+	}
+	if version == "" && !hasIssue(issues, "/version") {
+		res = append(res, newError("/version", "version cannot be empty"))
+	}
+	return res
+}
+
+// hasIssue returns whether *issues* contains an issue of field at *path*.
+func hasIssue(issues []Issue, path string) bool {
+	for _, issue := range issues {
+		if issue.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldPath converts name of a field in RFC7951 JSON into its schema path,
+// e.g., `openconfig-module-catalog:access` into `/access`.
+func fieldPath(name string) string {
+	return rootPath + name[strings.LastIndex(name, ":")+1:]
+}
+
+// newError returns an Issue with SeverityError.
+func newError(path string, message string) Issue {
+	return Issue{Path: path, Severity: SeverityError, Message: message}
+}
+
+// sortIssues sorts issues by path, while keeping original order of issues with the same path.
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Path < issues[j].Path
+	})
+}
+
+// IssuesToError returns an error containing all issues with SeverityError,
+// or nil if there is no such issue.
+func IssuesToError(issues []Issue) error {
+	var msgs []string
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", issue.Path, issue.Message))
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "; "))
+}
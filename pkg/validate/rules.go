@@ -0,0 +1,291 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+)
+
+// This file contains semantic lint rules of catalog entries, which are checked beyond ygot validation.
+// Severity of each rule can be configured per organization, see *LintConfig*.
+
+// SeverityOff can be configured for a rule in LintConfig to disable that rule.
+const SeverityOff = `OFF`
+
+// allOrgs is name of organization in LintConfig whose severities apply to all organizations
+// that do not configure severity of a rule by themselves.
+const allOrgs = `*`
+
+// Names of lint rules.
+const (
+	RuleVersionSemver   = `version-semver`    // Version matches semantic versioning, e.g., `1.2.0`.
+	RuleRevisionDate    = `revision-date`     // Revision is a valid date in format of `YYYY-MM-DD`.
+	RuleNamespaceURI    = `namespace-uri`     // Namespace is an absolute URI.
+	RulePrefixUnique    = `prefix-unique`     // Prefix is not used by other modules of the same organization.
+	RuleAccessURIFormat = `access-uri-format` // URI in access is an absolute http(s) URL.
+	RuleSubmoduleURI    = `submodule-uri`     // Each submodule has URI in its access.
+	RuleSummaryRequired = `summary-required`  // Summary is non-empty.
+)
+
+// semverRegexp matches versions following semantic versioning 2.0.0 (https://semver.org).
+var semverRegexp = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// revisionLayout is layout of revision date of YANG modules.
+const revisionLayout = `2006-01-02`
+
+// Rule is a semantic lint rule.
+// A rule may check Module, FeatureBundle or both, its check function of the other type is nil.
+type Rule struct {
+	Name               string // Name is used to configure severity of this rule in LintConfig.
+	DefaultSeverity    string // DefaultSeverity is used if severity of this rule is not configured.
+	checkModule        func(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, orgModules []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) []Issue
+	checkFeatureBundle func(featureBundle *oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle) []Issue
+}
+
+// Rules contains all lint rules in the order they are checked.
+var Rules = []Rule{
+	{
+		Name:            RuleVersionSemver,
+		DefaultSeverity: SeverityWarning,
+		checkModule: func(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, _ []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) []Issue {
+			return checkVersion(module.GetVersion())
+		},
+		checkFeatureBundle: func(featureBundle *oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle) []Issue {
+			return checkVersion(featureBundle.GetVersion())
+		},
+	},
+	{
+		Name:            RuleRevisionDate,
+		DefaultSeverity: SeverityWarning,
+		checkModule:     checkRevision,
+	},
+	{
+		Name:            RuleNamespaceURI,
+		DefaultSeverity: SeverityWarning,
+		checkModule:     checkNamespace,
+	},
+	{
+		Name:            RulePrefixUnique,
+		DefaultSeverity: SeverityWarning,
+		checkModule:     checkPrefix,
+	},
+	{
+		Name:            RuleAccessURIFormat,
+		DefaultSeverity: SeverityWarning,
+		checkModule:     checkAccessURI,
+	},
+	{
+		Name:            RuleSubmoduleURI,
+		DefaultSeverity: SeverityWarning,
+		checkModule:     checkSubmoduleURI,
+	},
+	{
+		Name:            RuleSummaryRequired,
+		DefaultSeverity: SeverityWarning,
+		checkModule:     checkSummary,
+	},
+}
+
+// LintConfig maps name of organization to severities configured for rules of that organization,
+// which is a map from name of rule to its severity (SeverityError, SeverityWarning or SeverityOff).
+// Severities configured for organization `*` apply to all organizations without their own configuration.
+// Rules not configured use their DefaultSeverity.
+type LintConfig map[string]map[string]string
+
+// lintConfig is the LintConfig used by LintModule and LintFeatureBundle.
+// Similar to connection of database in package db, it is a global variable
+// set up once when server starts.
+var lintConfig LintConfig
+
+// SetLintConfig validates *config* and sets it as LintConfig used by this package.
+func SetLintConfig(config LintConfig) error {
+	for orgName, severities := range config {
+		for name, severity := range severities {
+			if findRule(name) == nil {
+				return fmt.Errorf("SetLintConfig: unknown rule %s for organization %s", name, orgName)
+			}
+			if severity != SeverityError && severity != SeverityWarning && severity != SeverityOff {
+				return fmt.Errorf("SetLintConfig: invalid severity %s of rule %s for organization %s", severity, name, orgName)
+			}
+		}
+	}
+	lintConfig = config
+	return nil
+}
+
+// LoadLintConfig reads LintConfig in JSON format from file of *filepath*, and sets it as LintConfig used by this package.
+// Example of this file: {"*": {"version-semver": "ERROR"}, "openconfig": {"summary-required": "OFF"}}.
+func LoadLintConfig(filepath string) error {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return fmt.Errorf("LoadLintConfig: read file failed: %v", err)
+	}
+	var config LintConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("LoadLintConfig: cannot unmarshal JSON: %v", err)
+	}
+	return SetLintConfig(config)
+}
+
+// severity returns severity of rule *r* configured for organization *orgName*.
+func (r *Rule) severity(orgName string) string {
+	if severity, ok := lintConfig[orgName][r.Name]; ok {
+		return severity
+	}
+	if severity, ok := lintConfig[allOrgs][r.Name]; ok {
+		return severity
+	}
+	return r.DefaultSeverity
+}
+
+// findRule returns pointer to rule with *name*, or nil if there is no such rule.
+func findRule(name string) *Rule {
+	for i := 0; i < len(Rules); i++ {
+		if Rules[i].Name == name {
+			return &Rules[i]
+		}
+	}
+	return nil
+}
+
+// LintModule checks all lint rules over *module* of organization *orgName*.
+// *orgModules* are existing modules of that organization, which are used by rules across modules (e.g., prefix-unique).
+// It returns a list of issues sorted by path, severity of each issue is configured for *orgName* in LintConfig.
+func LintModule(orgName string, module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, orgModules []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) []Issue {
+	var issues []Issue
+	for i := 0; i < len(Rules); i++ {
+		r := &Rules[i]
+		severity := r.severity(orgName)
+		if r.checkModule == nil || severity == SeverityOff {
+			continue
+		}
+		issues = append(issues, withRule(r.checkModule(module, orgModules), r.Name, severity)...)
+	}
+	sortIssues(issues)
+	return issues
+}
+
+// LintFeatureBundle checks all lint rules over *featureBundle* of organization *orgName*.
+// It returns a list of issues sorted by path, severity of each issue is configured for *orgName* in LintConfig.
+func LintFeatureBundle(orgName string, featureBundle *oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle) []Issue {
+	var issues []Issue
+	for i := 0; i < len(Rules); i++ {
+		r := &Rules[i]
+		severity := r.severity(orgName)
+		if r.checkFeatureBundle == nil || severity == SeverityOff {
+			continue
+		}
+		issues = append(issues, withRule(r.checkFeatureBundle(featureBundle), r.Name, severity)...)
+	}
+	sortIssues(issues)
+	return issues
+}
+
+// withRule sets rule name and severity of *issues*.
+func withRule(issues []Issue, name string, severity string) []Issue {
+	for i := 0; i < len(issues); i++ {
+		issues[i].Rule = name
+		issues[i].Severity = severity
+	}
+	return issues
+}
+
+// checkVersion checks whether *version* follows semantic versioning.
+// Empty version is reported by CheckModule and CheckFeatureBundle instead.
+func checkVersion(version string) []Issue {
+	if version == "" || semverRegexp.MatchString(version) {
+		return nil
+	}
+	return []Issue{{Path: "/version", Message: fmt.Sprintf("version %s does not follow semantic versioning", version)}}
+}
+
+func checkRevision(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, _ []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) []Issue {
+	if module.Revision == nil {
+		return nil
+	}
+	if _, err := time.Parse(revisionLayout, module.GetRevision()); err != nil {
+		return []Issue{{Path: "/revision", Message: fmt.Sprintf("revision %s is not a valid date in format of YYYY-MM-DD", module.GetRevision())}}
+	}
+	return nil
+}
+
+func checkNamespace(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, _ []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) []Issue {
+	if module.Namespace == nil {
+		return nil
+	}
+	if u, err := url.Parse(module.GetNamespace()); err != nil || !u.IsAbs() {
+		return []Issue{{Path: "/namespace", Message: fmt.Sprintf("namespace %s is not an absolute URI", module.GetNamespace())}}
+	}
+	return nil
+}
+
+func checkPrefix(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, orgModules []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) []Issue {
+	if module.GetPrefix() == "" {
+		return nil
+	}
+	for _, m := range orgModules {
+		// Different versions of the same module share the same prefix.
+		if m.GetName() != module.GetName() && m.GetPrefix() == module.GetPrefix() {
+			return []Issue{{Path: "/prefix", Message: fmt.Sprintf("prefix %s is already used by module %s", module.GetPrefix(), m.GetName())}}
+		}
+	}
+	return nil
+}
+
+func checkAccessURI(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, _ []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) []Issue {
+	if module.GetAccess().GetUri() == "" {
+		return []Issue{{Path: "/access/uri", Message: "access uri is empty"}}
+	}
+	if !isHTTPURL(module.GetAccess().GetUri()) {
+		return []Issue{{Path: "/access/uri", Message: fmt.Sprintf("access uri %s is not an absolute http(s) URL", module.GetAccess().GetUri())}}
+	}
+	return nil
+}
+
+func checkSubmoduleURI(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, _ []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) []Issue {
+	if module.GetSubmodules() == nil {
+		return nil
+	}
+	var issues []Issue
+	for name, submodule := range module.GetSubmodules().Submodule {
+		if submodule.GetAccess().GetUri() == "" {
+			issues = append(issues, Issue{Path: fmt.Sprintf("/submodules/submodule[name=%s]/access/uri", name), Message: fmt.Sprintf("submodule %s does not have access uri", name)})
+		}
+	}
+	return issues
+}
+
+func checkSummary(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, _ []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) []Issue {
+	if module.GetSummary() == "" {
+		return []Issue{{Path: "/summary", Message: "summary is empty"}}
+	}
+	return nil
+}
+
+// isHTTPURL checks whether *uri* is an absolute URL with http(s) scheme and a host.
+func isHTTPURL(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+)
+
+// mustModule unmarshals JSON *data* into a Module and fails the test if unmarshalling fails.
+func mustModule(t *testing.T, data string) *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module {
+	module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+	if err := oc.Unmarshal([]byte(data), module); err != nil {
+		t.Fatalf("cannot unmarshal module %s: %v", data, err)
+	}
+	return module
+}
+
+func TestLintModule(t *testing.T) {
+	existing := `{
+		"openconfig-module-catalog:name": "openconfig-interfaces",
+		"openconfig-module-catalog:version": "2.0.0",
+		"openconfig-module-catalog:prefix": "oc-if"
+	}`
+	tests := []struct {
+		desc       string
+		config     LintConfig
+		orgName    string
+		input      string
+		wantIssues []Issue
+	}{
+		{
+			desc:    "module passing all rules",
+			orgName: "openconfig",
+			input: `{
+				"openconfig-module-catalog:name": "openconfig-interfaces",
+				"openconfig-module-catalog:version": "2.4.3",
+				"openconfig-module-catalog:revision": "2021-04-06",
+				"openconfig-module-catalog:namespace": "http://openconfig.net/yang/interfaces",
+				"openconfig-module-catalog:prefix": "oc-if",
+				"openconfig-module-catalog:summary": "Model for managing network interfaces",
+				"openconfig-module-catalog:access": {"uri": "https://github.com/openconfig/public/blob/master/release/models/interfaces/openconfig-interfaces.yang"},
+				"openconfig-module-catalog:submodules": {"submodule": [{"name": "sub", "access": {"uri": "https://example.com/sub.yang"}}]}
+			}`,
+		},
+		{
+			desc:    "module failing all rules with default severities",
+			orgName: "openconfig",
+			input: `{
+				"openconfig-module-catalog:name": "openconfig-vlan",
+				"openconfig-module-catalog:version": "version1",
+				"openconfig-module-catalog:revision": "2021-13-01",
+				"openconfig-module-catalog:namespace": "vlan",
+				"openconfig-module-catalog:prefix": "oc-if",
+				"openconfig-module-catalog:access": {"uri": "github.com/openconfig/public"},
+				"openconfig-module-catalog:submodules": {"submodule": [{"name": "sub"}]}
+			}`,
+			wantIssues: []Issue{
+				{Path: "/access/uri", Severity: SeverityWarning, Message: "access uri github.com/openconfig/public is not an absolute http(s) URL", Rule: RuleAccessURIFormat},
+				{Path: "/namespace", Severity: SeverityWarning, Message: "namespace vlan is not an absolute URI", Rule: RuleNamespaceURI},
+				{Path: "/prefix", Severity: SeverityWarning, Message: "prefix oc-if is already used by module openconfig-interfaces", Rule: RulePrefixUnique},
+				{Path: "/revision", Severity: SeverityWarning, Message: "revision 2021-13-01 is not a valid date in format of YYYY-MM-DD", Rule: RuleRevisionDate},
+				{Path: "/submodules/submodule[name=sub]/access/uri", Severity: SeverityWarning, Message: "submodule sub does not have access uri", Rule: RuleSubmoduleURI},
+				{Path: "/summary", Severity: SeverityWarning, Message: "summary is empty", Rule: RuleSummaryRequired},
+				{Path: "/version", Severity: SeverityWarning, Message: "version version1 does not follow semantic versioning", Rule: RuleVersionSemver},
+			},
+		},
+		{
+			desc: "severities configured for organization",
+			config: LintConfig{
+				"*":          {RuleVersionSemver: SeverityError, RuleSummaryRequired: SeverityError},
+				"openconfig": {RuleSummaryRequired: SeverityOff, RuleAccessURIFormat: SeverityError},
+			},
+			orgName: "openconfig",
+			input: `{
+				"openconfig-module-catalog:name": "openconfig-vlan",
+				"openconfig-module-catalog:version": "version1"
+			}`,
+			wantIssues: []Issue{
+				{Path: "/access/uri", Severity: SeverityError, Message: "access uri is empty", Rule: RuleAccessURIFormat},
+				{Path: "/version", Severity: SeverityError, Message: "version version1 does not follow semantic versioning", Rule: RuleVersionSemver},
+			},
+		},
+		{
+			desc:    "rules escalated to errors by configuration",
+			config:  LintConfig{"*": {RuleRevisionDate: SeverityError, RuleNamespaceURI: SeverityError, RulePrefixUnique: SeverityError, RuleSummaryRequired: SeverityOff, RuleAccessURIFormat: SeverityOff}},
+			orgName: "openconfig",
+			input: `{
+				"openconfig-module-catalog:name": "openconfig-vlan",
+				"openconfig-module-catalog:version": "1.0.0",
+				"openconfig-module-catalog:revision": "2021-13-01",
+				"openconfig-module-catalog:namespace": "vlan",
+				"openconfig-module-catalog:prefix": "oc-if"
+			}`,
+			wantIssues: []Issue{
+				{Path: "/namespace", Severity: SeverityError, Message: "namespace vlan is not an absolute URI", Rule: RuleNamespaceURI},
+				{Path: "/prefix", Severity: SeverityError, Message: "prefix oc-if is already used by module openconfig-interfaces", Rule: RulePrefixUnique},
+				{Path: "/revision", Severity: SeverityError, Message: "revision 2021-13-01 is not a valid date in format of YYYY-MM-DD", Rule: RuleRevisionDate},
+			},
+		},
+		{
+			desc: "severities configured for all organizations",
+			config: LintConfig{
+				"*":          {RuleVersionSemver: SeverityError, RuleSummaryRequired: SeverityOff, RuleAccessURIFormat: SeverityOff},
+				"openconfig": {RuleVersionSemver: SeverityWarning},
+			},
+			orgName: "ietf",
+			input: `{
+				"openconfig-module-catalog:name": "ietf-interfaces",
+				"openconfig-module-catalog:version": "version1"
+			}`,
+			wantIssues: []Issue{
+				{Path: "/version", Severity: SeverityError, Message: "version version1 does not follow semantic versioning", Rule: RuleVersionSemver},
+			},
+		},
+	}
+
+	orgModules := []*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{mustModule(t, existing)}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if err := SetLintConfig(tc.config); err != nil {
+				t.Fatalf("SetLintConfig failed: %v", err)
+			}
+			defer SetLintConfig(nil)
+			issues := LintModule(tc.orgName, mustModule(t, tc.input), orgModules)
+			if diff := cmp.Diff(tc.wantIssues, issues); diff != "" {
+				t.Errorf("LintModule issues mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLintFeatureBundle(t *testing.T) {
+	tests := []struct {
+		desc       string
+		input      string
+		wantIssues []Issue
+	}{
+		{
+			desc:  "version follows semantic versioning",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": "1.0.0-rc.1+build.5"}`,
+		},
+		{
+			desc:  "version does not follow semantic versioning",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": "1.0"}`,
+			wantIssues: []Issue{
+				{Path: "/version", Severity: SeverityWarning, Message: "version 1.0 does not follow semantic versioning", Rule: RuleVersionSemver},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			featureBundle := &oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle{}
+			if err := oc.Unmarshal([]byte(tc.input), featureBundle); err != nil {
+				t.Fatalf("cannot unmarshal featureBundle: %v", err)
+			}
+			issues := LintFeatureBundle("openconfig", featureBundle)
+			if diff := cmp.Diff(tc.wantIssues, issues); diff != "" {
+				t.Errorf("LintFeatureBundle issues mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSetLintConfig(t *testing.T) {
+	tests := []struct {
+		desc    string
+		config  LintConfig
+		wantErr bool
+	}{
+		{
+			desc:   "valid config",
+			config: LintConfig{"*": {RuleVersionSemver: SeverityError}, "openconfig": {RulePrefixUnique: SeverityOff}},
+		},
+		{
+			desc:    "unknown rule",
+			config:  LintConfig{"openconfig": {"no-such-rule": SeverityError}},
+			wantErr: true,
+		},
+		{
+			desc:    "invalid severity",
+			config:  LintConfig{"openconfig": {RuleVersionSemver: "error"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := SetLintConfig(tc.config)
+			defer SetLintConfig(nil)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("SetLintConfig wantErr mismatch, err: %v, wantErr: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
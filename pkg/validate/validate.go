@@ -29,22 +29,9 @@ import (
 // It takes a JSON string *data*, and returns a pointer to Module if *data* is in correct format.
 // Otherwise, the function returns an error explaining why validation fails.
 func ValidateModule(data string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, error) {
-	module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
-
-	// First check whether the data can be correctly unmarshalled back to a Module struct.
-	if err := oc.Unmarshal([]byte(data), module); err != nil {
-		return nil, fmt.Errorf("ValidateModule: cannot unmarshal JSON: %v", err)
-	}
-
-	// Check whether Validate function for Module struct that comes from ygot package could pass.
-	if err := module.Validate(); err != nil {
-		return nil, fmt.Errorf("ValidateModule: Validate function failed: %v", err)
-	}
-
-	// Check whether this module contains non-empty key of Module (i.e, name and version).
-	// If not, then return an error.
-	if module.GetName() == "" || module.GetVersion() == "" {
-		return nil, fmt.Errorf("ValidateModule: Module cannot have empty name or version")
+	module, issues := CheckModule(data)
+	if err := IssuesToError(issues); err != nil {
+		return nil, fmt.Errorf("ValidateModule: %v", err)
 	}
 	return module, nil
 }
@@ -53,22 +40,9 @@ func ValidateModule(data string) (*oc.OpenconfigModuleCatalog_Organizations_Orga
 // It takes a JSON string *data*, and returns a pointer to FeatureBundle if *data* is in correct format.
 // Otherwise, the fucntion returns an error explaining why validation fails.
 func ValidateFeatureBundle(data string) (*oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle, error) {
-	featureBundle := &oc.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle{}
-
-	// First check whether the data can be correctly unmarshalled back to a FeatureBundle struct.
-	if err := oc.Unmarshal([]byte(data), featureBundle); err != nil {
-		return nil, fmt.Errorf("ValidateFeatureBundle: cannot unmarshal JSON: %v", err)
-	}
-
-	// Check whether Validate function for FeatureBundle struct that comes from ygot package could pass.
-	if err := featureBundle.Validate(); err != nil {
-		return nil, fmt.Errorf("ValidateFeatureBundle: Validate function failed: %v", err)
-	}
-
-	// Check whether this featureBundle contains non-empty key of FeatureBundle (i.e, name and version).
-	// If not, then return an error.
-	if featureBundle.GetName() == "" || featureBundle.GetVersion() == "" {
-		return nil, fmt.Errorf("ValidateFeatureBundle: FeatureBundle cannot have empty name or version")
+	featureBundle, issues := CheckFeatureBundle(data)
+	if err := IssuesToError(issues); err != nil {
+		return nil, fmt.Errorf("ValidateFeatureBundle: %v", err)
 	}
 	return featureBundle, nil
 }
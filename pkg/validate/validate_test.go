@@ -15,7 +15,11 @@
 package validate
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/ygot/util"
 )
 
 func TestValidateModule(t *testing.T) {
@@ -76,3 +80,160 @@ func TestValidateFeatureBundle(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckModule(t *testing.T) {
+	tests := []struct {
+		desc       string
+		input      string
+		wantIssues []Issue
+	}{
+		{
+			desc:  "invalid JSON",
+			input: ``,
+			wantIssues: []Issue{
+				{Path: "/", Severity: SeverityError, Message: "cannot unmarshal JSON: unexpected end of JSON input"},
+			},
+		},
+		{
+			desc:  "empty module",
+			input: `{}`,
+			wantIssues: []Issue{
+				{Path: "/name", Severity: SeverityError, Message: "name cannot be empty"},
+				{Path: "/version", Severity: SeverityError, Message: "version cannot be empty"},
+			},
+		},
+		{
+			desc:  "multiple invalid fields are all reported",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": 1, "openconfig-module-catalog:foo": "bar", "openconfig-module-catalog:classification": {"category": "bad"}}`,
+			wantIssues: []Issue{
+				{Path: "/classification/category", Severity: SeverityError, Message: "cannot unmarshal JSON: bad is not a valid value for enum field Category, type ygotgen.E_OpenconfigCatalogTypes_MODULE_CATEGORY_BASE"},
+				{Path: "/foo", Severity: SeverityError, Message: "cannot unmarshal JSON: parent container module (type *ygotgen.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module): JSON contains unexpected field foo"},
+				{Path: "/version", Severity: SeverityError, Message: "cannot unmarshal JSON: got float64 type for field version, expect string"},
+			},
+		},
+		{
+			desc:  "bad enum in nested container",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": "version1", "openconfig-module-catalog:classification": {"category": "openconfig-catalog-types:IETF_MODEL_LAYER", "deployment-status": "openconfig-catalog-types:BAD"}}`,
+			wantIssues: []Issue{
+				{Path: "/classification/deployment-status", Severity: SeverityError, Message: "cannot unmarshal JSON: openconfig-catalog-types:BAD is not a valid value for enum field DeploymentStatus, type ygotgen.E_OpenconfigCatalogTypes_MODULE_STATUS_TYPE"},
+			},
+		},
+		{
+			desc:  "wrong type in nested container",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": "version1", "openconfig-module-catalog:access": {"uri": "https://example.com/m.yang", "md5-hash": 5}}`,
+			wantIssues: []Issue{
+				{Path: "/access/md5-hash", Severity: SeverityError, Message: "cannot unmarshal JSON: got float64 type for field md5-hash, expect string"},
+			},
+		},
+		{
+			desc:  "list entry without key",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": "version1", "openconfig-module-catalog:submodules": {"submodule": [{"access": {"uri": "https://example.com/s.yang"}}]}}`,
+			wantIssues: []Issue{
+				{Path: "/submodules/submodule", Severity: SeverityError, Message: "cannot unmarshal JSON: key field name (*string) has nil value <nil>"},
+			},
+		},
+		{
+			desc:  "valid module",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": "version1"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, issues := CheckModule(tc.input)
+			if diff := cmp.Diff(tc.wantIssues, issues); diff != "" {
+				t.Errorf("CheckModule issues mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckFeatureBundle(t *testing.T) {
+	tests := []struct {
+		desc       string
+		input      string
+		wantIssues []Issue
+	}{
+		{
+			desc:  "JSON is not an object",
+			input: `[]`,
+			wantIssues: []Issue{
+				{Path: "/", Severity: SeverityError, Message: "cannot unmarshal JSON: json: cannot unmarshal array into Go value of type map[string]interface {}"},
+			},
+		},
+		{
+			desc:  "invalid field and empty name",
+			input: `{"openconfig-module-catalog:version": "version1", "openconfig-module-catalog:path": "not-a-list"}`,
+			wantIssues: []Issue{
+				{Path: "/name", Severity: SeverityError, Message: "name cannot be empty"},
+				{Path: "/path", Severity: SeverityError, Message: "cannot unmarshal JSON: unmarshalLeafList for schema path: value not-a-list (string): got type string, expect []interface{}"},
+			},
+		},
+		{
+			desc:  "wrong type in nested container",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": "version1", "openconfig-module-catalog:release-bundle": {"name": "release", "version": 2}}`,
+			wantIssues: []Issue{
+				{Path: "/release-bundle/version", Severity: SeverityError, Message: "cannot unmarshal JSON: got float64 type for field version, expect string"},
+			},
+		},
+		{
+			desc:  "list entry without key",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": "version1", "openconfig-module-catalog:feature-bundles": {"feature-bundle": [{"publisher": "openconfig"}]}}`,
+			wantIssues: []Issue{
+				{Path: "/feature-bundles/feature-bundle", Severity: SeverityError, Message: "cannot unmarshal JSON: key field name (*string) has nil value <nil>"},
+			},
+		},
+		{
+			desc:  "unexpected field in nested container",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": "version1", "openconfig-module-catalog:release-bundle": {"name": "release", "foo": "bar"}}`,
+			wantIssues: []Issue{
+				{Path: "/release-bundle/foo", Severity: SeverityError, Message: "cannot unmarshal JSON: parent container release-bundle (type *ygotgen.OpenconfigModuleCatalog_Organizations_Organization_FeatureBundles_FeatureBundle_ReleaseBundle): JSON contains unexpected field foo"},
+			},
+		},
+		{
+			desc:  "valid featureBundle",
+			input: `{"openconfig-module-catalog:name": "name1", "openconfig-module-catalog:version": "version1"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, issues := CheckFeatureBundle(tc.input)
+			if diff := cmp.Diff(tc.wantIssues, issues); diff != "" {
+				t.Errorf("CheckFeatureBundle issues mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidationIssue(t *testing.T) {
+	const schemaPath = "//organizations/organization/modules/module"
+	tests := []struct {
+		desc string
+		err  error
+		want Issue
+	}{
+		{
+			desc: "nested field",
+			err:  util.PrefixErrors(util.PrefixErrors(util.NewErrs(errors.New("bad value")), schemaPath+"/access/md5-hash"), schemaPath+"/access")[0],
+			want: Issue{Path: "/access/md5-hash", Severity: SeverityError, Message: "Validate function failed: bad value"},
+		},
+		{
+			desc: "top-level field",
+			err:  util.PrefixErrors(util.NewErrs(errors.New("bad value")), schemaPath+"/revision")[0],
+			want: Issue{Path: "/revision", Severity: SeverityError, Message: "Validate function failed: bad value"},
+		},
+		{
+			desc: "error without path",
+			err:  errors.New("bad struct"),
+			want: Issue{Path: "/", Severity: SeverityError, Message: "Validate function failed: bad struct"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, validationIssue(tc.err, schemaPath)); diff != "" {
+				t.Errorf("validationIssue mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
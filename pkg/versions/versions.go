@@ -0,0 +1,52 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package versions compares versions of modules and feature bundles in catalog.
+// It has no dependencies on other packages of catalog server, so that both server and client use it.
+package versions
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Compare compares versions *a* and *b* numerically element by element if possible,
+// e.g., `1.10.0` is later than `1.9.0`. It returns 1 if *a* is later, -1 if *b* is later, and 0 if they are equal.
+func Compare(a string, b string) int {
+	aElems := strings.Split(a, ".")
+	bElems := strings.Split(b, ".")
+	for i := 0; i < len(aElems) && i < len(bElems); i++ {
+		aNum, aErr := strconv.Atoi(aElems[i])
+		bNum, bErr := strconv.Atoi(bElems[i])
+		switch {
+		case aErr == nil && bErr == nil && aNum != bNum:
+			if aNum > bNum {
+				return 1
+			}
+			return -1
+		case (aErr != nil || bErr != nil) && aElems[i] != bElems[i]:
+			if aElems[i] > bElems[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	switch {
+	case len(aElems) > len(bElems):
+		return 1
+	case len(aElems) < len(bElems):
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yangsrc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/openconfig/catalog-server/pkg/access"
+	"github.com/openconfig/catalog-server/pkg/db"
+)
+
+// DownloadPath is path prefix of HTTP endpoint to download YANG sources,
+// a YANG source is downloaded from DownloadPath + its sha256 or md5 hash.
+const DownloadPath = `/yang/`
+
+// Store parses *source* to get name of the module or submodule it defines,
+// and stores it in database keyed by its sha256 hash.
+// It returns md5 hash of *source*, which is used as md5-hash in access of Module.
+// Modules refer to sources by md5 hash, so a source whose md5 hash collides with a different stored source
+// is rejected instead of being served in place of it.
+func Store(source string) (string, error) {
+	mod, err := Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("Store: %v", err)
+	}
+	md5Hash, sha256Hash := MD5Hash(source), SHA256Hash(source)
+	stored, err := db.QueryYangSources(md5Hash)
+	if err != nil {
+		return "", fmt.Errorf("Store: %v", err)
+	}
+	for _, s := range stored {
+		if s.MD5 == md5Hash && s.SHA256 != sha256Hash {
+			return "", fmt.Errorf("Store: md5 hash %s of source of %s collides with a different stored source", md5Hash, mod.Name)
+		}
+	}
+	if err := db.InsertYangSource(sha256Hash, md5Hash, mod.Name, source); err != nil {
+		return "", fmt.Errorf("Store: %v", err)
+	}
+	return md5Hash, nil
+}
+
+// Lookup returns stored YANG source whose sha256 or md5 hash equals *hash*.
+// If there is no such source, a nil pointer is returned without error.
+// Error is returned if sources of different content match *hash*, i.e., their md5 hashes collide.
+func Lookup(hash string) (*db.YangSource, error) {
+	sources, err := db.QueryYangSources(strings.ToLower(hash))
+	if err != nil {
+		return nil, fmt.Errorf("Lookup: %v", err)
+	}
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	for _, s := range sources[1:] {
+		if s.SHA256 != sources[0].SHA256 {
+			return nil, fmt.Errorf("Lookup: sources of different content match hash %s", hash)
+		}
+	}
+	return &sources[0], nil
+}
+
+// LookupMD5s returns stored YANG sources whose md5 hash is any of *md5Hashes* in one query, keyed by md5 hash.
+// Hashes without stored source, or matching sources of different content, are not in returned map.
+func LookupMD5s(md5Hashes []string) (map[string]*db.YangSource, error) {
+	var hashes []string
+	for _, hash := range md5Hashes {
+		hashes = append(hashes, strings.ToLower(hash))
+	}
+	sources, err := db.QueryYangSourcesByMD5(hashes)
+	if err != nil {
+		return nil, fmt.Errorf("LookupMD5s: %v", err)
+	}
+	res := map[string]*db.YangSource{}
+	collided := map[string]bool{}
+	for i := range sources {
+		s := &sources[i]
+		if prev, ok := res[s.MD5]; ok && prev.SHA256 != s.SHA256 {
+			collided[s.MD5] = true
+		}
+		res[s.MD5] = s
+	}
+	for hash := range collided {
+		delete(res, hash)
+	}
+	return res, nil
+}
+
+// Functions used by HandleDownload to read database, which are replaced by tests.
+var (
+	lookupSource     = Lookup
+	queryModuleOrgs  = db.QueryModuleOrgsBySource
+	queryPrivateOrgs = func() ([]string, error) { return db.QueryOrgsByVisibility(db.VisibilityPrivate) }
+)
+
+// HandleDownload is handler function of HTTP endpoint under DownloadPath.
+// It serves stored YANG source whose sha256 or md5 hash is the last element of request's path,
+// e.g., `/yang/d41d8cd98f00b204e9800998ecf8427e`.
+// Source is served only if requester can read an organization having a module that refers to it,
+// otherwise it is not found, so that sources of private organizations are not revealed.
+// Requests are expected to pass access.Middleware first.
+func HandleDownload(w http.ResponseWriter, r *http.Request) {
+	hash := strings.TrimPrefix(r.URL.Path, DownloadPath)
+	if hash == "" || strings.Contains(hash, "/") {
+		http.Error(w, "hash of YANG source is required", http.StatusBadRequest)
+		return
+	}
+	source, err := lookupSource(hash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if source == nil {
+		http.NotFound(w, r)
+		return
+	}
+	readable, err := canReadSource(r.Context(), source)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !readable {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yang; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", source.Name+".yang"))
+	w.Header().Set("ETag", `"`+source.SHA256+`"`)
+	fmt.Fprint(w, source.Data)
+}
+
+// canReadSource returns whether requester of *ctx* can read an organization having a module
+// that refers to *source*.
+func canReadSource(ctx context.Context, source *db.YangSource) (bool, error) {
+	orgNames, err := queryModuleOrgs(source.MD5)
+	if err != nil {
+		return false, err
+	}
+	if len(orgNames) == 0 {
+		return false, nil
+	}
+	privateOrgs, err := queryPrivateOrgs()
+	if err != nil {
+		return false, err
+	}
+	canRead := access.ReadFilter(ctx, privateOrgs)
+	for _, orgName := range orgNames {
+		if canRead(orgName) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
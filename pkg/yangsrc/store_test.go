@@ -0,0 +1,107 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yangsrc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/openconfig/catalog-server/pkg/access"
+	"github.com/openconfig/catalog-server/pkg/db"
+)
+
+func TestHandleDownload(t *testing.T) {
+	// Roles of principals are read from claims named after database.
+	os.Setenv("DB_NAME", "catalog")
+	defer os.Unsetenv("DB_NAME")
+	sources := map[string]*db.YangSource{
+		"md5-public":  {SHA256: "sha-public", MD5: "md5-public", Name: "public", Data: "module public {}"},
+		"md5-private": {SHA256: "sha-private", MD5: "md5-private", Name: "private", Data: "module private {}"},
+		"md5-orphan":  {SHA256: "sha-orphan", MD5: "md5-orphan", Name: "orphan", Data: "module orphan {}"},
+	}
+	moduleOrgs := map[string][]string{
+		"md5-public":  {"private", "public"},
+		"md5-private": {"private"},
+	}
+	lookupSource = func(hash string) (*db.YangSource, error) { return sources[hash], nil }
+	queryModuleOrgs = func(md5Hash string) ([]string, error) { return moduleOrgs[md5Hash], nil }
+	queryPrivateOrgs = func() ([]string, error) { return []string{"private"}, nil }
+	defer func() {
+		lookupSource = Lookup
+		queryModuleOrgs = db.QueryModuleOrgsBySource
+		queryPrivateOrgs = func() ([]string, error) { return db.QueryOrgsByVisibility(db.VisibilityPrivate) }
+	}()
+	reader := access.WithPrincipal(context.Background(), &access.Principal{Claims: map[string]interface{}{"catalog-allow": "private:reader"}})
+
+	tests := []struct {
+		desc       string
+		ctx        context.Context
+		hash       string
+		wantStatus int
+	}{
+		{
+			desc:       "source referred by module of public organization",
+			ctx:        context.Background(),
+			hash:       "md5-public",
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "source referred only by module of private organization",
+			ctx:        context.Background(),
+			hash:       "md5-private",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "reader of private organization",
+			ctx:        reader,
+			hash:       "md5-private",
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "source not referred by any module",
+			ctx:        reader,
+			hash:       "md5-orphan",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "unknown source",
+			ctx:        context.Background(),
+			hash:       "md5-unknown",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			desc:       "missing hash",
+			ctx:        context.Background(),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, DownloadPath+tc.hash, nil).WithContext(tc.ctx)
+			HandleDownload(rec, req)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status mismatch, got: %d, want: %d, body: %s", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus == http.StatusOK && rec.Body.String() != sources[tc.hash].Data {
+				t.Errorf("served source mismatch, got: %s, want: %s", rec.Body.String(), sources[tc.hash].Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package yangsrc contains functions to handle YANG source files of modules and submodules.
+  - yangsrc.go parses YANG sources using goyang, and verifies that metadata of a Module
+    in catalog matches the actual YANG source of that module.
+  - store.go stores YANG sources in database and serves them for download.
+*/
+package yangsrc
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/openconfig/catalog-server/pkg/validate"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+	"github.com/openconfig/goyang/pkg/yang"
+	"github.com/openconfig/ygot/ygot"
+)
+
+const (
+	extensionsModule = `openconfig-extensions` // Name of module defining openconfig-version extension.
+	versionExtension = `openconfig-version`    // Name of extension statement containing version of openconfig modules.
+)
+
+// Parse parses *source* of one YANG module or submodule into yang.Module.
+// Unlike crawling modules in `scripts/crawl`, imports and includes are not resolved,
+// so that a single module can be parsed without sources of its dependencies.
+func Parse(source string) (*yang.Module, error) {
+	statements, err := yang.Parse(source, "")
+	if err != nil {
+		return nil, fmt.Errorf("Parse: parse YANG source failed: %v", err)
+	}
+	if len(statements) != 1 {
+		return nil, fmt.Errorf("Parse: YANG source should contain exactly one module or submodule, found %d", len(statements))
+	}
+	node, err := yang.BuildAST(statements[0])
+	if err != nil {
+		return nil, fmt.Errorf("Parse: build AST failed: %v", err)
+	}
+	mod, ok := node.(*yang.Module)
+	if !ok {
+		return nil, fmt.Errorf("Parse: YANG source does not contain a module or submodule, found %s", node.Kind())
+	}
+	return mod, nil
+}
+
+// MD5Hash returns hex encoded md5 hash of *source*, which is used as md5-hash in access of Module.
+func MD5Hash(source string) string {
+	hash := md5.Sum([]byte(source))
+	return hex.EncodeToString(hash[:])
+}
+
+// SHA256Hash returns hex encoded sha256 hash of *source*, which is the key of stored YANG sources.
+func SHA256Hash(source string) string {
+	hash := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(hash[:])
+}
+
+// Version returns version of *mod* declared by `openconfig-version` extension statement,
+// or "" if *mod* does not declare its version.
+// Similar to yang.MatchingExtensions, but it only needs prefix of imported openconfig-extensions module
+// instead of the resolved imported module.
+func Version(mod *yang.Module) string {
+	prefix := ""
+	for _, i := range mod.Import {
+		if i.Name == extensionsModule && i.Prefix != nil {
+			prefix = i.Prefix.Name
+		}
+	}
+	if prefix == "" {
+		return ""
+	}
+	for _, ext := range mod.Extensions {
+		if ext.Keyword == prefix+":"+versionExtension {
+			return ext.Argument
+		}
+	}
+	return ""
+}
+
+// LatestRevision returns the latest revision of *mod*, or "" if *mod* does not have any revision.
+func LatestRevision(mod *yang.Module) string {
+	latest := ""
+	for _, r := range mod.Revision {
+		// Revisions are dates in format of YYYY-MM-DD, which can be compared as strings.
+		if r.Name > latest {
+			latest = r.Name
+		}
+	}
+	return latest
+}
+
+// VerifyModule verifies that metadata in *module* matches YANG *source* of that module
+// and *submoduleSources* of its submodules, including name, namespace, prefix, revision,
+// version, imports and submodules. Metadata that is not set in *module* is not checked, except for name and version.
+// Besides, it fills in md5-hash in access of module and submodules whose sources are given.
+// It returns a list of issues sorted by path, all of which have SeverityError.
+func VerifyModule(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, source string, submoduleSources []string) []validate.Issue {
+	mod, err := Parse(source)
+	if err != nil {
+		return []validate.Issue{newIssue("/", err.Error())}
+	}
+	if mod.Kind() != "module" {
+		return []validate.Issue{newIssue("/", fmt.Sprintf("YANG source of module contains a %s", mod.Kind()))}
+	}
+
+	var issues []validate.Issue
+	issues = append(issues, compare("/name", module.GetName(), mod.Name)...)
+	if module.Namespace != nil && mod.Namespace != nil {
+		issues = append(issues, compare("/namespace", module.GetNamespace(), mod.Namespace.Name)...)
+	}
+	if module.Prefix != nil && mod.Prefix != nil {
+		issues = append(issues, compare("/prefix", module.GetPrefix(), mod.Prefix.Name)...)
+	}
+	if module.Revision != nil {
+		issues = append(issues, compare("/revision", module.GetRevision(), LatestRevision(mod))...)
+	}
+	// Modules not from openconfig may not declare their versions in YANG source.
+	if version := Version(mod); version != "" {
+		issues = append(issues, compare("/version", module.GetVersion(), version)...)
+	}
+
+	// Check whether required modules are exactly imported modules.
+	var imports []string
+	for _, i := range mod.Import {
+		imports = append(imports, i.Name)
+	}
+	issues = append(issues, compareSets("/dependencies/required-module", "required module", module.GetDependencies().GetRequiredModule(), imports)...)
+
+	// Check whether submodules are exactly included submodules.
+	var includes, submodules []string
+	for _, i := range mod.Include {
+		includes = append(includes, i.Name)
+	}
+	if module.GetSubmodules() != nil {
+		for name := range module.GetSubmodules().Submodule {
+			submodules = append(submodules, name)
+		}
+	}
+	issues = append(issues, compareSets("/submodules/submodule", "submodule", submodules, includes)...)
+
+	for _, submoduleSource := range submoduleSources {
+		issues = append(issues, verifySubmodule(module, mod.Name, submoduleSource)...)
+	}
+
+	module.GetOrCreateAccess().Md5Hash = ygot.String(MD5Hash(source))
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Path < issues[j].Path
+	})
+	return issues
+}
+
+// verifySubmodule verifies that *source* is a submodule belonging to module *moduleName*,
+// and fills in md5-hash in access of that submodule in *module*.
+func verifySubmodule(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module, moduleName string, source string) []validate.Issue {
+	mod, err := Parse(source)
+	if err != nil {
+		return []validate.Issue{newIssue("/submodules/submodule", fmt.Sprintf("YANG source of submodule: %v", err))}
+	}
+	path := fmt.Sprintf("/submodules/submodule[name=%s]", mod.Name)
+	if mod.Kind() != "submodule" {
+		return []validate.Issue{newIssue(path, fmt.Sprintf("YANG source of submodule %s contains a %s", mod.Name, mod.Kind()))}
+	}
+	if mod.BelongsTo.Name != moduleName {
+		return []validate.Issue{newIssue(path, fmt.Sprintf("submodule %s belongs to module %s", mod.Name, mod.BelongsTo.Name))}
+	}
+	submodule := module.GetSubmodules().GetSubmodule(mod.Name)
+	if submodule == nil {
+		return []validate.Issue{newIssue(path, fmt.Sprintf("YANG source is given for submodule %s which is not included", mod.Name))}
+	}
+	submodule.GetOrCreateAccess().Md5Hash = ygot.String(MD5Hash(source))
+	return nil
+}
+
+// compare returns an issue at *path* if *got* value in catalog differs from *want* value in YANG source.
+func compare(path string, got string, want string) []validate.Issue {
+	if got == want {
+		return nil
+	}
+	return []validate.Issue{newIssue(path, fmt.Sprintf("%q does not match %q in YANG source", got, want))}
+}
+
+// compareSets returns issues at *path* for elements of *got* in catalog that are not in *want* in YANG source,
+// and elements of *want* that are missing in *got*. *kind* describes elements in messages of issues.
+func compareSets(path string, kind string, got []string, want []string) []validate.Issue {
+	gotSet := map[string]bool{}
+	for _, g := range got {
+		gotSet[g] = true
+	}
+	wantSet := map[string]bool{}
+	for _, w := range want {
+		wantSet[w] = true
+	}
+
+	var msgs []string
+	for g := range gotSet {
+		if !wantSet[g] {
+			msgs = append(msgs, fmt.Sprintf("%s %s is not in YANG source", kind, g))
+		}
+	}
+	for w := range wantSet {
+		if !gotSet[w] {
+			msgs = append(msgs, fmt.Sprintf("%s %s in YANG source is missing", kind, w))
+		}
+	}
+	sort.Strings(msgs)
+
+	var issues []validate.Issue
+	for _, msg := range msgs {
+		issues = append(issues, newIssue(path, msg))
+	}
+	return issues
+}
+
+// newIssue returns an issue with SeverityError at *path*.
+func newIssue(path string, message string) validate.Issue {
+	return validate.Issue{Path: path, Severity: validate.SeverityError, Message: message}
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yangsrc
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/catalog-server/pkg/validate"
+	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
+)
+
+const (
+	baseSource = `module base {
+  namespace "urn:mod";
+  prefix "base";
+  import openconfig-extensions { prefix oc-ext; }
+  import other { prefix bother; }
+  include sub;
+
+  description "base desc";
+  oc-ext:openconfig-version "1.3.0";
+
+  revision "2021-07-01" { description "newer"; }
+  revision "2020-01-01" { description "older"; }
+}`
+	subSource = `submodule sub {
+  belongs-to base { prefix "sbase"; }
+}`
+	otherSubSource = `submodule othersub {
+  belongs-to other { prefix "sother"; }
+}`
+)
+
+func TestVerifyModule(t *testing.T) {
+	tests := []struct {
+		desc             string
+		input            string
+		submoduleSources []string
+		wantIssues       []validate.Issue
+	}{
+		{
+			desc: "matching metadata",
+			input: `{
+				"openconfig-module-catalog:name": "base",
+				"openconfig-module-catalog:version": "1.3.0",
+				"openconfig-module-catalog:namespace": "urn:mod",
+				"openconfig-module-catalog:prefix": "base",
+				"openconfig-module-catalog:revision": "2021-07-01",
+				"openconfig-module-catalog:dependencies": {"required-module": ["other", "openconfig-extensions"]},
+				"openconfig-module-catalog:submodules": {"submodule": [{"name": "sub"}]}
+			}`,
+			submoduleSources: []string{subSource},
+		},
+		{
+			desc: "metadata not set is not checked",
+			input: `{
+				"openconfig-module-catalog:name": "base",
+				"openconfig-module-catalog:version": "1.3.0",
+				"openconfig-module-catalog:dependencies": {"required-module": ["other", "openconfig-extensions"]},
+				"openconfig-module-catalog:submodules": {"submodule": [{"name": "sub"}]}
+			}`,
+		},
+		{
+			desc: "mismatching metadata",
+			input: `{
+				"openconfig-module-catalog:name": "base",
+				"openconfig-module-catalog:version": "1.2.0",
+				"openconfig-module-catalog:namespace": "urn:other",
+				"openconfig-module-catalog:prefix": "b",
+				"openconfig-module-catalog:revision": "2020-01-01",
+				"openconfig-module-catalog:dependencies": {"required-module": ["openconfig-extensions", "foo"]}
+			}`,
+			submoduleSources: []string{otherSubSource},
+			wantIssues: []validate.Issue{
+				{Path: "/dependencies/required-module", Severity: validate.SeverityError, Message: "required module foo is not in YANG source"},
+				{Path: "/dependencies/required-module", Severity: validate.SeverityError, Message: "required module other in YANG source is missing"},
+				{Path: "/namespace", Severity: validate.SeverityError, Message: `"urn:other" does not match "urn:mod" in YANG source`},
+				{Path: "/prefix", Severity: validate.SeverityError, Message: `"b" does not match "base" in YANG source`},
+				{Path: "/revision", Severity: validate.SeverityError, Message: `"2020-01-01" does not match "2021-07-01" in YANG source`},
+				{Path: "/submodules/submodule", Severity: validate.SeverityError, Message: "submodule sub in YANG source is missing"},
+				{Path: "/submodules/submodule[name=othersub]", Severity: validate.SeverityError, Message: "submodule othersub belongs to module other"},
+				{Path: "/version", Severity: validate.SeverityError, Message: `"1.2.0" does not match "1.3.0" in YANG source`},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+			if err := oc.Unmarshal([]byte(tc.input), module); err != nil {
+				t.Fatalf("cannot unmarshal module: %v", err)
+			}
+			issues := VerifyModule(module, baseSource, tc.submoduleSources)
+			if diff := cmp.Diff(tc.wantIssues, issues); diff != "" {
+				t.Errorf("VerifyModule issues mismatch (-want +got):\n%s", diff)
+			}
+			if got, want := module.GetAccess().GetMd5Hash(), MD5Hash(baseSource); got != want {
+				t.Errorf("md5-hash of module mismatch, got: %s, want: %s", got, want)
+			}
+			if len(tc.submoduleSources) > 0 && tc.wantIssues == nil {
+				if got, want := module.GetSubmodules().GetSubmodule("sub").GetAccess().GetMd5Hash(), MD5Hash(subSource); got != want {
+					t.Errorf("md5-hash of submodule mismatch, got: %s, want: %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifyModuleInvalidSource(t *testing.T) {
+	tests := []struct {
+		desc   string
+		source string
+	}{
+		{
+			desc:   "not YANG",
+			source: `module {`,
+		},
+		{
+			desc:   "submodule instead of module",
+			source: subSource,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			module := &oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module{}
+			issues := VerifyModule(module, tc.source, nil)
+			if len(issues) != 1 || issues[0].Path != "/" {
+				t.Errorf("VerifyModule should report one issue at /, got: %v", issues)
+			}
+		})
+	}
+}
@@ -20,8 +20,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	firebase "firebase.google.com/go/v4"
+	"github.com/openconfig/catalog-server/pkg/access"
+	"github.com/openconfig/catalog-server/pkg/db"
 	"google.golang.org/api/impersonate"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
@@ -29,13 +32,16 @@ import (
 
 const (
 	baseAccessField = `allow`
+	// migrationGranter is recorded as granter of access grants migrated from claims.
+	migrationGranter = `grantaccess-migration`
 )
 
 func main() {
 	var emailPtr = flag.String("email", "", "email account that you want to change access for")
-	var accessPtr = flag.String("access", "", "string of a list of organizations that account would be granted access to, seperated by delimiter. If not set, it means set empty access for this account")
+	var accessPtr = flag.String("access", "", "string of a list of organizations that account would be granted access to, seperated by delimiter. Each organization can be followed by role, e.g., `openconfig:publisher`. If not set, it means set empty access for this account")
 	var listall = flag.Bool("all", false, "whether to list all current users' claims")
 	var dbnamePtr = flag.String("db", "", "name of db that you want to grant user access to")
+	var migrate = flag.Bool("migrate", false, "whether to migrate all current users' claims to access grants stored in db, which is connected by $DB_HOST, $DB_PORT, $DB_USERNAME and $DB_PWD")
 
 	flag.Parse()
 
@@ -46,7 +52,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	if !*listall && *emailPtr == "" {
+	if !*listall && !*migrate && *emailPtr == "" {
 		flag.CommandLine.SetOutput(os.Stderr)
 		fmt.Fprintf(os.Stderr, "Please provide either provide email address or specify list `all`\n")
 		flag.Usage()
@@ -85,6 +91,57 @@ func main() {
 
 	accessField := *dbnamePtr + "-" + baseAccessField
 
+	if *migrate {
+		// Connect to db *dbnamePtr* to store access grants.
+		os.Setenv("DB_NAME", *dbnamePtr)
+		if err := db.ConnectDB(); err != nil {
+			log.Fatalf("Connect to db failed: %v\n", err)
+		}
+		defer db.Close()
+
+		migrated := 0
+		iter := client.Users(ctx, "")
+		for {
+			user, err := iter.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				log.Fatalf("error listing users: %s\n", err)
+			}
+			claim, ok := user.CustomClaims[accessField]
+			if !ok {
+				continue
+			}
+			roles, err := access.ParseClaim(claim)
+			if err != nil {
+				log.Printf("Skip %s: %v\n", user.Email, err)
+				continue
+			}
+			// Grants are stored under verified emails, so that admins can manage them by emails.
+			member := user.UID
+			if user.Email != "" && user.EmailVerified {
+				member = access.NormalizeMember(user.Email)
+			}
+			for orgName, role := range roles {
+				grant := db.AccessGrant{
+					OrgName:   orgName,
+					Member:    member,
+					Role:      role.String(),
+					GrantedBy: migrationGranter,
+					GrantedAt: time.Now().UTC(),
+				}
+				if err := db.InsertAccessGrant(grant); err != nil {
+					log.Fatalf("Migrate access of %s failed: %v\n", member, err)
+				}
+				log.Printf("%s is granted %s in organization %s\n", member, role, orgName)
+				migrated++
+			}
+		}
+		fmt.Printf("Successfully migrated %d access grants\n", migrated)
+		return
+	}
+
 	// list all existing users and their claims
 	if *listall {
 		iter := client.Users(ctx, "")
@@ -99,10 +156,12 @@ func main() {
 			// Assume all users have email addresses.
 			if user.CustomClaims == nil {
 				log.Printf("%s does not have claims\n", user.Email)
-			} else if access, ok := user.CustomClaims[accessField]; !ok {
+			} else if claim, ok := user.CustomClaims[accessField]; !ok {
 				log.Printf("%s does not have access\n", user.Email)
+			} else if roles, err := access.ParseClaim(claim); err != nil {
+				log.Printf("%s has invalid access %v: %v\n", user.Email, claim, err)
 			} else {
-				log.Printf("%s current access is: %v\n", user.Email, access)
+				log.Printf("%s current access is: %v\n", user.Email, roles)
 			}
 		}
 	} else {
@@ -125,8 +184,13 @@ func main() {
 			currentClaims = make(map[string]interface{})
 		}
 
-		// Set claims to grant access.
-		currentClaims[accessField] = *accessPtr
+		// Set claims to grant access, which are written as object of roles keyed by organizations,
+		// e.g., `{"openconfig": "publisher"}`. Legacy string claims are replaced.
+		roles, err := access.ParseRoles(*accessPtr)
+		if err != nil {
+			log.Fatalf("Invalid access %q: %v\n", *accessPtr, err)
+		}
+		currentClaims[accessField] = access.FormatClaim(roles)
 		if err := client.SetCustomUserClaims(ctx, user.UID, currentClaims); err != nil {
 			log.Fatalf("error setting custom claims %v\n", err)
 		}
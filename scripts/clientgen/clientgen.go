@@ -0,0 +1,70 @@
+// Copyright 2021 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command clientgen generates typed Go client of catalog server from GraphQL operations in .graphql files,
+// it is run by `go generate` of package pkg/client/gqlclient.
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+
+	"github.com/openconfig/catalog-server/pkg/clientgen"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// loadSources reads files matched by glob *pattern* in order of names.
+func loadSources(pattern string) ([]*ast.Source, error) {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	var sources []*ast.Source
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, &ast.Source{Name: filepath.Base(file), Input: string(data)})
+	}
+	return sources, nil
+}
+
+func main() {
+	schemaPtr := flag.String("schema", "graph/*.graphqls", "glob of files of GraphQL schema of catalog server")
+	operationsPtr := flag.String("operations", "*.graphql", "glob of files of GraphQL operations to generate")
+	outPtr := flag.String("out", "generated.go", "path of generated Go file")
+	packagePtr := flag.String("package", "gqlclient", "name of generated Go package")
+	flag.Parse()
+
+	schema, err := loadSources(*schemaPtr)
+	if err != nil || len(schema) == 0 {
+		log.Fatalf("load schema %s failed: %v", *schemaPtr, err)
+	}
+	operations, err := loadSources(*operationsPtr)
+	if err != nil || len(operations) == 0 {
+		log.Fatalf("load operations %s failed: %v", *operationsPtr, err)
+	}
+	src, err := clientgen.Generate(schema, operations, *packagePtr, "scripts/clientgen")
+	if err != nil {
+		log.Fatalf("generate client failed: %v", err)
+	}
+	if err := ioutil.WriteFile(*outPtr, src, 0644); err != nil {
+		log.Fatalf("write %s failed: %v", *outPtr, err)
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"strings"
 
 	"github.com/openconfig/catalog-server/pkg/db"
+	"github.com/openconfig/catalog-server/pkg/yangsrc"
 	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
 	"github.com/openconfig/goyang/pkg/yang"
 	"github.com/openconfig/ygot/ygot"
@@ -62,6 +63,10 @@ const (
 // urlMap is map from model's name to its github URL.
 var urlMap = map[string]string{}
 
+// pathMap is map from model's name to path of its YANG source file.
+// Name found in different files is mapped to "", since which file is source of crawled module is unknown.
+var pathMap = map[string]string{}
+
 // traverseDir traverses given directory *dir* to find all modules in this directory including its sub-directories.
 // *url* is the url prefix of github repo at certain commit.
 // It returns a slice of names of modules found.
@@ -85,6 +90,12 @@ func traverseDir(dir string, url string) ([]string, error) {
 			}
 			// *name* is name of yang module/submodule, we get it by removing `.yang` from original file name.
 			name := strings.TrimSuffix(f.Name(), ".yang")
+			if prev, ok := pathMap[name]; !ok {
+				pathMap[name] = p
+			} else if prev != p {
+				log.Printf("traverseDir: %s is found in both %s and %s, its YANG source is not stored\n", name, prev, p)
+				pathMap[name] = ""
+			}
 
 			// Check whether found module is under `models` directory or `ietf` dirctory.
 			if strings.Contains(p, modelKeyword) {
@@ -214,13 +225,52 @@ func populateModule(mod *yang.Module, name string) *oc.OpenconfigModuleCatalog_O
 			return nil
 		}
 		module.GetOrCreateSubmodules().GetOrCreateSubmodule(mod.Include[i].Name).GetOrCreateAccess().Uri = &submoduleURL
-
+		if source, ok := readSource(mod.Include[i].Name); ok {
+			module.GetOrCreateSubmodules().GetOrCreateSubmodule(mod.Include[i].Name).GetOrCreateAccess().Md5Hash = ygot.String(yangsrc.MD5Hash(source))
+		}
 	}
 	moduleURL := urlMap[name]
 	module.GetOrCreateAccess().Uri = &moduleURL
+	if source, ok := readSource(name); ok {
+		module.GetOrCreateAccess().Md5Hash = ygot.String(yangsrc.MD5Hash(source))
+	}
 	return module
 }
 
+// readSource reads YANG source file of module/submodule *name* found when traversing directories.
+// It returns false if the file is not found, cannot be read, or files of the same name are found.
+func readSource(name string) (string, bool) {
+	p, ok := pathMap[name]
+	if !ok || p == "" {
+		return "", false
+	}
+	source, err := os.ReadFile(p)
+	if err != nil {
+		log.Printf("read YANG source file %s failed: %v\n", p, err)
+		return "", false
+	}
+	return string(source), true
+}
+
+// storeSources uploads YANG sources of *module* and its submodules into database alongside its metadata.
+func storeSources(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) {
+	names := []string{module.GetName()}
+	if module.GetSubmodules() != nil {
+		for name := range module.GetSubmodules().Submodule {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		source, ok := readSource(name)
+		if !ok {
+			continue
+		}
+		if _, err := yangsrc.Store(source); err != nil {
+			log.Printf("Store YANG source of %s failed: %v\n", name, err)
+		}
+	}
+}
+
 // insertModule marshalls the module into JSON string, and tries to insert it into database.
 // It checks whether the key (name+version) of module already exists, if the module exists, then the insertion is skipped.
 func insertModule(module *oc.OpenconfigModuleCatalog_Organizations_Organization_Modules_Module) {
@@ -235,6 +285,9 @@ func insertModule(module *oc.OpenconfigModuleCatalog_Organizations_Organization_
 	if err != nil {
 		log.Fatalf("Marshalling into json string failed\n")
 	}
+	// Sources are stored even if module already exists, e.g., it is inserted before its sources are crawled.
+	// Sources are keyed by their hashes, so storing them again does nothing.
+	storeSources(module)
 
 	// Query to check whether the key already exists before insertion.
 	// As we crawl from the lastest version to the oldest one, we want to only insert the lastest data into database.
@@ -16,8 +16,11 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/openconfig/catalog-server/pkg/yangsrc"
 	oc "github.com/openconfig/catalog-server/pkg/ygotgen"
 )
 
@@ -70,3 +73,90 @@ func TestCrawlModules(t *testing.T) {
 		})
 	}
 }
+
+func TestPopulateModuleMD5Hash(t *testing.T) {
+	paths := []string{"./models"}
+	url := "https://github.com/openconfig/goyang/tree/master/testdata"
+	mods, _ := crawlModules(paths, url)
+	module := populateModule(mods["base"], "base")
+	if module == nil {
+		t.Fatalf("populate module base failed")
+	}
+
+	tests := []struct {
+		desc   string
+		file   string
+		gotMD5 string
+	}{
+		{
+			desc:   "module base",
+			file:   "./models/base.yang",
+			gotMD5: module.GetAccess().GetMd5Hash(),
+		},
+		{
+			desc:   "submodule sub",
+			file:   "./models/sub.yang",
+			gotMD5: module.GetSubmodules().GetSubmodule("sub").GetAccess().GetMd5Hash(),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			source, err := os.ReadFile(tc.file)
+			if err != nil {
+				t.Fatalf("read file %s failed: %v", tc.file, err)
+			}
+			if want := yangsrc.MD5Hash(string(source)); tc.gotMD5 != want {
+				t.Errorf("md5-hash mismatch, got: %s, want: %s", tc.gotMD5, want)
+			}
+		})
+	}
+}
+
+func TestTraverseDirNameCollision(t *testing.T) {
+	savedPathMap, savedURLMap := pathMap, urlMap
+	pathMap, urlMap = map[string]string{}, map[string]string{}
+	defer func() { pathMap, urlMap = savedPathMap, savedURLMap }()
+
+	dir := filepath.Join(t.TempDir(), "models")
+	files := map[string]string{
+		"a/dup.yang":    "module dup { prefix a; }",
+		"b/dup.yang":    "module dup { prefix b; }",
+		"a/unique.yang": "module unique { prefix u; }",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatalf("create directory failed: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatalf("write file %s failed: %v", p, err)
+		}
+	}
+	if _, err := traverseDir(dir, "https://github.com/openconfig/public/tree/master/"); err != nil {
+		t.Fatalf("traverseDir failed: %v", err)
+	}
+
+	tests := []struct {
+		desc   string
+		name   string
+		wantOK bool
+	}{
+		{
+			desc:   "name found in different files",
+			name:   "dup",
+			wantOK: false,
+		},
+		{
+			desc:   "name found in one file",
+			name:   "unique",
+			wantOK: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.desc, func(t *testing.T) {
+			if _, ok := readSource(tc.name); ok != tc.wantOK {
+				t.Errorf("readSource(%s) found mismatch, got: %v, want: %v", tc.name, ok, tc.wantOK)
+			}
+		})
+	}
+}
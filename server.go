@@ -16,6 +16,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"html/template"
 	"log"
 	"net/http"
@@ -25,7 +26,13 @@ import (
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/openconfig/catalog-server/graph"
 	"github.com/openconfig/catalog-server/graph/generated"
+	"github.com/openconfig/catalog-server/pkg/access"
+	"github.com/openconfig/catalog-server/pkg/archive"
+	"github.com/openconfig/catalog-server/pkg/audit"
 	"github.com/openconfig/catalog-server/pkg/db"
+	"github.com/openconfig/catalog-server/pkg/etag"
+	"github.com/openconfig/catalog-server/pkg/validate"
+	"github.com/openconfig/catalog-server/pkg/yangsrc"
 )
 
 const (
@@ -48,12 +55,32 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Initialize authenticator once, which verifies and caches tokens of write operations.
+	if err := access.Init(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
+	// Load lint rules configured for organizations if the config file is given.
+	if lintConfigPath, ok := os.LookupEnv("LINT_CONFIG"); ok {
+		if err := validate.LoadLintConfig(lintConfigPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: &graph.Resolver{}}))
+	// Record every mutation in audit log.
+	srv.AroundFields(audit.Middleware)
 
 	// Launch built-in graphQL frontend server.
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
 	// Set handler for all queries.
-	http.Handle("/query", srv)
+	// Bearer token in Authorization header is verified once by middleware and passed to resolvers.
+	// Responses carry ETags, so that clients can revalidate cached results by conditional requests.
+	http.Handle("/query", access.Middleware(etag.Middleware(srv)))
+	// Set handler to download stored YANG sources, which are served only to requesters who can read modules referring to them.
+	http.Handle(yangsrc.DownloadPath, access.Middleware(http.HandlerFunc(yangsrc.HandleDownload)))
+	// Set handler to download archives of modules or feature bundles with their dependencies.
+	http.Handle(archive.DownloadPath, access.Middleware(http.HandlerFunc(archive.HandleDownload)))
 
 	// static file server to serve frontend webpages.
 	updateHTMLTemplate, err := os.ReadFile(updateHTMLPath)